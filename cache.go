@@ -0,0 +1,57 @@
+package viscaoverip
+
+import (
+	"bytes"
+	"time"
+)
+
+// cachedReply is the data of an inquiry reply in the inquiry cache.
+type cachedReply struct {
+	data    []byte
+	expires time.Time
+}
+
+// uncachedInquiries are the inquiries, by normalized hex, whose replies are
+// never cached: positions change while the camera moves, without a command.
+var uncachedInquiries = map[string]bool{
+	"0612": true, // Pan-tilt position
+	"0447": true, // Zoom position
+	"0448": true, // Focus position
+}
+
+// cachedInquiry returns the cached reply data of an inquiry, by normalized
+// hex, if Config.InquiryCacheTTL is set and the reply has not expired.
+func (c *Camera) cachedInquiry(key string) ([]byte, bool) {
+	if c.Config.InquiryCacheTTL <= 0 {
+		return nil, false
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	r, ok := c.cache[key]
+	if !ok || time.Now().After(r.expires) {
+		return nil, false
+	}
+	return bytes.Clone(r.data), true
+}
+
+// cacheInquiry stores the reply data of an inquiry, if Config.InquiryCacheTTL
+// is set and the inquiry is not a position.
+func (c *Camera) cacheInquiry(key string, data []byte) {
+	if c.Config.InquiryCacheTTL <= 0 || uncachedInquiries[key] {
+		return
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if c.cache == nil {
+		c.cache = make(map[string]cachedReply)
+	}
+	c.cache[key] = cachedReply{data: bytes.Clone(data), expires: time.Now().Add(c.Config.InquiryCacheTTL)}
+}
+
+// FlushInquiryCache empties the inquiry cache, e.g. after the camera was
+// changed from its own menu or by another controller.
+func (c *Camera) FlushInquiryCache() {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	clear(c.cache)
+}
@@ -0,0 +1,99 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestInquiryCache(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond, InquiryCacheTTL: 50 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	inquire := func() {
+		t.Helper()
+		if _, err := camera.SendInquiry(voip.PowerInquiry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sent := func() int { return len(emulator.Requests()) }
+
+	inquire()
+	before := sent()
+	inquire()
+	inquire()
+	if n := sent() - before; n != 0 {
+		t.Errorf("%d inquiries sent within the TTL, want 0", n)
+	}
+
+	// WithNoCache asks the camera
+	if _, err := camera.SendInquiry(voip.PowerInquiry, voip.WithNoCache()); err != nil {
+		t.Fatal(err)
+	}
+	if n := sent() - before; n != 1 {
+		t.Errorf("%d inquiries sent with WithNoCache, want 1", n)
+	}
+
+	// Commands flush the cache
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+	before = sent()
+	inquire()
+	if n := sent() - before; n != 1 {
+		t.Errorf("%d inquiries sent after a command, want 1", n)
+	}
+
+	// Replies expire
+	time.Sleep(60 * time.Millisecond)
+	before = sent()
+	inquire()
+	if n := sent() - before; n != 1 {
+		t.Errorf("%d inquiries sent after the TTL, want 1", n)
+	}
+
+	// Positions are never cached
+	before = sent()
+	for range 2 {
+		if _, err := camera.Position(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := sent() - before; n != 4 {
+		t.Errorf("%d inquiries sent for 2 positions, want 4", n)
+	}
+	before = sent()
+	for _, inquiry := range []string{voip.PanTiltPositionInquiry, voip.ZoomPositionInquiry} {
+		for range 2 {
+			if _, err := camera.SendInquiry(inquiry); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if n := sent() - before; n != 4 {
+		t.Errorf("%d position inquiries sent twice each, want 4", n)
+	}
+
+	// Replies fetched WithNoCache are not stored
+	camera.FlushInquiryCache()
+	if _, err := camera.SendInquiry(voip.PowerInquiry, voip.WithNoCache()); err != nil {
+		t.Fatal(err)
+	}
+	before = sent()
+	inquire()
+	if n := sent() - before; n != 1 {
+		t.Errorf("%d inquiries sent after one WithNoCache, want 1", n)
+	}
+}
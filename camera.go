@@ -1,6 +1,8 @@
 package viscaoverip
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -9,27 +11,42 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
 const (
 	// VISCA over IP constants
 	CommandPrefix      = "8101"
+	InquiryPrefix      = "8109"
 	CommandSuffix      = "FF"   // Message terminator
 	PayloadTypeCommand = "0100" // Payload type for Command
+	PayloadTypeInquiry = "0110" // Payload type for Inquiry
 	SequenceNumMax     = math.MaxUint32
 	MessageBufferSize  = 24
+	MaxPayloadSize     = 16 // VISCA packet, address and terminator included
 
 	// Status Codes
 	StatusCodeACK        = 4
 	StatusCodeCompletion = 5
 
 	// Timeout
-	DefaultTimeout = 100 * time.Millisecond
-	InitialBackoff = 5 * time.Millisecond
-	MaxBackoff     = 50 * time.Millisecond
+	DefaultTimeout           = 100 * time.Millisecond
+	DefaultCompletionTimeout = 10 * time.Second
+	InitialBackoff           = 5 * time.Millisecond
+	MaxBackoff               = 50 * time.Millisecond
 )
 
+// ErrInvalidPayload is the error of a VISCA packet that the camera would
+// reject: too long, or with a misplaced terminator.
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// ErrReplyTruncated is the error of a reply longer than
+// Config.ReceiveBufferSize.
+var ErrReplyTruncated = errors.New("reply truncated")
+
 type UDPConn interface {
 	net.Conn
 	net.PacketConn
@@ -37,21 +54,209 @@ type UDPConn interface {
 
 type Config struct {
 	MaxRetries int
-	Timeout    time.Duration
-	Debug      bool
+	// Timeout is how long to wait for each reply before retrying, until the
+	// ACK of a command.
+	Timeout time.Duration
+	// CompletionTimeout is how long to wait for the Completion of a command
+	// once it is acknowledged, which depends on the mechanics of the camera,
+	// so that a slow move is not taken for a missed reply and sent again.
+	// Defaults to DefaultCompletionTimeout.
+	CompletionTimeout time.Duration
+	// Debug prints the debug events of the camera (see OnDebug) to stdout.
+	Debug bool
+	// ResetFallback enables compatibility with peripheral devices that do not
+	// answer the RESET control command. When set, a RESET that times out is
+	// not an error, and commands are numbered from 1.
+	ResetFallback bool
+	// HeartbeatInterval enables a background heartbeat that sends a power
+	// inquiry at this interval, so that the State of the camera is kept up to
+	// date even when no commands are sent.
+	// Zero disables the heartbeat.
+	HeartbeatInterval time.Duration
+	// OfflineAfterMisses is the number of consecutive unanswered exchanges,
+	// heartbeats included, after which the camera is considered offline.
+	// Defaults to DefaultOfflineAfterMisses.
+	OfflineAfterMisses int
+	// WatchdogMisses enables the watchdog, which re-initializes the camera
+	// every time this many consecutive exchanges go unanswered.
+	// Zero disables the watchdog.
+	WatchdogMisses int
+	// WatchdogReconnect makes the watchdog perform a full Reconnect instead of
+	// only re-running the RESET and IF_Clear sequence.
+	WatchdogReconnect bool
+	// Vendor selects the vendor profile of the peripheral device, which
+	// gates the vendor extension commands that may be sent to it.
+	Vendor Vendor
+	// CoalesceDrive filters the pan-tilt and zoom drive commands sent with
+	// SendCommand: a drive command identical to the last one sent is
+	// dropped, and one superseded by a newer drive command while waiting
+	// for its turn is dropped in favor of the newer one. Dropped commands
+	// return nil.
+	CoalesceDrive bool
+	// AcceptAnySource disables the check that replies come from the address
+	// of the camera, for devices that answer from another address than the
+	// one they are sent to, e.g. behind NAT. Datagrams from any host are
+	// then taken as replies.
+	AcceptAnySource bool
+	// ReceiveBufferSize is the size of the buffer replies are read into,
+	// which must hold the longest reply expected, e.g. block inquiries or
+	// vendor extensions. Longer replies fail with ErrReplyTruncated.
+	// Defaults to MessageBufferSize.
+	ReceiveBufferSize int
+	// ResyncOnSequenceError re-runs the RESET and IF_Clear sequence after an
+	// exchange fails with a SequenceError or ErrAbnormalSequence, so that the
+	// following exchanges are numbered in step with the camera. The failed
+	// exchange is not retried.
+	ResyncOnSequenceError bool
+	// InquiryCacheTTL enables the inquiry cache: the replies of SendInquiry
+	// are reused for this long, so that several widgets asking for slowly
+	// changing values do not each reach the camera. Every command flushes
+	// the cache. Positions are never cached.
+	// Zero disables the cache.
+	InquiryCacheTTL time.Duration
+	// LivenessProbe is the message of the heartbeat and of Ping. Defaults to
+	// the probe of the Vendor profile.
+	LivenessProbe Probe
+	// InitializeWithProbe makes Initialize send the liveness probe instead
+	// of IF_Clear after RESET, for cameras that misbehave on IF_Clear.
+	InitializeWithProbe bool
+	// ZoomCompensation scales the speeds of PanTilt down as the camera zooms
+	// in, so that the picture moves at the same apparent speed at any focal
+	// length: at full optical zoom, speeds are reduced by this fraction,
+	// from 0 to 1. The zoom position is inquired at most every
+	// ZoomPositionMaxAge while driving.
+	// Zero disables the compensation.
+	ZoomCompensation float64
+	// DriveTimeout stops pan-tilt or zoom when no drive command of that
+	// axis group is sent for this long while it moves, so that a crashed UI
+	// or a dropped connection cannot leave the camera moving until it hits
+	// its limit. Drive inputs must then be refreshed faster than this, even
+	// when unchanged.
+	// Zero disables the failsafe.
+	DriveTimeout time.Duration
+	// PowerOnWarmup makes the commands sent after a power on command wait,
+	// for at most this long, until a power inquiry reports the camera on,
+	// instead of failing or burning retries while it boots. The power
+	// state is inquired every WarmupPollInterval meanwhile.
+	// Zero disables the wait.
+	PowerOnWarmup time.Duration
+	// Unconnected makes Dial, DialLazy and Reconnect use an unconnected
+	// socket, sending with WriteTo and reading with ReadFrom, for cameras
+	// that reply from another source port than the one they listen on,
+	// which a connected socket never receives. Replies are then taken from
+	// the host of the camera on any port.
+	Unconnected bool
+	// MultiController is for cameras shared with another controller, e.g. a
+	// joystick panel behind the same proxy, whose replies reach this
+	// socket too. Replies numbered ahead of the message, which this camera
+	// never sent, are then ignored instead of failing with a SequenceError.
+	// Replies numbered behind it are always ignored, unless pending.
+	MultiController bool
+	// NoReset makes Initialize skip the RESET control command, which
+	// restarts the numbering of every controller of the camera, so that a
+	// restarting controller does not disturb other software using it.
+	// Messages are then numbered on from InitialSeqNum. Combine with
+	// InitializeWithProbe to also skip IF_Clear, which cancels the commands
+	// running in the camera.
+	NoReset bool
+	// InitialSeqNum is the sequence number the numbering of messages
+	// starts after, e.g. the SeqNum of a previous run of the process. It
+	// is only used with NoReset, as RESET restarts the numbering.
+	InitialSeqNum uint32
+	// InquiryTimeout and InquiryMaxRetries replace Timeout and MaxRetries
+	// for inquiries, which firmware answers at once while commands may
+	// take a while to be acknowledged, so that state polling fails fast.
+	// Zero uses Timeout and MaxRetries.
+	InquiryTimeout    time.Duration
+	InquiryMaxRetries int
+	// DryRun makes the camera validate and encode every message without
+	// any network I/O, to rehearse show files and scripts offline: Dial and
+	// DialLazy dial nothing, and each message is reported as a SendEvent
+	// (see OnDebug, printed with Debug) and succeeds at once, without data.
+	DryRun bool
+	// DryRunReplies makes a dry run simulate the replies of a camera
+	// instead: an ACK and a Completion to every command, and a Completion
+	// without data to every inquiry, handled like real replies, e.g.
+	// completing SendCommandAsync. It requires a camera made with Dial or
+	// DialLazy.
+	DryRunReplies bool
+	// Metrics receives the counters of Metrics as they change, the number
+	// of pending commands and the duration of the exchanges. Nil reports
+	// nothing.
+	Metrics MetricsSink
 }
 
 type Stats struct {
 	missedResponses int
 	timeouts        int
+	sent            int
+	retries         int
+	errors          int
 }
 
 // Camera represents a peripheral device that can be controlled via VISCA over IP.
+//
+// A Camera is safe for concurrent use: exchanges with the peripheral device
+// are serialized.
 type Camera struct {
-	Conn   UDPConn
-	seqNum int // Sequence Number
-	Config Config
-	stats  Stats
+	Conn    UDPConn
+	seqNum  atomic.Uint32 // Sequence number of the last message sent
+	Config  Config
+	stats   Stats
+	address string // Dialed address, re-resolved on Reconnect
+
+	mu      sync.Mutex // Serializes exchanges, guards Conn, stats, misses and pending
+	misses  int        // Consecutive exchanges without reply
+	pending map[uint32]*Completion
+	replies [][]byte // Replies of the current exchange
+
+	warmupUntil time.Time // End of the power on warm-up, zero if none; guarded by mu
+	lazy        bool      // Made by DialLazy and not initialized yet; guarded by mu
+
+	cacheMu sync.Mutex // Guards cache
+	cache   map[string]cachedReply
+
+	lastMu sync.Mutex // Guards last
+	last   Exchange
+
+	tracer   atomic.Pointer[tracer]
+	recorder atomic.Pointer[recorder]
+
+	driveMu sync.Mutex // Guards drive
+	drive   map[string]*driveState
+
+	failsafeMu sync.Mutex // Guards failsafe
+	failsafe   map[string]*failsafeTimer
+
+	zoomMu  sync.Mutex // Guards zoomPos and zoomAt
+	zoomPos int        // Last zoom position inquired
+	zoomAt  time.Time  // When zoomPos was inquired, zero if never
+
+	stateMu        sync.Mutex // Guards state and stateListeners
+	state          State
+	stateListeners map[int]func(from, to State)
+	nextListenerID int
+
+	heartbeatStop chan struct{}
+	heartbeatDone chan struct{}
+
+	closing           atomic.Bool
+	closeMu           sync.Mutex // Serializes Close
+	doneMu            sync.Mutex // Guards closed and done
+	closed            bool
+	done              chan struct{}
+	watchdogRunning   atomic.Bool
+	watchdogWG        sync.WaitGroup
+	commandMu         sync.Mutex // Guards commandListeners
+	commandListeners  map[int]func(CommandEvent)
+	nextCommandID     int
+	watchdogMu        sync.Mutex // Guards watchdogListeners, and watchdogWG against Close
+	watchdogListeners map[int]func(WatchdogEvent)
+	nextWatchdogID    int
+	debugMu           sync.Mutex // Guards debugListeners
+	debugListeners    map[int]func(DebugEvent)
+	nextDebugID       int
+	debugListening    atomic.Int32 // Number of debugListeners
 }
 
 // NewCamera returns a Camera struct that holds information to communicate
@@ -61,7 +266,7 @@ type Camera struct {
 // number and clear the interface socket of the connected peripheral device.
 //
 // MaxNumRetries can be updated post initialization.
-func NewCamera(conn UDPConn) (Camera, error) {
+func NewCamera(conn UDPConn) (*Camera, error) {
 	cfg := Config{
 		MaxRetries: 5,
 		Timeout:    DefaultTimeout,
@@ -70,237 +275,998 @@ func NewCamera(conn UDPConn) (Camera, error) {
 	return NewCameraWithConfig(conn, cfg)
 }
 
-func NewCameraWithConfig(conn UDPConn, cfg Config) (Camera, error) {
-	camera := Camera{
+func NewCameraWithConfig(conn UDPConn, cfg Config) (*Camera, error) {
+	camera := New(conn, cfg)
+	err := camera.Initialize(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return camera, nil
+}
+
+// Dial connects to the peripheral device at address (host:port) and
+// initializes it. Unlike a Camera made from an existing connection, the host
+// name is resolved again on every Reconnect.
+func Dial(ctx context.Context, address string, cfg Config) (*Camera, error) {
+	conn, err := dialUDP(ctx, address, cfg)
+	if err != nil {
+		return nil, err
+	}
+	camera := New(conn, cfg)
+	camera.address = address
+	err = camera.Initialize(ctx)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return camera, nil
+}
+
+func dialUDP(ctx context.Context, address string, cfg Config) (UDPConn, error) {
+	if cfg.DryRun {
+		return newDryRunConn(address), nil
+	}
+	if cfg.Unconnected {
+		return listenUDP(ctx, address)
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", address, err)
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// New returns a Camera without performing any network I/O, so cameras can
+// be constructed offline. Initialize must be called before sending commands.
+func New(conn UDPConn, cfg Config) *Camera {
+	c := &Camera{
 		Conn:   conn,
-		seqNum: 0,
 		Config: cfg,
 		stats:  Stats{},
 	}
-	err := camera.ResetSequenceNumber()
+	c.seqNum.Store(cfg.InitialSeqNum)
+	return c
+}
+
+// SeqNum returns the sequence number of the last message sent, to be
+// persisted as the Config.InitialSeqNum of the next run of the process.
+func (c *Camera) SeqNum() uint32 {
+	return c.seqNum.Load()
+}
+
+// Initialize resets the sequence number and clears the interface socket of
+// the peripheral device. Cancelling ctx interrupts the pending network I/O.
+//
+// If Config.HeartbeatInterval is set, the heartbeat starts once the camera
+// is initialized.
+func (c *Camera) Initialize(ctx context.Context) error {
+	c.mu.Lock()
+	err := c.reinitialize(ctx)
+	c.mu.Unlock()
 	if err != nil {
-		return Camera{}, err
+		return err
+	}
+	c.startHeartbeat()
+	return nil
+}
+
+// reinitialize runs initialize and tracks the resulting state.
+// c.mu must be held.
+func (c *Camera) reinitialize(ctx context.Context) error {
+	c.failPending(ErrCompletionLost)
+	c.FlushInquiryCache()
+	c.forgetDrive()
+	c.setState(StateInitializing)
+	err := c.initialize(ctx)
+	if err != nil && c.State() == StateInitializing {
+		c.setState(StateOffline)
+	}
+	if err == nil {
+		c.lazy = false
+	}
+	return err
+}
+
+func (c *Camera) initialize(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.Conn == nil {
+		// Made by DialLazy
+		conn, err := dialUDP(ctx, c.address, c.Config)
+		if err != nil {
+			return err
+		}
+		c.Conn = conn
+	}
+	stop := context.AfterFunc(ctx, func() {
+		// Unblock any pending read or write
+		_ = c.Conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	var err error
+	if !c.Config.NoReset {
+		err = c.resetSequenceNumber()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
 	}
+	if err != nil {
+		// Some implementations never answer RESET. In compatibility mode,
+		// start from sequence number 1 as if the reset had succeeded.
+		if !c.Config.ResetFallback || !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+		c.ReportError("reset", fmt.Errorf("no reply to RESET, continuing without sequence reset: %w", err))
+		c.seqNum.Store(0)
+	}
+
+	if c.Config.InitializeWithProbe {
+		return c.sendProbe(ctx)
+	}
+
 	// NOTE: clear the camera's interface socket
-	err = camera.SendCommand("00 01")
+	message, err := MakeCommand("00 01", 0)
 	if err != nil {
-		return Camera{}, err
+		return err
 	}
-	return camera, nil
+	seqNum, err := c.number(ctx, message)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, message, seqNum, c.sendOptions(nil))
+	return err
+}
+
+// Reconnect closes the current connection, dials the remote endpoint again
+// and re-runs Initialize, which also resets the sequence state. The Config
+// and Stats of the camera are preserved. It is used to recover from camera
+// reboots or address changes without rebuilding the Camera.
+func (c *Camera) Reconnect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closing.Store(false)
+	c.doneMu.Lock()
+	if c.closed {
+		c.closed, c.done = false, nil
+	}
+	c.doneMu.Unlock()
+	if err := c.reconnect(ctx); err != nil {
+		return err
+	}
+	// Close stopped the heartbeat
+	c.startHeartbeatLocked()
+	return nil
+}
+
+// reconnect is Reconnect with c.mu held.
+func (c *Camera) reconnect(ctx context.Context) error {
+	address := c.address
+	if address == "" {
+		address = c.Conn.RemoteAddr().String()
+	}
+	conn, err := dialUDP(ctx, address, c.Config)
+	if err != nil {
+		return err
+	}
+	if c.Conn != nil {
+		c.Conn.Close()
+	}
+	c.Conn = conn
+	if !c.Config.NoReset {
+		c.seqNum.Store(0)
+	}
+	return c.reinitialize(ctx)
+}
+
+// seqCompare compares the sequence numbers a and b in serial number
+// arithmetic (RFC 1982), so that the numbers following a wraparound past
+// SequenceNumMax come after those preceding it. It returns -1 if a comes
+// before b, 0 if they are equal and +1 if a comes after b.
+func seqCompare(a, b uint32) int {
+	d := int32(a - b)
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// incSeqNum returns the sequence number of the next message, wrapping
+// around from SequenceNumMax to 0.
+func (c *Camera) incSeqNum() uint32 {
+	return c.seqNum.Add(1)
+}
+
+// number waits for the warm-up of the camera if it holds message, then
+// numbers message with the next sequence number, so that the power
+// inquiries of the warm-up are numbered before it. c.mu must be held; it is
+// released while waiting for the warm-up.
+func (c *Camera) number(ctx context.Context, message []byte) (uint32, error) {
+	if err := c.awaitWarmup(ctx, message); err != nil {
+		return 0, err
+	}
+	seqNum := c.incSeqNum()
+	binary.BigEndian.PutUint32(message[4:8], seqNum)
+	return seqNum, nil
 }
 
-func (c *Camera) incSeqNum() int {
-	c.seqNum += 1
-	if c.seqNum > SequenceNumMax {
-		c.seqNum = 0
+// cleanHex removes the notations of hex strings copied from documentation:
+// "0x" prefixes, and spaces, line breaks, commas and colons between bytes.
+func cleanHex(s string) string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ':' || unicode.IsSpace(r)
+	})
+	for i, f := range fields {
+		if len(f) > 2 && (f[:2] == "0x" || f[:2] == "0X") {
+			fields[i] = f[2:]
+		}
 	}
-	return c.seqNum
+	return strings.Join(fields, "")
 }
 
 // MakeCommand is a convenience function that takes the hex string
 // representation of command payload and returns the binary message
-// to communicate to peripheral device.
-func MakeCommand(commandHex string, seqNum int) ([]byte, error) {
-	// Allow input string to contain spaces for legibility
-	cleaned := strings.ReplaceAll(commandHex, " ", "")
+// to communicate to peripheral device. Bytes may be separated by spaces,
+// line breaks, commas or colons, and prefixed with "0x".
+func MakeCommand(commandHex string, seqNum uint32) ([]byte, error) {
+	cleaned := cleanHex(commandHex)
 
 	if len(cleaned)%2 != 0 {
 		return nil, fmt.Errorf("command hex must have even length: %s", commandHex)
 	}
 
-	payload := CommandPrefix + cleaned + CommandSuffix
-	payloadLength := fmt.Sprintf("%04x", len(payload)/2)
-	seqNumStr := fmt.Sprintf("%08x", seqNum)
-
-	messageStr := PayloadTypeCommand + payloadLength + seqNumStr + payload
-	message, err := hex.DecodeString(messageStr)
+	message, err := makeMessage(PayloadTypeCommand, CommandPrefix+cleaned+CommandSuffix, seqNum)
 	if err != nil {
 		return nil, fmt.Errorf("invalid hex in command: %s", commandHex)
 	}
+	if err := validatePayload(message[HeaderSize:]); err != nil {
+		return nil, fmt.Errorf("invalid command %s: %w", commandHex, err)
+	}
 
 	return message, nil
 }
 
-func (c *Camera) SendCommand(commandHex string) error {
-	seqNum := c.incSeqNum()
-	message, err := MakeCommand(commandHex, seqNum)
+// MakePacket is like MakeCommand, but takes a complete VISCA packet
+// including the address byte and the message terminator. It is used for
+// commands outside of the 8x 01 category, such as vendor extensions.
+func MakePacket(packetHex string, seqNum uint32) ([]byte, error) {
+	cleaned := cleanHex(packetHex)
+
+	if len(cleaned)%2 != 0 {
+		return nil, fmt.Errorf("packet hex must have even length: %s", packetHex)
+	}
+
+	message, err := makeMessage(PayloadTypeCommand, cleaned, seqNum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex in packet: %s", packetHex)
+	}
+	if err := validatePayload(message[HeaderSize:]); err != nil {
+		return nil, fmt.Errorf("invalid packet %s: %w", packetHex, err)
+	}
+
+	return message, nil
+}
+
+// MakeInquiry is like MakeCommand, but for inquiries (8x 09 category).
+func MakeInquiry(inquiryHex string, seqNum uint32) ([]byte, error) {
+	cleaned := cleanHex(inquiryHex)
+
+	if len(cleaned)%2 != 0 {
+		return nil, fmt.Errorf("inquiry hex must have even length: %s", inquiryHex)
+	}
+
+	message, err := makeMessage(PayloadTypeInquiry, InquiryPrefix+cleaned+CommandSuffix, seqNum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex in inquiry: %s", inquiryHex)
+	}
+	if err := validatePayload(message[HeaderSize:]); err != nil {
+		return nil, fmt.Errorf("invalid inquiry %s: %w", inquiryHex, err)
+	}
+
+	return message, nil
+}
+
+// validatePayload checks the structure of a VISCA packet: an address byte
+// 8x, at most MaxPayloadSize bytes, and a single terminator at the end.
+func validatePayload(payload []byte) error {
+	switch {
+	case len(payload) < 3:
+		return fmt.Errorf("%w: %d bytes, at least 3 expected", ErrInvalidPayload, len(payload))
+	case len(payload) > MaxPayloadSize:
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrInvalidPayload, len(payload), MaxPayloadSize)
+	case payload[0]&0xF0 != 0x80:
+		return fmt.Errorf("%w: address byte %02X, 8x expected", ErrInvalidPayload, payload[0])
+	case payload[len(payload)-1] != 0xFF:
+		return fmt.Errorf("%w: missing FF terminator", ErrInvalidPayload)
+	}
+	if i := bytes.IndexByte(payload, 0xFF); i < len(payload)-1 {
+		return fmt.Errorf("%w: FF at byte %d before the terminator", ErrInvalidPayload, i)
+	}
+	return nil
+}
+
+func makeMessage(payloadType, payload string, seqNum uint32) ([]byte, error) {
+	payloadLength := fmt.Sprintf("%04x", len(payload)/2)
+	seqNumStr := fmt.Sprintf("%08x", seqNum)
+
+	messageStr := payloadType + payloadLength + seqNumStr + payload
+	return hex.DecodeString(messageStr)
+}
+
+func (c *Camera) SendCommand(commandHex string, opts ...SendOption) error {
+	_, err := c.SendCommandReply(commandHex, opts...)
+	return err
+}
+
+// SendCommandReply is like SendCommand, but also returns the data carried by
+// the Completion of the command, which is the completion payload without the
+// 'y0 5z' header and the FF terminator, for the commands that answer with
+// data. The data is empty for most commands, and for commands dropped by
+// Config.CoalesceDrive.
+func (c *Camera) SendCommandReply(commandHex string, opts ...SendOption) ([]byte, error) {
+	c.refreshFailsafe(commandHex)
+	gen := c.coalesceBegin(commandHex)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.coalesceSkip(commandHex, gen) {
+		return nil, nil
+	}
+	if err := c.connect(context.Background()); err != nil {
+		c.coalesceDone(commandHex, err)
+		return nil, err
+	}
+
+	message, err := MakeCommand(commandHex, 0)
+	if err != nil {
+		return nil, err
+	}
+	seqNum, err := c.number(context.Background(), message)
+	if err != nil {
+		c.coalesceDone(commandHex, err)
+		return nil, err
+	}
+	res, err := c.send(context.Background(), message, seqNum, c.sendOptions(opts))
+	c.coalesceDone(commandHex, err)
+	return res.data, err
+}
+
+// SendPacket sends a complete VISCA packet (see MakePacket) and waits for
+// its completion the same way SendCommand does.
+func (c *Camera) SendPacket(packetHex string, opts ...SendOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetDrive()
+	if err := c.connect(context.Background()); err != nil {
+		return err
+	}
+
+	message, err := MakePacket(packetHex, 0)
 	if err != nil {
 		return err
 	}
+	seqNum, err := c.number(context.Background(), message)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(context.Background(), message, seqNum, c.sendOptions(opts))
+	return err
+}
+
+// SendInquiry sends an inquiry and returns the data of its reply, which is
+// the reply payload without the 'y0 50' header and the FF terminator.
+//
+// With Config.InquiryCacheTTL set, a reply received within the TTL is
+// returned without reaching the camera, unless WithNoCache is given. The
+// reply to an inquiry sent WithNoCache is not cached either.
+func (c *Camera) SendInquiry(inquiryHex string, opts ...SendOption) ([]byte, error) {
+	o := c.inquiryOptions(opts)
+	key := strings.ToUpper(cleanHex(inquiryHex))
+	if !o.noCache {
+		if data, ok := c.cachedInquiry(key); ok {
+			return data, nil
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.connect(context.Background()); err != nil {
+		return nil, err
+	}
+
+	message, err := MakeInquiry(inquiryHex, 0)
+	if err != nil {
+		return nil, err
+	}
+	seqNum, err := c.number(context.Background(), message)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(context.Background(), message, seqNum, o)
+	if err == nil && !o.noCache {
+		c.cacheInquiry(key, res.data)
+	}
+	return res.data, err
+}
+
+// reply is the final reply to a message.
+type reply struct {
+	// data is the data of the completion payload, which is only non-empty
+	// for inquiry replies and the few commands that answer with data.
+	data []byte
+	// completed is false if the exchange ended at the ACK.
+	completed bool
+}
+
+// send writes the message and waits for its completion, or only its ACK if
+// opts.untilACK is set, retrying when either the write or the read times
+// out, until ctx is done.
+// Errors are annotated with the exchange in a CommandError.
+func (c *Camera) send(ctx context.Context, message []byte, seqNum uint32, opts sendOptions) (reply, error) {
+	start := time.Now()
+	c.replies = nil
+	if binary.BigEndian.Uint16(message) != PayloadTypeVISCAInquiry {
+		// Commands may change what inquiries report
+		c.FlushInquiryCache()
+	}
+	res, attempts, err := c.exchange(ctx, message, seqNum, opts)
+	c.recordExchange(message, start, err)
+	c.stats.sent++
+	c.count(MetricSent, 1)
+	if attempts > 1 {
+		c.stats.retries += attempts - 1
+		c.count(MetricRetries, attempts-1)
+	}
+	c.timing(MetricExchange, time.Since(start))
+	c.gauge(MetricPending, float64(len(c.pending)))
+	if err != nil {
+		c.stats.errors++
+		c.count(MetricErrors, 1)
+		var seqErr *SequenceError
+		outOfStep := errors.As(err, &seqErr) || errors.Is(err, ErrAbnormalSequence)
+		if outOfStep && c.Config.ResyncOnSequenceError && c.State() != StateInitializing {
+			if err := c.reinitialize(ctx); err != nil {
+				c.ReportError("resync after sequence error", err)
+			}
+		}
+		cmdErr := &CommandError{
+			Message:  message,
+			Address:  c.Conn.RemoteAddr().String(),
+			SeqNum:   seqNum,
+			Attempts: attempts,
+			Elapsed:  time.Since(start),
+			Err:      err,
+		}
+		c.notifyCommand(message, seqNum, start, cmdErr)
+		return reply{}, cmdErr
+	}
+	if !opts.untilACK || res.completed {
+		c.notifyCommand(message, seqNum, start, nil)
+	}
+	c.startWarmup(message)
+	return res, nil
+}
 
+// exchange implements send, and also returns the number of attempts made.
+func (c *Camera) exchange(ctx context.Context, message []byte, seqNum uint32, opts sendOptions) (reply, int, error) {
 	backoff := InitialBackoff
+	if c.Config.DryRun && !c.Config.DryRunReplies {
+		return c.dryRun(message, seqNum), 1, nil
+	}
+	var lastErr error // Why the previous attempt failed
 	for count := 1; ; count += 1 {
-		if count > c.Config.MaxRetries {
+		if err := ctx.Err(); err != nil {
+			return reply{}, count - 1, err
+		}
+		if count > opts.maxRetries {
 			c.stats.timeouts++
-			return errors.New("peripheral device is not responsive")
+			c.count(MetricTimeouts, 1)
+			c.recordMiss()
+			return reply{}, count - 1, &TimeoutError{Attempts: count - 1}
 		}
 
-		err = c.Conn.SetWriteDeadline(time.Now().Add(c.Config.Timeout))
+		err := c.Conn.SetWriteDeadline(time.Now().Add(opts.timeout))
 		if err != nil {
-			return fmt.Errorf("failed to set read deadline: %w", err)
+			return reply{}, count, &TransportError{Op: "set write deadline", Err: err}
 		}
 		_, err = c.Conn.Write(message)
+		c.trace(traceSend, c.Conn.RemoteAddr(), message, err)
 		if err != nil {
 			// If write times out, simply try again
 			if errors.Is(err, os.ErrDeadlineExceeded) {
 				c.stats.timeouts++
+				c.count(MetricTimeouts, 1)
+				lastErr = err
 				time.Sleep(backoff)
 				backoff = time.Duration(math.Min(float64(backoff)*2, float64(MaxBackoff)))
 				continue
 			}
-			return err
+			c.recordMiss()
+			return reply{}, count, &TransportError{Op: "write", Err: err}
+		}
+		if c.debugging() {
+			if count == 1 {
+				c.debug(SendEvent{Time: time.Now(), Message: message, SeqNum: seqNum})
+			} else {
+				c.debug(RetryEvent{Time: time.Now(), Message: message, SeqNum: seqNum, Attempt: count, Err: lastErr})
+			}
 		}
 
-		err := c.receiveCommandResponse(seqNum)
+		inquiry := binary.BigEndian.Uint16(message) == PayloadTypeVISCAInquiry
+		res, err := c.receiveCommandResponse(seqNum, inquiry, opts)
 		if err != nil {
 			// If read times out, simply consider response missed
 			if errors.Is(err, os.ErrDeadlineExceeded) {
 				c.stats.missedResponses++
+				c.count(MetricMissedResponses, 1)
+				lastErr = err
 				time.Sleep(backoff)
 				backoff = time.Duration(math.Min(float64(backoff)*2, float64(MaxBackoff)))
 				continue
 			}
-			return fmt.Errorf("response error: %w", err)
+			// The peripheral device answered, even if with an error
+			c.recordReply()
+			return reply{}, count, fmt.Errorf("response error: %w", err)
 		}
 
-		break
+		c.recordReply()
+		return res, count, nil
 	}
-
-	return nil
 }
 
 // receiveCommandResponse blocks until it times out or gets a response.
 // If the response status code is not 4 (ACK) or 5 (completion) then it
-// return the payload of the response as the error message.
-func (c *Camera) receiveCommandResponse(seqNum int) error {
-	res := make([]byte, MessageBufferSize)
+// return the payload of the response as the error message. On completion it
+// returns the data carried by the completion payload, if any. If untilACK is
+// set, it returns at the ACK. Late replies completing pending commands are
+// dispatched to them, and malformed datagrams are skipped, as the reply may
+// still be on its way. An inquiry only takes replies on socket 0, and an
+// acknowledged command only those on the socket of its ACK.
+//
+// Once the ACK is received, it waits up to the completion timeout instead.
+func (c *Camera) receiveCommandResponse(seqNum uint32, inquiry bool, opts sendOptions) (reply, error) {
+	res := make([]byte, c.receiveBufferSize())
+	acked := false
+	socket := -1 // Socket of the command, once acknowledged
+	if inquiry {
+		socket = 0
+	}
+	deadline := time.Now().Add(opts.timeout)
 
 	for {
-		// Set read deadline for timeout
-		err := c.Conn.SetReadDeadline(time.Now().Add(c.Config.Timeout))
+		err := c.Conn.SetReadDeadline(deadline)
 		if err != nil {
-			return fmt.Errorf("failed to set read deadline: %w", err)
+			return reply{}, &TransportError{Op: "set read deadline", Err: err}
 		}
 		bytesRead, addr, err := c.Conn.ReadFrom(res)
+		c.trace(traceRecv, addr, res[:bytesRead], err)
 		if err != nil {
 			// If read times out, error will be os.ErrDeadlineExceeded, which can be
 			// returned to the caller to retry or give up.
-			return err
+			return reply{}, &TransportError{Op: "read", Err: err}
 		}
 		// If the process gets here, a response is received. All further processing
 		// will continue the loop (which will extend the deadline) or return to the caller.
 
 		// Verify the sender address matches expected camera address
-		if addr.String() != c.Conn.RemoteAddr().String() {
-			if c.Config.Debug {
-				fmt.Printf("Received packet from unexpected address: %s\n", addr.String())
+		if !c.fromCamera(addr) {
+			continue
+		}
+		if opts.anyReply && bytesRead > HeaderSize &&
+			seqCompare(binary.BigEndian.Uint32(res[4:8]), seqNum) == 0 {
+			bytesRead, err = messageLength(res, bytesRead)
+			if err != nil {
+				return reply{}, &ProtocolError{Err: err}
+			}
+			c.replies = append(c.replies, bytes.Clone(res[:bytesRead]))
+			return reply{data: bytes.Clone(res[HeaderSize:bytesRead]), completed: true}, nil
+		}
+		if bytesRead < HeaderSize {
+			c.skipDatagram(addr, res[:bytesRead], seqNum)
+			continue
+		}
+		switch binary.BigEndian.Uint16(res[0:2]) {
+		case PayloadTypeVISCAReply:
+		case PayloadTypeControlReply:
+			if err := controlReplyError(res[HeaderSize:bytesRead]); err != nil {
+				return reply{}, &ProtocolError{Err: err}
+			}
+			// A late RESET reply
+			continue
+		default:
+			// Notifications and vendor specific messages answer no message
+			if c.debugging() {
+				c.debugReply(addr, res[:bytesRead], seqNum, fmt.Sprintf("skipped message of payload type %04X", binary.BigEndian.Uint16(res[0:2])))
 			}
 			continue
 		}
-		// Ensure message received has enough bytes for header (8)
-		// and minimum payload (4)
-		if bytesRead < 12 {
-			return fmt.Errorf("response too short: got %d bytes, expected at least 12", bytesRead)
+		// Skip datagrams without a header (8) and a minimum payload (3),
+		// e.g. 90 41 FF, as the reply may still be on its way
+		if bytesRead < 11 {
+			c.skipDatagram(addr, res[:bytesRead], seqNum)
+			continue
 		}
+		bytesRead, err = messageLength(res, bytesRead)
 		if err != nil {
-			return err
+			return reply{}, &ProtocolError{Err: err}
+		}
+		if bytesRead < 11 || res[bytesRead-1] != 0xFF {
+			c.skipDatagram(addr, res[:bytesRead], seqNum)
+			continue
 		}
 
 		resSeqNum := binary.BigEndian.Uint32(res[4:8])
 
 		// Ignore late responses from earlier messages.
-		// resSeqNum cannot be larger than seqNum.
 		// When there are missed responses from peripheral device, the resSeqNum of subsequent
 		// responses will be the same as seqNum, in which case we can continue processing.
-		if int(resSeqNum) < seqNum {
-			if c.Config.Debug {
-				fmt.Printf("Received old response: expected=%d, got=%d\n", seqNum, resSeqNum)
+		// A larger resSeqNum does not answer this message.
+		if seqCompare(resSeqNum, seqNum) > 0 {
+			if c.Config.MultiController {
+				// A reply to another controller
+				c.debugReply(addr, res[:bytesRead], seqNum, "ignored reply to another controller")
+				continue
 			}
+			return reply{}, &ProtocolError{Err: &SequenceError{Expected: seqNum, Got: resSeqNum}}
+		}
+		if seqCompare(resSeqNum, seqNum) < 0 {
+			if c.resolvePending(resSeqNum, res[8:bytesRead]) {
+				continue
+			}
+			c.debugReply(addr, res[:bytesRead], seqNum, "old reply")
 			continue
 		}
+		// Inquiry replies come on socket 0, while the ACK, Completion and
+		// errors of a command carry the socket it runs in, except for the
+		// errors of the message itself (syntax error, buffer full...) on
+		// socket 0. A reply on another socket answers another exchange, even
+		// if numbered alike by a camera that numbers its replies after the
+		// last message.
+		replySocket := int(res[9] & 0x0F)
+		messageError := res[9]>>4 == 6 && replySocket == 0
+		if socket >= 0 && replySocket != socket && !messageError {
+			if c.debugging() {
+				c.debugReply(addr, res[:bytesRead], seqNum, fmt.Sprintf("reply on socket %d, expected socket %d", replySocket, socket))
+			}
+			continue
+		}
+		c.replies = append(c.replies, bytes.Clone(res[:bytesRead]))
+
 		// Extract payload (everything after first 8 bytes)
 		resPayload := res[8:bytesRead]
 
-		if len(resPayload) < 4 {
-			return errors.New("response payload too short")
-		}
-
 		// Status code is the first 4 bit at index 1 in the payload
 		statusCode := resPayload[1] >> 4
 		switch statusCode {
 		case StatusCodeACK:
-			if c.Config.Debug {
-				fmt.Printf("Received ACK for sequence %d\n", seqNum)
+			c.debugReply(addr, res[:bytesRead], seqNum, "ACK")
+			if opts.untilACK {
+				return reply{}, nil
+			}
+			if !acked {
+				// Once acknowledged, wait for the completion instead
+				acked = true
+				deadline = time.Now().Add(opts.completionTimeout)
+				socket = int(resPayload[1] & 0x0F)
 			}
 			continue
 		case StatusCodeCompletion:
-			if c.Config.Debug {
-				fmt.Printf("Received Completion for sequence %d\n", seqNum)
-			}
-			return nil
+			c.debugReply(addr, res[:bytesRead], seqNum, "Completion")
+			// Completion data sits between the status byte and the terminator
+			data := make([]byte, len(resPayload)-3)
+			copy(data, resPayload[2:len(resPayload)-1])
+			return reply{data: data, completed: true}, nil
 		default:
-			return fmt.Errorf(
-				"peripheral device error: payload=%x, statusCode=%x",
-				resPayload, statusCode,
-			)
+			return reply{}, &CameraError{Payload: bytes.Clone(resPayload)}
 		}
 
 	}
 }
 
+// controlReplyError returns the error reported by the payload of a control
+// reply, or nil for an acknowledgement.
+func controlReplyError(payload []byte) error {
+	if len(payload) < 2 || payload[0] != 0x0F {
+		return nil
+	}
+	switch payload[1] {
+	case 0x01:
+		return ErrAbnormalSequence
+	case 0x02:
+		return ErrAbnormalMessage
+	default:
+		return fmt.Errorf("control reply error % X", payload)
+	}
+}
+
+// skipDatagram drops a malformed datagram received while waiting for the
+// reply to seqNum.
+func (c *Camera) skipDatagram(from net.Addr, b []byte, seqNum uint32) {
+	c.debugReply(from, b, seqNum, "malformed datagram, skipped")
+}
+
+// debugReply emits a ReplyEvent for a datagram received while waiting for
+// the reply to seqNum.
+func (c *Camera) debugReply(from net.Addr, b []byte, seqNum uint32, note string) {
+	if c.debugging() {
+		c.debug(ReplyEvent{Time: time.Now(), From: from, Message: b, SeqNum: seqNum, Note: note})
+	}
+}
+
+// receiveBufferSize returns the size of the buffer replies are read into.
+func (c *Camera) receiveBufferSize() int {
+	if c.Config.ReceiveBufferSize > 0 {
+		return c.Config.ReceiveBufferSize
+	}
+	return MessageBufferSize
+}
+
+// messageLength returns the length of the message read into the first n
+// bytes of buf, from the payload length of its header. A message filling buf
+// but announcing a longer payload was truncated by the read. Other length
+// mismatches are tolerated, and the n bytes read are taken as the message.
+func messageLength(buf []byte, n int) (int, error) {
+	length := HeaderSize + int(binary.BigEndian.Uint16(buf[2:4]))
+	switch {
+	case length > n && n == len(buf):
+		return n, fmt.Errorf("%w: payload of %d bytes, buffer of %d bytes", ErrReplyTruncated, length-HeaderSize, len(buf))
+	case length > n:
+		return n, nil
+	default:
+		return length, nil
+	}
+}
+
+// fromCamera reports whether a datagram received from addr is to be taken as
+// a reply of the camera. Datagrams from other hosts are dropped silently,
+// unless Config.AcceptAnySource is set.
+func (c *Camera) fromCamera(addr net.Addr) bool {
+	if c.Config.AcceptAnySource {
+		return true
+	}
+	remote := c.Conn.RemoteAddr()
+	from, ok1 := addr.(*net.UDPAddr)
+	to, ok2 := remote.(*net.UDPAddr)
+	if ok1 && ok2 {
+		if from.IP.Equal(to.IP) && (from.Port == to.Port || c.Config.Unconnected) {
+			return true
+		}
+	} else if addr != nil && addr.String() == remote.String() {
+		return true
+	}
+	if c.debugging() {
+		c.debug(ErrorEvent{Time: time.Now(), Op: "receive", Err: fmt.Errorf("datagram from unexpected address %v", addr)})
+	}
+	return false
+}
+
 // ResetSequenceNumber calls RESET command to peripheral device, which
 // resets its sequence number to 0. The value that was set as the
 // sequence number is ignored.
 func (c *Camera) ResetSequenceNumber() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lazy {
+		if err := c.connect(context.Background()); err != nil || !c.Config.NoReset {
+			// Connecting resets the sequence number
+			return err
+		}
+	}
+	return c.resetSequenceNumber()
+}
+
+func (c *Camera) resetSequenceNumber() error {
 	resetCmd := []byte{0x02, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x01}
+	if c.Config.DryRun && !c.Config.DryRunReplies {
+		c.dryRun(resetCmd, 1)
+		c.seqNum.Store(1)
+		return nil
+	}
 
 	err := c.Conn.SetWriteDeadline(time.Now().Add(c.Config.Timeout))
 	if err != nil {
-		return fmt.Errorf("failed to set write deadline: %w", err)
+		return &TransportError{Op: "set write deadline", Err: err}
 	}
 
 	_, err = c.Conn.Write(resetCmd)
+	c.trace(traceSend, c.Conn.RemoteAddr(), resetCmd, err)
+	if c.debugging() {
+		c.debug(SendEvent{Time: time.Now(), Message: resetCmd, SeqNum: 1})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to send reset command: %w", err)
+		return &TransportError{Op: "send reset command", Err: err}
 	}
 
 	res := make([]byte, MessageBufferSize)
 
 	err = c.Conn.SetReadDeadline(time.Now().Add(c.Config.Timeout))
 	if err != nil {
-		return fmt.Errorf("failed to set read deadline: %w", err)
+		return &TransportError{Op: "set read deadline", Err: err}
 	}
 
-	bytesRead, err := c.Conn.Read(res)
-	if bytesRead < 9 { // Minimum expected response size
-		return fmt.Errorf("reset response too short: got %d bytes", bytesRead)
-	}
-	if err != nil {
-		return fmt.Errorf("failed to read reset response: %w", err)
+	var bytesRead int
+	for {
+		var addr net.Addr
+		bytesRead, addr, err = c.Conn.ReadFrom(res)
+		c.trace(traceRecv, addr, res[:bytesRead], err)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return fmt.Errorf("failed to read reset response: %w", err)
+		}
+		if err != nil {
+			return &TransportError{Op: "read reset response", Err: err}
+		}
+		if !c.fromCamera(addr) {
+			continue
+		}
+		if bytesRead < 9 { // Minimum expected response size
+			c.skipDatagram(addr, res[:bytesRead], 1)
+			continue
+		}
+		if binary.BigEndian.Uint16(res[0:2]) == PayloadTypeVISCAReply {
+			// A late reply to a message sent before the RESET
+			continue
+		}
+		break
 	}
 
 	// Check response payload
 	if res[8] != 0x01 {
-		return fmt.Errorf("invalid reset response: %x", res[:bytesRead])
+		return &ProtocolError{Err: fmt.Errorf("invalid reset response: %x", res[:bytesRead])}
 	}
 
-	c.seqNum = 1
+	c.seqNum.Store(1)
 	return nil
 }
 
 // Close needs to be called before connection can be used to connect
-// to another peripheral device.
+// to another peripheral device. It also stops the heartbeat and waits for a
+// running watchdog recovery to finish.
+//
+// Close may be called several times, from several goroutines: the calls
+// after the first wait for it to finish and return nil.
 func (c *Camera) Close() error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	c.doneMu.Lock()
+	closed := c.closed
+	c.doneMu.Unlock()
+	if closed {
+		return nil
+	}
+
+	// A recovery starts under watchdogMu if the camera is not closing, so
+	// that none starts once Wait is called
+	c.watchdogMu.Lock()
+	c.closing.Store(true)
+	c.watchdogMu.Unlock()
+	c.watchdogWG.Wait()
+	c.stopHeartbeat()
+	c.stopFailsafe()
+
+	c.mu.Lock()
+	c.failPending(net.ErrClosed)
+	c.setState(StateClosed)
+	var err error
 	if c.Conn != nil {
-		return c.Conn.Close()
+		err = c.Conn.Close()
+	}
+	c.mu.Unlock()
+
+	c.doneMu.Lock()
+	c.closed = true
+	close(c.doneChan())
+	c.doneMu.Unlock()
+	return err
+}
+
+// Done returns a channel closed once Close has stopped the heartbeat, the
+// watchdog and the connection of the camera. A Reconnect after Close makes
+// a new channel.
+func (c *Camera) Done() <-chan struct{} {
+	c.doneMu.Lock()
+	defer c.doneMu.Unlock()
+	return c.doneChan()
+}
+
+// doneChan returns the channel of Done, making it if needed. c.doneMu must
+// be held.
+func (c *Camera) doneChan() chan struct{} {
+	if c.done == nil {
+		c.done = make(chan struct{})
+	}
+	return c.done
+}
+
+// ShutdownOptions configures Shutdown.
+type ShutdownOptions struct {
+	// Abort fails the commands sent with SendCommandAsync that have not
+	// completed yet, instead of waiting for them.
+	Abort bool
+	// StopMotion sends a final pan-tilt stop before closing, so that the
+	// camera is not left moving after the application exits.
+	StopMotion bool
+}
+
+// Shutdown closes the camera gracefully. Unless opts.Abort is set, it first
+// waits, until ctx is done, for the completions of the commands sent with
+// SendCommandAsync; those still pending when the camera is closed fail with
+// net.ErrClosed. The stop of opts.StopMotion is sent even if ctx is done.
+// Shutdown returns ctx.Err() if the wait was cut short.
+func (c *Camera) Shutdown(ctx context.Context, opts ShutdownOptions) error {
+	var errs []error
+	if !opts.Abort {
+		errs = append(errs, c.drainPending(ctx))
+	}
+	if opts.StopMotion {
+		errs = append(errs, c.PanTiltStop())
+	}
+	errs = append(errs, c.Close())
+	return errors.Join(errs...)
+}
+
+// drainPending reads replies until no command is pending or ctx is done.
+func (c *Camera) drainPending(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		var p *Completion
+		for _, p = range c.pending {
+			break
+		}
+		if p == nil {
+			c.mu.Unlock()
+			return nil
+		}
+		err := c.awaitPending(ctx, p)
+		c.mu.Unlock()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
 	}
-	return nil
 }
 
 func (c *Camera) Stats() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return fmt.Sprintf(
 		"Missed Responses: %d, Timeouts: %d",
 		c.stats.missedResponses,
 		c.stats.timeouts,
 	)
 }
+
+// Metrics are the counters of the exchanges of a camera since it was
+// created.
+type Metrics struct {
+	// Sent is the number of commands and inquiries sent, retries excluded.
+	Sent int
+	// Retries is the number of times a message was sent again.
+	Retries int
+	// Timeouts is the number of write timeouts and of exchanges abandoned
+	// after the last retry.
+	Timeouts int
+	// MissedResponses is the number of attempts without reply.
+	MissedResponses int
+	// Errors is the number of exchanges that failed, for any reason.
+	Errors int
+}
+
+func (c *Camera) Metrics() Metrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Metrics{
+		Sent:            c.stats.sent,
+		Retries:         c.stats.retries,
+		Timeouts:        c.stats.timeouts,
+		MissedResponses: c.stats.missedResponses,
+		Errors:          c.stats.errors,
+	}
+}
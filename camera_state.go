@@ -0,0 +1,121 @@
+package viscaoverip
+
+import (
+	"errors"
+	"sync"
+)
+
+// CameraState is a snapshot of everything the camera reports through the
+// inquiries of this package. Fields the camera does not answer are nil.
+type CameraState struct {
+	Power    *bool     `json:"power,omitempty"`
+	Position *Position `json:"position,omitempty"`
+
+	Exposure               *ExposureMode     `json:"exposure,omitempty"`
+	ExposureCompensationOn *bool             `json:"exposure_compensation_on,omitempty"`
+	ExposureCompensation   *int              `json:"exposure_compensation,omitempty"`
+	Iris                   *int              `json:"iris,omitempty"`
+	Gain                   *int              `json:"gain,omitempty"`
+	Shutter                *int              `json:"shutter,omitempty"`
+	Backlight              *bool             `json:"backlight,omitempty"`
+	WhiteBalance           *WhiteBalanceMode `json:"white_balance,omitempty"`
+	ColorGain              *int              `json:"color_gain,omitempty"`
+	Hue                    *int              `json:"hue,omitempty"`
+	Gamma                  *int              `json:"gamma,omitempty"`
+	Aperture               *int              `json:"aperture,omitempty"`
+	NoiseReduction         *int              `json:"noise_reduction,omitempty"`
+	Flip                   *bool             `json:"flip,omitempty"`
+	Mirror                 *bool             `json:"mirror,omitempty"`
+	NightMode              *bool             `json:"night_mode,omitempty"`
+	DigitalZoom            *bool             `json:"digital_zoom,omitempty"`
+	Freeze                 *bool             `json:"freeze,omitempty"`
+}
+
+// stateField returns a function storing the result of get into *dst.
+func stateField[T any](dst **T, get func() (T, error)) func() error {
+	return func() error {
+		v, err := get()
+		if err != nil {
+			return err
+		}
+		*dst = &v
+		return nil
+	}
+}
+
+// ReadFullState runs every inquiry of CameraState and returns the complete
+// snapshot, e.g. to initialize an application at startup. The inquiries are
+// issued concurrently and exchanged back to back. Inquiries the camera
+// rejects leave their field nil; an unresponsive camera is an error.
+func (c *Camera) ReadFullState() (CameraState, error) {
+	var s CameraState
+	byteSetting := func(inquiry string) func() (byte, error) {
+		return func() (byte, error) {
+			data, err := c.SendInquiry(inquiry)
+			if err == nil && len(data) != 1 {
+				err = errors.New("unexpected inquiry reply data")
+			}
+			if err != nil {
+				return 0, err
+			}
+			return data[0], nil
+		}
+	}
+	onOff := func(inquiry string) func() (bool, error) {
+		return func() (bool, error) { return c.inquireOnOff(inquiry) }
+	}
+
+	var exposure, whiteBalance, noiseReduction *byte
+	reads := []func() error{
+		stateField(&s.Power, onOff(PowerInquiry)),
+		stateField(&s.Position, c.Position),
+		stateField(&exposure, byteSetting("04 39")),
+		stateField(&s.ExposureCompensationOn, c.ExposureCompensationOn),
+		stateField(&s.ExposureCompensation, c.ExposureCompensation),
+		stateField(&s.Iris, c.Iris),
+		stateField(&s.Gain, c.Gain),
+		stateField(&s.Shutter, c.Shutter),
+		stateField(&s.Backlight, c.Backlight),
+		stateField(&whiteBalance, byteSetting("04 35")),
+		stateField(&s.ColorGain, c.ColorGain),
+		stateField(&s.Hue, c.Hue),
+		stateField(&s.Gamma, c.Gamma),
+		stateField(&s.Aperture, c.Aperture),
+		stateField(&noiseReduction, byteSetting("04 53")),
+		stateField(&s.Flip, onOff("04 66")),
+		stateField(&s.Mirror, onOff("04 61")),
+		stateField(&s.NightMode, c.NightMode),
+		stateField(&s.DigitalZoom, c.DigitalZoom),
+		stateField(&s.Freeze, c.Freeze),
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(reads))
+	for i, read := range reads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = read()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if errors.Is(err, ErrNotResponsive) {
+			return s, err
+		}
+	}
+
+	if exposure != nil {
+		v := ExposureMode(*exposure)
+		s.Exposure = &v
+	}
+	if whiteBalance != nil {
+		v := WhiteBalanceMode(*whiteBalance)
+		s.WhiteBalance = &v
+	}
+	if noiseReduction != nil {
+		v := int(*noiseReduction)
+		s.NoiseReduction = &v
+	}
+	return s, nil
+}
@@ -0,0 +1,43 @@
+package viscaoverip_test
+
+import (
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestReadFullState(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Pan: 100, Tilt: -20, Zoom: 0x1000})
+	if err := camera.SetHue(3); err != nil {
+		t.Fatal(err)
+	}
+	flip := true
+	if err := camera.ApplySettings(voip.Settings{Flip: &flip}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := camera.ReadFullState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Power == nil || !*s.Power {
+		t.Errorf("Power = %v, want on", s.Power)
+	}
+	if want := (voip.Position{Pan: 100, Tilt: -20, Zoom: 0x1000}); s.Position == nil || *s.Position != want {
+		t.Errorf("Position = %v, want %+v", s.Position, want)
+	}
+	if s.Hue == nil || *s.Hue != 3 {
+		t.Errorf("Hue = %v, want 3", s.Hue)
+	}
+	if s.Flip == nil || !*s.Flip {
+		t.Errorf("Flip = %v, want on", s.Flip)
+	}
+	if s.Exposure == nil || *s.Exposure != voip.ExposureFullAuto {
+		t.Errorf("Exposure = %v, want full auto", s.Exposure)
+	}
+	if s.ExposureCompensation == nil || *s.ExposureCompensation != 0 {
+		t.Errorf("ExposureCompensation = %v, want 0", s.ExposureCompensation)
+	}
+}
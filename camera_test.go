@@ -2,22 +2,26 @@ package viscaoverip_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
 )
 
 func TestMakeCommand(t *testing.T) {
 	type testCase struct {
 		name    string
 		command string
-		seqNum  int
+		seqNum  uint32
 		wantStr string
 	}
 	tests := []testCase{
@@ -39,6 +43,24 @@ func TestMakeCommand(t *testing.T) {
 			2489321654, // 946008B6
 			"0100 0006 946008B6 81 01 04 07 22 FF",
 		},
+		{
+			"Notation: 0x prefixes and commas",
+			"0x06, 0x04",
+			1,
+			"0100 0005 00000001 81 01 06 04 FF",
+		},
+		{
+			"Notation: colons",
+			"06:01:18:14:03:01",
+			1,
+			"0100 0009 00000001 81 01 06 01 18 14 03 01 FF",
+		},
+		{
+			"Notation: multi-line",
+			"06 01\n\t18 14\r\n03 01\n",
+			1,
+			"0100 0009 00000001 81 01 06 01 18 14 03 01 FF",
+		},
 	}
 
 	for _, tc := range tests {
@@ -57,77 +79,94 @@ func TestMakeCommand(t *testing.T) {
 	}
 }
 
-type mockServer struct {
-	conn    *net.UDPConn
-	handler func([]byte) [][]byte
-	done    chan struct{}
-	wg      sync.WaitGroup
-}
-
-func newMockServer(t *testing.T) (*mockServer, string) {
-	addr, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+func TestMakePacket(t *testing.T) {
+	wantStr := "0100 0006 00000001 81 0A 11 13 02 FF"
+	want, err := hex.DecodeString(strings.ReplaceAll(wantStr, " ", ""))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	conn, err := net.ListenUDP("udp", addr)
-	if err != nil {
-		t.Fatal(err)
+	message, err := voip.MakePacket("81 0A 11 13 02 FF", 1)
+	if !bytes.Equal(message, want) || err != nil {
+		t.Errorf("MakePacket() = %#v, %v, want %#v, nil", message, err, want)
 	}
 
-	server := &mockServer{
-		conn: conn,
-		done: make(chan struct{}),
+	if _, err := voip.MakePacket("81 0A 1", 1); err == nil {
+		t.Error("MakePacket() with odd length hex: expected error")
 	}
+}
 
-	server.wg.Add(1)
-	go server.serve()
+func TestPayloadValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		make func() ([]byte, error)
+	}{
+		{"command too long", func() ([]byte, error) {
+			return voip.MakeCommand("01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E", 1)
+		}},
+		{"command with embedded terminator", func() ([]byte, error) { return voip.MakeCommand("06 FF 04", 1) }},
+		{"inquiry with embedded terminator", func() ([]byte, error) { return voip.MakeInquiry("04 FF", 1) }},
+		{"packet without terminator", func() ([]byte, error) { return voip.MakePacket("81 01 06 04", 1) }},
+		{"packet without address", func() ([]byte, error) { return voip.MakePacket("01 06 04 FF", 1) }},
+		{"two packets", func() ([]byte, error) { return voip.MakePacket("81 01 06 04 FF 81 01 06 04 FF", 1) }},
+	}
+	for _, tt := range tests {
+		if _, err := tt.make(); !errors.Is(err, voip.ErrInvalidPayload) {
+			t.Errorf("%s: err = %v, want ErrInvalidPayload", tt.name, err)
+		}
+	}
 
-	return server, conn.LocalAddr().String()
+	// The longest valid command
+	if _, err := voip.MakeCommand("01 02 03 04 05 06 07 08 09 0A 0B 0C 0D", 1); err != nil {
+		t.Errorf("MakeCommand() of 16 bytes: %v", err)
+	}
 }
 
-func (s *mockServer) serve() {
-	defer s.wg.Done()
-
-	buf := make([]byte, 1024)
-	for {
-		select {
-		case <-s.done:
-			return
-		default:
-			s.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-			n, remoteAddr, err := s.conn.ReadFrom(buf)
-			if err != nil {
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue
-				}
-				return
-			}
+// mockServer answers with the replies of handler, which may be set after
+// the server started.
+type mockServer struct {
+	*viscatest.Server
+	mu      sync.Mutex
+	handler func([]byte) [][]byte
+}
 
-			if s.handler != nil {
-				responses := s.handler(buf[:n])
-				for _, response := range responses {
-					time.Sleep(1 * time.Millisecond) // Small delay between responses
-					_, err = s.conn.WriteTo(response, remoteAddr)
-					if err != nil {
-						return
-					}
-				}
-			}
+func newMockServer(t *testing.T) (*mockServer, string) {
+	s := &mockServer{}
+	server, err := viscatest.NewServer(func(msg []byte) []viscatest.Reply {
+		s.mu.Lock()
+		handler := s.handler
+		s.mu.Unlock()
+		if handler == nil {
+			return nil
 		}
+		var replies []viscatest.Reply
+		for _, r := range handler(msg) {
+			// Small delay between replies
+			replies = append(replies, viscatest.Reply{Msg: r, Pause: time.Millisecond})
+		}
+		return replies
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
+	s.Server = server
+	return s, server.Addr()
+}
+
+func (s *mockServer) setHandler(handler func([]byte) [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
 }
 
 func (s *mockServer) close() {
-	close(s.done)
-	s.conn.Close()
-	s.wg.Wait()
+	s.Close()
 }
 
 // Helper function to create response messages
 func makeResponse(seqNum uint32, statusCode byte) []byte {
 	response := make([]byte, 12)
-	binary.BigEndian.PutUint16(response[0:2], 0x0101) // Response type
+	binary.BigEndian.PutUint16(response[0:2], 0x0111) // Response type
 	binary.BigEndian.PutUint16(response[2:4], 0x0004) // Payload length
 	binary.BigEndian.PutUint32(response[4:8], seqNum) // Sequence number
 	response[8] = 0x90                                // Response prefix
@@ -140,7 +179,7 @@ func makeResponse(seqNum uint32, statusCode byte) []byte {
 // Helper function to create reset response
 func makeResetResponse() []byte {
 	response := make([]byte, 9)
-	binary.BigEndian.PutUint16(response[0:2], 0x0111)     // Reset response type
+	binary.BigEndian.PutUint16(response[0:2], 0x0201)     // Reset response type
 	binary.BigEndian.PutUint16(response[2:4], 0x0001)     // Payload length
 	binary.BigEndian.PutUint32(response[4:8], 0x00000001) // Sequence number
 	response[8] = 0x01                                    // Reset acknowledge
@@ -159,7 +198,7 @@ func TestSendCommand(t *testing.T) {
 			name: "Success - ACK and Completion",
 			setupHandler: func(s *mockServer) {
 				initialized := false
-				s.handler = func(msg []byte) [][]byte {
+				s.setHandler(func(msg []byte) [][]byte {
 					// Check if this is a reset command (first two bytes are 0x0200)
 					if !initialized && len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
 						initialized = true
@@ -178,7 +217,7 @@ func TestSendCommand(t *testing.T) {
 						makeResponse(seqNum, 0x41), // ACK
 						makeResponse(seqNum, 0x51), // Completion
 					}
-				}
+				})
 			},
 			expectedStats: "Missed Responses: 0, Timeouts: 0",
 			expectedError: false,
@@ -188,7 +227,7 @@ func TestSendCommand(t *testing.T) {
 			setupHandler: func(s *mockServer) {
 				initialized := false
 				firstCommand := true
-				s.handler = func(msg []byte) [][]byte {
+				s.setHandler(func(msg []byte) [][]byte {
 					// Handle initialization sequence
 					if !initialized && len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
 						initialized = true
@@ -210,7 +249,7 @@ func TestSendCommand(t *testing.T) {
 						makeResponse(seqNum, 0x41), // ACK
 						makeResponse(seqNum, 0x51), // Completion
 					}
-				}
+				})
 			},
 			expectedStats: "Missed Responses: 1, Timeouts: 0",
 			expectedError: false,
@@ -220,7 +259,7 @@ func TestSendCommand(t *testing.T) {
 			setupHandler: func(s *mockServer) {
 				initialized := false
 				firstCommand := true
-				s.handler = func(msg []byte) [][]byte {
+				s.setHandler(func(msg []byte) [][]byte {
 					// Handle initialization sequence
 					if !initialized && len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
 						initialized = true
@@ -242,7 +281,7 @@ func TestSendCommand(t *testing.T) {
 						makeResponse(seqNum, 0x41), // ACK
 						makeResponse(seqNum, 0x51), // Completion
 					}
-				}
+				})
 			},
 			expectedStats: "Missed Responses: 1, Timeouts: 0",
 			expectedError: false,
@@ -251,7 +290,7 @@ func TestSendCommand(t *testing.T) {
 			name: "Camera Returns Error Response",
 			setupHandler: func(s *mockServer) {
 				initialized := false
-				s.handler = func(msg []byte) [][]byte {
+				s.setHandler(func(msg []byte) [][]byte {
 					// Handle initialization sequence
 					if !initialized && len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
 						initialized = true
@@ -271,7 +310,7 @@ func TestSendCommand(t *testing.T) {
 						makeResponse(seqNum, 0x41), // ACK
 						makeResponse(seqNum, 0x60), // Error response (syntax error)
 					}
-				}
+				})
 			},
 			expectedStats:  "Missed Responses: 0, Timeouts: 0",
 			expectedError:  true,
@@ -281,7 +320,7 @@ func TestSendCommand(t *testing.T) {
 			name: "Camera Returns Command Buffer Full Error",
 			setupHandler: func(s *mockServer) {
 				initialized := false
-				s.handler = func(msg []byte) [][]byte {
+				s.setHandler(func(msg []byte) [][]byte {
 					// Handle initialization sequence
 					if !initialized && len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
 						initialized = true
@@ -301,7 +340,7 @@ func TestSendCommand(t *testing.T) {
 						makeResponse(seqNum, 0x41), // ACK
 						makeResponse(seqNum, 0x61), // Error response (command buffer full)
 					}
-				}
+				})
 			},
 			expectedStats:  "Missed Responses: 0, Timeouts: 0",
 			expectedError:  true,
@@ -327,9 +366,10 @@ func TestSendCommand(t *testing.T) {
 			}
 
 			cfg := voip.Config{
-				MaxRetries: 3,
-				Timeout:    50 * time.Millisecond,
-				Debug:      true,
+				MaxRetries:        3,
+				Timeout:           50 * time.Millisecond,
+				CompletionTimeout: 50 * time.Millisecond,
+				Debug:             true,
 			}
 
 			camera, err := voip.NewCameraWithConfig(conn, cfg)
@@ -345,10 +385,13 @@ func TestSendCommand(t *testing.T) {
 
 			// Check error message if error is expected
 			if tt.expectedError {
+				var cmdErr *voip.CommandError
 				if err == nil {
 					t.Error("expected error but got nil")
-				} else if err.Error() != tt.expectedErrMsg {
-					t.Errorf("error message = %q, want %q", err.Error(), tt.expectedErrMsg)
+				} else if !errors.As(err, &cmdErr) {
+					t.Errorf("error %q is not a CommandError", err)
+				} else if cmdErr.Err.Error() != tt.expectedErrMsg {
+					t.Errorf("error message = %q, want %q", cmdErr.Err.Error(), tt.expectedErrMsg)
 				}
 			}
 
@@ -358,3 +401,582 @@ func TestSendCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestSendInquiry(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		if msg[0] == 0x01 && msg[1] == 0x10 {
+			// Inquiry reply carries data and has no ACK
+			response := makeResponse(seqNum, 0x50)
+			response[10] = 0x02 // Data: On
+			return [][]byte{response}
+		}
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	data, err := camera.SendInquiry("04 00") // CAM_PowerInq
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x02}) {
+		t.Errorf("SendInquiry() = %x, want 02", data)
+	}
+}
+
+func TestResetFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		resetFallback bool
+		expectedError bool
+	}{
+		{"Fallback Disabled", false, true},
+		{"Fallback Enabled", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, addr := newMockServer(t)
+			defer server.close()
+
+			seqNums := make(chan uint32, 2)
+			server.setHandler(func(msg []byte) [][]byte {
+				// Never answer RESET
+				if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+					return nil
+				}
+				seqNum := binary.BigEndian.Uint32(msg[4:8])
+				seqNums <- seqNum
+				return [][]byte{
+					makeResponse(seqNum, 0x41), // ACK
+					makeResponse(seqNum, 0x51), // Completion
+				}
+			})
+
+			udpAddr, err := net.ResolveUDPAddr("udp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, err := net.DialUDP("udp", nil, udpAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := voip.Config{
+				MaxRetries:    3,
+				Timeout:       50 * time.Millisecond,
+				ResetFallback: tt.resetFallback,
+			}
+			camera, err := voip.NewCameraWithConfig(conn, cfg)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("NewCameraWithConfig() error = %v, expectedError = %v", err, tt.expectedError)
+			}
+			if err != nil {
+				conn.Close()
+				return
+			}
+			defer camera.Close()
+
+			if seqNum := <-seqNums; seqNum != 1 {
+				t.Errorf("first command sequence number = %d, want 1", seqNum)
+			}
+		})
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	received := make(chan struct{}, 8)
+	server.setHandler(func(msg []byte) [][]byte {
+		received <- struct{}{}
+		return nil // Never answer
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{
+		MaxRetries:    100,
+		Timeout:       50 * time.Millisecond,
+		ResetFallback: true,
+	}
+	camera := voip.New(conn, cfg)
+	defer camera.Close()
+
+	select {
+	case <-received:
+		t.Fatal("New() must not perform network I/O")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = camera.Initialize(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Initialize() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Initialize() took %v after context was done", elapsed)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	resets := make(chan struct{}, 4)
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			resets <- struct{}{}
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), addr, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+	<-resets
+
+	oldConn := camera.Conn
+	err = camera.Reconnect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if camera.Conn == oldConn {
+		t.Error("Reconnect() did not replace the connection")
+	}
+	select {
+	case <-resets:
+	default:
+		t.Error("Reconnect() did not reset the sequence number")
+	}
+	if camera.Config != cfg {
+		t.Errorf("Config = %+v, want %+v", camera.Config, cfg)
+	}
+
+	err = camera.SendCommand("06 04")
+	if err != nil {
+		t.Errorf("SendCommand() after Reconnect() error = %v", err)
+	}
+}
+
+func TestCloseIdempotent(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	select {
+	case <-camera.Done():
+		t.Fatal("Done() closed before Close()")
+	default:
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- camera.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Close() = %v", err)
+		}
+	}
+	select {
+	case <-camera.Done():
+	default:
+		t.Fatal("Done() not closed after Close()")
+	}
+	if err := camera.Close(); err != nil {
+		t.Errorf("Close() again = %v", err)
+	}
+
+	if err := camera.Reconnect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-camera.Done():
+		t.Error("Done() closed after Reconnect()")
+	default:
+	}
+}
+
+// unconnectedConn is a socket that receives datagrams from any host, as a
+// camera sitting behind a listening socket would.
+type unconnectedConn struct {
+	*net.UDPConn
+	remote *net.UDPAddr
+}
+
+func (c unconnectedConn) Write(b []byte) (int, error) { return c.WriteTo(b, c.remote) }
+func (c unconnectedConn) RemoteAddr() net.Addr        { return c.remote }
+
+func TestSourceAddressVerification(t *testing.T) {
+	for _, acceptAny := range []bool{false, true} {
+		emulator, err := viscatest.NewEmulator()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer emulator.Close()
+		remote, err := net.ResolveUDPAddr("udp", emulator.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		local, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stranger, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stranger.Close()
+
+		cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond, AcceptAnySource: acceptAny}
+		camera, err := voip.NewCameraWithConfig(unconnectedConn{local, remote}, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer camera.Close()
+
+		// Queue a forged syntax error for the next sequence number
+		seqNum := binary.BigEndian.Uint32(camera.LastExchange().Sent[4:8]) + 1
+		forged := makeResponse(seqNum, 0x60)
+		if _, err := stranger.WriteTo(forged, local.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		err = camera.SendCommand("06 04")
+		if acceptAny && err == nil {
+			t.Error("AcceptAnySource: forged reply was ignored")
+		}
+		if !acceptAny && err != nil {
+			t.Errorf("forged reply was accepted: %v", err)
+		}
+	}
+}
+
+func TestReceiveBufferSize(t *testing.T) {
+	// A block inquiry reply carrying 16 bytes of data
+	data := bytes.Repeat([]byte{0x0A}, 16)
+	for _, size := range []int{0, 64} {
+		server, addr := newMockServer(t)
+		defer server.close()
+		server.setHandler(func(msg []byte) [][]byte {
+			if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+				return [][]byte{makeResetResponse()}
+			}
+			seqNum := binary.BigEndian.Uint32(msg[4:8])
+			if msg[0] == 0x01 && msg[1] == 0x10 {
+				payload := append(append([]byte{0x90, 0x50}, data...), 0xFF)
+				response := make([]byte, 8, 8+len(payload))
+				binary.BigEndian.PutUint16(response[0:2], 0x0111)
+				binary.BigEndian.PutUint16(response[2:4], uint16(len(payload)))
+				binary.BigEndian.PutUint32(response[4:8], seqNum)
+				return [][]byte{append(response, payload...)}
+			}
+			return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+		})
+
+		udpAddr, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := net.DialUDP("udp", nil, udpAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond, ReceiveBufferSize: size}
+		camera, err := voip.NewCameraWithConfig(conn, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer camera.Close()
+
+		got, err := camera.SendInquiry("7E 7E 00")
+		if size == 0 {
+			if !errors.Is(err, voip.ErrReplyTruncated) {
+				t.Errorf("default buffer: err = %v, want ErrReplyTruncated", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("SendInquiry() = % X, want % X", got, data)
+		}
+	}
+}
+
+func TestSeqCompare(t *testing.T) {
+	tests := []struct {
+		a, b uint32
+		want int
+	}{
+		{1, 2, -1},
+		{2, 1, 1},
+		{7, 7, 0},
+		{voip.SequenceNumMax, 0, -1},
+		{0, voip.SequenceNumMax, 1},
+		{voip.SequenceNumMax - 3, 2, -1},
+		{2, voip.SequenceNumMax - 3, 1},
+	}
+	for _, tt := range tests {
+		if got := voip.SeqCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("SeqCompare(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGarbageDatagrams(t *testing.T) {
+	ack := viscatest.ScriptReply{Payload: "90 41 FF"}
+	completion := viscatest.ScriptReply{Payload: "90 51 FF"}
+	script, err := viscatest.NewScript(
+		viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, completion}},
+		viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{
+			{Raw: "01 11 00"},                            // Truncated header
+			{Raw: "01 11 00 03 00 00 00 02 90 41"},       // Truncated payload
+			{Raw: "01 11 00 00 00 00 00 02 90 41 FF"},    // Empty payload announced
+			{Raw: "01 11 00 03 00 00 00 02 90 41 00"},    // No terminator
+			{Raw: "01 11 00 03 00 00 00 02 90 41 FF 00"}, // Trailing junk
+			completion,
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Errorf("SendCommand() = %v, want garbage skipped", err)
+	}
+	if err := script.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplyPayloadTypes(t *testing.T) {
+	ack := viscatest.ScriptReply{Payload: "90 41 FF"}
+	completion := viscatest.ScriptReply{Payload: "90 51 FF"}
+	script, err := viscatest.NewScript(
+		viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, completion}},
+		viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{
+			{Raw: "02 01 00 01 00 00 00 01 01"},       // Late RESET reply
+			{Raw: "01 00 00 03 00 00 00 02 81 41 FF"}, // Not a reply
+			{Raw: "03 00 00 03 00 00 00 02 90 07 FF"}, // Vendor notification
+			ack, completion,
+		}},
+		viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{
+			{Raw: "02 01 00 02 00 00 00 03 0F 01"},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Errorf("SendCommand() = %v, want other payload types skipped", err)
+	}
+	err = camera.SendCommand("06 04")
+	var protoErr *voip.ProtocolError
+	if !errors.Is(err, voip.ErrAbnormalSequence) || !errors.As(err, &protoErr) {
+		t.Errorf("SendCommand() = %v, want ErrAbnormalSequence", err)
+	}
+	if err := script.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSocketRouting(t *testing.T) {
+	ack := viscatest.ScriptReply{Payload: "90 41 FF"}
+	completion := viscatest.ScriptReply{Payload: "90 51 FF"}
+	otherCompletion := viscatest.ScriptReply{Payload: "90 52 FF"}
+	script, err := viscatest.NewScript(
+		viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, completion}},
+		viscatest.Step{Request: "81 09 04 00 FF", Replies: []viscatest.ScriptReply{
+			otherCompletion, {Payload: "90 50 02 FF"},
+		}},
+		viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{
+			ack, otherCompletion, completion,
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	data, err := camera.SendInquiry("04 00")
+	if err != nil || !bytes.Equal(data, []byte{0x02}) {
+		t.Errorf("SendInquiry() = % X, %v, want the reply on socket 0", data, err)
+	}
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+	if replies := camera.LastExchange().Replies; len(replies) != 2 || replies[1][9] != 0x51 {
+		t.Errorf("command replies = % X, want ACK and Completion on socket 1", replies)
+	}
+	if err := script.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSeqNumWraparound(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+	camera.SetSeqNum(voip.SequenceNumMax)
+
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+	if seqNum := binary.BigEndian.Uint32(camera.LastExchange().Sent[4:8]); seqNum != 0 {
+		t.Errorf("sequence number after %d = %d, want 0", uint32(voip.SequenceNumMax), seqNum)
+	}
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Errorf("SendCommand() after wraparound = %v", err)
+	}
+}
+
+func TestNoReset(t *testing.T) {
+	var mu sync.Mutex
+	var resets int
+	var seqNums []uint32
+	server, err := viscatest.NewServer(func(msg []byte) []viscatest.Reply {
+		mu.Lock()
+		defer mu.Unlock()
+		seqNum := viscatest.SeqNum(msg)
+		if msg[0] == 0x02 {
+			resets++
+			return []viscatest.Reply{{Msg: viscatest.MakeResetReply(seqNum)}}
+		}
+		seqNums = append(seqNums, seqNum)
+		return []viscatest.Reply{
+			{Msg: viscatest.MakeReply(seqNum, 0x90, 0x41, 0xFF)},
+			{Msg: viscatest.MakeReply(seqNum, 0x90, 0x51, 0xFF)},
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond, NoReset: true, InitialSeqNum: 41}
+	camera, err := voip.Dial(context.Background(), server.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.Reconnect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if resets != 0 {
+		t.Errorf("%d resets sent with NoReset", resets)
+	}
+	if want := []uint32{42, 43, 44}; !slices.Equal(seqNums, want) {
+		t.Errorf("sequence numbers = %v, want %v", seqNums, want)
+	}
+	if got := camera.SeqNum(); got != 44 {
+		t.Errorf("SeqNum() = %d, want 44", got)
+	}
+}
+
+func TestSendCommandReply(t *testing.T) {
+	ack := viscatest.ScriptReply{Payload: "90 41 FF"}
+	script, err := viscatest.NewScript(
+		viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, {Payload: "90 51 FF"}}},
+		viscatest.Step{Request: "81 01 7E 01 FF", Replies: []viscatest.ScriptReply{ack, {Payload: "90 51 0A 0B FF"}}},
+		viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{ack, {Payload: "90 51 FF"}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	camera, err := voip.Dial(context.Background(), script.Addr(), voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	data, err := camera.SendCommandReply("7E 01")
+	if err != nil || !bytes.Equal(data, []byte{0x0A, 0x0B}) {
+		t.Errorf("SendCommandReply() = % X, %v, want 0A 0B", data, err)
+	}
+	data, err = camera.SendCommandReply("06 04")
+	if err != nil || len(data) != 0 {
+		t.Errorf("SendCommandReply() = % X, %v, want no data", data, err)
+	}
+}
@@ -0,0 +1,175 @@
+// Command viscactl sends VISCA over IP commands and inquiries to a camera,
+// for diagnostics and shell automation.
+//
+// Usage:
+//
+//	viscactl [flags] <command> [arguments]
+//
+// Commands:
+//
+//	raw <hex>            send a command payload, e.g. raw 06 04,
+//	                     or a full packet, e.g. raw 81 01 06 04 FF
+//	inquiry <hex>        send an inquiry and print the reply data
+//	preset <n>           recall preset n
+//	preset store <n>     store the current position as preset n
+//	ptz <pan> <tilt>     drive pan and tilt at signed speeds
+//	zoom <speed>         drive zoom at a signed speed
+//	home                 move to the home position
+//	stop                 stop pan, tilt and zoom
+//	position             print the pan, tilt and zoom positions
+//	version              print the version inquiry reply
+//	ping                 print the round trip time of a power inquiry
+//	tui                  control the camera interactively from the keyboard
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/discovery"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "viscactl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("viscactl", flag.ContinueOnError)
+	addr := fs.String("addr", os.Getenv("VISCA_ADDR"), "camera `host[:port]`, defaults to $VISCA_ADDR")
+	timeout := fs.Duration("timeout", voip.DefaultTimeout, "reply timeout of each attempt")
+	retries := fs.Int("retries", 5, "attempts before giving up")
+	debug := fs.Bool("debug", false, "print the messages exchanged")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: viscactl [flags] <command> [arguments]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+	if *addr == "" {
+		return errors.New("missing camera address: set -addr or $VISCA_ADDR")
+	}
+	address := *addr
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(discovery.DefaultPort))
+	}
+
+	cfg := voip.Config{MaxRetries: *retries, Timeout: *timeout, Debug: *debug}
+	camera, err := voip.Dial(ctx, address, cfg)
+	if err != nil {
+		return err
+	}
+	defer camera.Close()
+
+	command, args := fs.Arg(0), fs.Args()[1:]
+	switch command {
+	case "raw":
+		payload := strings.Join(args, " ")
+		if payload == "" {
+			return errors.New("usage: raw <hex>")
+		}
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(payload)), "8") {
+			return camera.SendPacket(payload)
+		}
+		return camera.SendCommand(payload)
+	case "inquiry":
+		if len(args) == 0 {
+			return errors.New("usage: inquiry <hex>")
+		}
+		data, err := camera.SendInquiry(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, strings.ToUpper(hex.EncodeToString(data)))
+		return nil
+	case "preset":
+		if len(args) == 2 && args[0] == "store" {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid preset: %w", err)
+			}
+			return camera.SetPreset(n)
+		}
+		if len(args) != 1 {
+			return errors.New("usage: preset [store] <n>")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid preset: %w", err)
+		}
+		return camera.RecallPreset(n)
+	case "ptz":
+		if len(args) != 2 {
+			return errors.New("usage: ptz <pan> <tilt>")
+		}
+		pan, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid pan speed: %w", err)
+		}
+		tilt, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid tilt speed: %w", err)
+		}
+		return camera.PanTilt(pan, tilt)
+	case "zoom":
+		if len(args) != 1 {
+			return errors.New("usage: zoom <speed>")
+		}
+		speed, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid zoom speed: %w", err)
+		}
+		return camera.Zoom(speed)
+	case "home":
+		return camera.Home()
+	case "stop":
+		return errors.Join(camera.PanTiltStop(), camera.ZoomStop())
+	case "position":
+		pos, err := camera.Position()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "pan %d tilt %d zoom %d\n", pos.Pan, pos.Tilt, pos.Zoom)
+		return nil
+	case "version":
+		v, err := camera.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "vendor %04X model %04X rom %04X sockets %d\n", v.VendorID, v.ModelID, v.ROMVersion, v.MaxSocket)
+		return nil
+	case "ping":
+		rtt, err := camera.Ping(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, rtt.Round(time.Microsecond))
+		return nil
+	case "tui":
+		restore, err := makeRaw()
+		if err != nil {
+			return err
+		}
+		defer restore()
+		return runTUI(ctx, camera, os.Stdin, stdout, 200*time.Millisecond)
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
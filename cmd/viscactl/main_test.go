@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestRun(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: -5, Tilt: 3, Zoom: 100})
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"inquiry", "04", "00"}, want: "02\n"},
+		{args: []string{"position"}, want: "pan -5 tilt 3 zoom 100\n"},
+		{args: []string{"raw", "81 01 06 04 FF"}},
+		{args: []string{"preset", "store", "4"}},
+		{args: []string{"ptz", "1", "x"}, wantErr: true},
+		{args: []string{"zoom", "8"}, wantErr: true},
+		{args: []string{"dance"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		args := append([]string{"-addr", emulator.Addr()}, tt.args...)
+		err := run(context.Background(), args, &out)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("run(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+		if out.String() != tt.want {
+			t.Errorf("run(%q) printed %q, want %q", tt.args, out.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+const tuiHelp = "arrows pan/tilt  +/- zoom  space stop  0-9 preset  h home  [/] speed  q quit"
+
+// tui is an interactive controller reading key presses. A direction key
+// starts moving that axis until space or the opposite key; there are no
+// key release events in a terminal.
+type tui struct {
+	camera *voip.Camera
+	out    io.Writer
+
+	mu     sync.Mutex // Guards the fields below and writes to out
+	pan    int        // Direction of each axis: -1, 0 or 1
+	tilt   int
+	speed  int // Pan and tilt speed
+	pos    voip.Position
+	status string
+}
+
+// runTUI controls camera with the keys read from in until q or the end of
+// in, drawing a status line to out.
+func runTUI(ctx context.Context, camera *voip.Camera, in io.Reader, out io.Writer, interval time.Duration) error {
+	t := &tui{camera: camera, out: out, speed: voip.MaxTiltSpeed / 2, status: tuiHelp}
+	t.draw()
+
+	positions, unsubscribe := voip.NewPoller(camera, interval).Subscribe()
+	defer unsubscribe()
+	done, stopped := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case pos := <-positions:
+				t.mu.Lock()
+				t.pos = pos
+				t.drawLocked()
+				t.mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	r := bufio.NewReader(in)
+	for ctx.Err() == nil {
+		key, err := readKey(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		if key == "q" || key == "\x03" { // Ctrl-C does not signal in raw mode
+			break
+		}
+		t.handle(key)
+	}
+	t.mu.Lock()
+	fmt.Fprint(out, "\r\n")
+	t.mu.Unlock()
+	return errors.Join(camera.PanTiltStop(), camera.ZoomStop())
+}
+
+// readKey reads a key press, decoding the escape sequences of arrow keys.
+func readKey(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b != 0x1B || r.Buffered() < 2 {
+		return string(b), nil
+	}
+	seq := make([]byte, 2)
+	if _, err := io.ReadFull(r, seq); err != nil {
+		return "", err
+	}
+	switch string(seq) {
+	case "[A":
+		return "up", nil
+	case "[B":
+		return "down", nil
+	case "[C":
+		return "right", nil
+	case "[D":
+		return "left", nil
+	default:
+		return "\x1b" + string(seq), nil
+	}
+}
+
+func (t *tui) handle(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var err error
+	switch key {
+	case "left", "right":
+		t.pan = toggle(t.pan, key == "right")
+		err = t.drive()
+	case "up", "down":
+		t.tilt = toggle(t.tilt, key == "up")
+		err = t.drive()
+	case "[", "]":
+		if key == "]" && t.speed < voip.MaxTiltSpeed {
+			t.speed++
+		} else if key == "[" && t.speed > 1 {
+			t.speed--
+		}
+		err = t.drive()
+	case "+", "=":
+		err = t.camera.Zoom(voip.MaxZoomSpeed / 2)
+	case "-":
+		err = t.camera.Zoom(-voip.MaxZoomSpeed / 2)
+	case " ":
+		t.pan, t.tilt = 0, 0
+		err = errors.Join(t.camera.PanTiltStop(), t.camera.ZoomStop())
+	case "h":
+		t.pan, t.tilt = 0, 0
+		err = t.camera.Home()
+	default:
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+			t.pan, t.tilt = 0, 0
+			err = t.camera.RecallPreset(int(key[0] - '0'))
+			key = "preset " + key
+		} else {
+			t.status = tuiHelp
+			t.drawLocked()
+			return
+		}
+	}
+
+	if err != nil {
+		t.status = err.Error()
+	} else {
+		t.status = strings.TrimSpace(key)
+		if t.status == "" {
+			t.status = "stop"
+		}
+	}
+	t.drawLocked()
+}
+
+// toggle returns the new direction of an axis after a key press: the
+// opposite key of a moving axis stops it.
+func toggle(dir int, positive bool) int {
+	switch {
+	case positive && dir < 0, !positive && dir > 0:
+		return 0
+	case positive:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func (t *tui) drive() error {
+	if t.pan == 0 && t.tilt == 0 {
+		return t.camera.PanTiltStop()
+	}
+	panSpeed := t.speed * voip.MaxPanSpeed / voip.MaxTiltSpeed
+	return t.camera.PanTilt(t.pan*panSpeed, t.tilt*t.speed)
+}
+
+func (t *tui) draw() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.drawLocked()
+}
+
+func (t *tui) drawLocked() {
+	fmt.Fprintf(t.out, "\r\x1b[2Kpan %6d  tilt %6d  zoom %5d  speed %2d | %s",
+		t.pos.Pan, t.pos.Tilt, t.pos.Zoom, t.speed, t.status)
+}
+
+// makeRaw puts the terminal of stdin in raw mode with stty and returns a
+// function restoring it. It fails if stdin is not a terminal.
+func makeRaw() (func(), error) {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&os.ModeCharDevice == 0 {
+		return nil, errors.New("stdin is not a terminal")
+	}
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal state: %w", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to set raw mode: %w", err)
+	}
+	return func() { _, _ = stty(state) }, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestTUI(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: 7})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	var out bytes.Buffer
+	keys := strings.NewReader("\x1b[C+3\x1b[Aq")
+	if err := runTUI(context.Background(), camera, keys, &out, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands [][]byte
+	for _, req := range emulator.Requests() {
+		if req[1] == 0x01 && !bytes.Equal(req, []byte{0x81, 0x01, 0x00, 0x01, 0xFF}) {
+			commands = append(commands, req)
+		}
+	}
+	want := [][]byte{
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x01, 0x02, 0x03, 0xFF}, // Right
+		{0x81, 0x01, 0x04, 0x07, 0x22, 0xFF},                   // Zoom in
+		{0x81, 0x01, 0x04, 0x3F, 0x02, 0x03, 0xFF},             // Preset 3
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x0A, 0x03, 0x01, 0xFF}, // Up, pan stopped by the preset
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}, // Stop on quit
+		{0x81, 0x01, 0x04, 0x07, 0x00, 0xFF},
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("got commands % X, want % X", commands, want)
+	}
+	for i := range want {
+		if !bytes.Equal(commands[i], want[i]) {
+			t.Errorf("command %d = % X, want % X", i, commands[i], want[i])
+		}
+	}
+	if !strings.Contains(out.String(), "preset 3") {
+		t.Errorf("status line never showed the preset: %q", out.String())
+	}
+}
@@ -0,0 +1,100 @@
+package viscaoverip
+
+import "strings"
+
+// driveState tracks the drive commands of one group for Config.CoalesceDrive.
+type driveState struct {
+	gen  uint64 // Incremented by every drive command of the group
+	last string // Last command sent successfully, or empty
+}
+
+// driveGroup returns the drive group of a command payload, or "" if it is
+// not a drive command.
+func driveGroup(cmd string) (group, normalized string) {
+	normalized = strings.ToUpper(cleanHex(cmd))
+	switch {
+	case len(normalized) == 12 && strings.HasPrefix(normalized, "0601"):
+		return "pan-tilt", normalized
+	case len(normalized) == 6 && strings.HasPrefix(normalized, "0407"):
+		return "zoom", normalized
+	default:
+		return "", normalized
+	}
+}
+
+// coalesceBegin registers a command before it waits for its turn, and
+// returns its generation within its drive group.
+func (c *Camera) coalesceBegin(cmd string) uint64 {
+	if !c.Config.CoalesceDrive {
+		return 0
+	}
+	group, _ := driveGroup(cmd)
+	if group == "" {
+		return 0
+	}
+	c.driveMu.Lock()
+	defer c.driveMu.Unlock()
+	if c.drive == nil {
+		c.drive = make(map[string]*driveState)
+	}
+	d := c.drive[group]
+	if d == nil {
+		d = &driveState{}
+		c.drive[group] = d
+	}
+	d.gen++
+	return d.gen
+}
+
+// coalesceSkip reports whether a command must be dropped because it is
+// superseded or repeats the last drive command. Any other command may move
+// the camera, so it forgets the last drive commands. c.mu must be held.
+func (c *Camera) coalesceSkip(cmd string, gen uint64) bool {
+	if !c.Config.CoalesceDrive {
+		return false
+	}
+	group, normalized := driveGroup(cmd)
+	c.driveMu.Lock()
+	defer c.driveMu.Unlock()
+	if group == "" {
+		c.forgetDriveLocked()
+		return false
+	}
+	d := c.drive[group]
+	return d != nil && (d.gen != gen || d.last == normalized)
+}
+
+// forgetDrive forgets the last drive commands, after the camera may have
+// moved or stopped by other means.
+func (c *Camera) forgetDrive() {
+	c.driveMu.Lock()
+	defer c.driveMu.Unlock()
+	c.forgetDriveLocked()
+}
+
+func (c *Camera) forgetDriveLocked() {
+	for _, d := range c.drive {
+		d.last = ""
+	}
+}
+
+// coalesceDone records the outcome of a command. c.mu must be held.
+func (c *Camera) coalesceDone(cmd string, err error) {
+	if !c.Config.CoalesceDrive {
+		return
+	}
+	group, normalized := driveGroup(cmd)
+	if group == "" {
+		return
+	}
+	c.driveMu.Lock()
+	defer c.driveMu.Unlock()
+	d := c.drive[group]
+	if d == nil {
+		return
+	}
+	if err != nil {
+		normalized = "" // The camera may be in any state
+	}
+	d.last = normalized
+}
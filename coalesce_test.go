@@ -0,0 +1,81 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestCoalesceDrive(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond, CoalesceDrive: true}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	countDrives := func() int {
+		n := 0
+		for _, req := range emulator.Requests() {
+			if bytes.HasPrefix(req, []byte{0x81, 0x01, 0x06, 0x01}) {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Identical drive commands are dropped until another command
+	for range 3 {
+		if err := camera.PanTilt(5, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countDrives(); n != 1 {
+		t.Errorf("sent %d drive commands, want 1", n)
+	}
+	camera.Home()
+	camera.PanTilt(5, 0)
+	if n := countDrives(); n != 2 {
+		t.Errorf("sent %d drive commands after Home, want 2", n)
+	}
+
+	// Updates queued behind a slow exchange are coalesced to the latest
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		camera.SendCommand("06 02 18 14 00 00 0F 00 00 00 00 00")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	for speed := 1; speed <= 3; speed++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := camera.PanTilt(-speed, 0); err != nil {
+				t.Error(err)
+			}
+		}()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	requests := emulator.Requests()
+	last := requests[len(requests)-1]
+	if !bytes.Equal(last, []byte{0x81, 0x01, 0x06, 0x01, 0x03, 0x01, 0x01, 0x03, 0xFF}) {
+		t.Errorf("last request = % X, want pan left at 3", last)
+	}
+	if n := countDrives(); n != 3 {
+		t.Errorf("sent %d drive commands, want 3", n)
+	}
+}
@@ -0,0 +1,65 @@
+package viscaoverip
+
+import "fmt"
+
+// Color levels, in steps of the camera. Color gain goes from 60% (0) to
+// 200% (MaxColorGain) in steps of 10%, and hue from -14° to +14° in steps
+// of 2°, with MinHue to MaxHue.
+const (
+	MaxColorGain = 0x0E
+	MinHue       = -7
+	MaxHue       = 7
+	MaxGamma     = 4
+)
+
+// SetColorGain sets the color gain (saturation), from 0 to MaxColorGain
+// (CAM_ColorGain Direct).
+func (c *Camera) SetColorGain(level int) error {
+	if err := checkRange("color gain", level, 0, MaxColorGain); err != nil {
+		return err
+	}
+	return c.setDirect(0x49, level)
+}
+
+// ColorGain inquires the color gain.
+func (c *Camera) ColorGain() (int, error) {
+	return c.inquireDirect(0x49)
+}
+
+// SetHue sets the hue (phase), from MinHue to MaxHue (CAM_ColorHue Direct).
+func (c *Camera) SetHue(level int) error {
+	if err := checkRange("hue", level, MinHue, MaxHue); err != nil {
+		return err
+	}
+	return c.setDirect(0x4F, level-MinHue)
+}
+
+// Hue inquires the hue.
+func (c *Camera) Hue() (int, error) {
+	v, err := c.inquireDirect(0x4F)
+	if err != nil {
+		return 0, err
+	}
+	return v + MinHue, nil
+}
+
+// SetGamma selects the gamma curve, from 0 (standard) to MaxGamma
+// (CAM_Gamma). The curves other than the standard one depend on the model.
+func (c *Camera) SetGamma(mode int) error {
+	if err := checkRange("gamma", mode, 0, MaxGamma); err != nil {
+		return err
+	}
+	return c.SendCommand(fmt.Sprintf("04 5B %02X", mode))
+}
+
+// Gamma inquires the gamma curve.
+func (c *Camera) Gamma() (int, error) {
+	data, err := c.SendInquiry("04 5B")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return int(data[0]), nil
+}
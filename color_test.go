@@ -0,0 +1,48 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestColor(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	if err := camera.SetColorGain(0x0A); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x04, 0x49, 0x00, 0x00, 0x00, 0x0A, 0xFF}) {
+		t.Errorf("SetColorGain(0x0A) sent % X", last)
+	}
+	if v, err := camera.ColorGain(); err != nil || v != 0x0A {
+		t.Errorf("ColorGain() = %#x, %v, want 0x0A", v, err)
+	}
+
+	if err := camera.SetHue(-2); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := camera.Hue(); err != nil || v != -2 {
+		t.Errorf("Hue() = %d, %v, want -2", v, err)
+	}
+
+	if err := camera.SetGamma(2); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := camera.Gamma(); err != nil || v != 2 {
+		t.Errorf("Gamma() = %d, %v, want 2", v, err)
+	}
+
+	for name, err := range map[string]error{
+		"SetColorGain": camera.SetColorGain(voip.MaxColorGain + 1),
+		"SetHue":       camera.SetHue(voip.MinHue - 1),
+		"SetGamma":     camera.SetGamma(voip.MaxGamma + 1),
+	} {
+		if !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("%s() out of range = %v, want ErrInvalidArgument", name, err)
+		}
+	}
+}
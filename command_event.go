@@ -0,0 +1,62 @@
+package viscaoverip
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// CommandEvent reports the outcome of a command: its Completion, or the
+// error that ended it.
+type CommandEvent struct {
+	// Payload is the VISCA payload of the command, e.g. 81 01 06 04 FF.
+	Payload []byte
+	SeqNum  uint32
+	// Elapsed is the time from sending the command to its outcome.
+	Elapsed time.Duration
+	// Err is nil if the camera completed the command.
+	Err error
+}
+
+// OnCommandDone registers fn to be called with the outcome of every command
+// sent, including those of SendCommandAsync once they complete, and
+// returns a function that unregisters it. Inquiries are not reported.
+//
+// fn is called synchronously while the camera is busy. It must return
+// quickly and must not call methods of the Camera.
+func (c *Camera) OnCommandDone(fn func(CommandEvent)) (unsubscribe func()) {
+	c.commandMu.Lock()
+	defer c.commandMu.Unlock()
+	if c.commandListeners == nil {
+		c.commandListeners = make(map[int]func(CommandEvent))
+	}
+	id := c.nextCommandID
+	c.nextCommandID++
+	c.commandListeners[id] = fn
+	return func() {
+		c.commandMu.Lock()
+		defer c.commandMu.Unlock()
+		delete(c.commandListeners, id)
+	}
+}
+
+// notifyCommand calls the OnCommandDone listeners for a message. Inquiries
+// and other payload types are ignored. c.mu must be held.
+func (c *Camera) notifyCommand(message []byte, seqNum uint32, start time.Time, err error) {
+	if binary.BigEndian.Uint16(message) != PayloadTypeVISCACommand {
+		return
+	}
+	c.commandMu.Lock()
+	defer c.commandMu.Unlock()
+	if len(c.commandListeners) == 0 {
+		return
+	}
+	event := CommandEvent{
+		Payload: message[HeaderSize:],
+		SeqNum:  seqNum,
+		Elapsed: time.Since(start),
+		Err:     err,
+	}
+	for _, fn := range c.commandListeners {
+		fn(event)
+	}
+}
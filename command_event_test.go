@@ -0,0 +1,64 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestOnCommandDone(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	var mu sync.Mutex
+	var events []voip.CommandEvent
+	unsubscribe := camera.OnCommandDone(func(e voip.CommandEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := camera.SendInquiry(voip.PowerInquiry); err != nil {
+		t.Fatal(err)
+	}
+	p, err := camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if len(events) != 1 {
+		t.Errorf("got %d events before the async completion, want 1", len(events))
+	}
+	mu.Unlock()
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	unsubscribe()
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"81010604ff", "81010602181400000f0000000000ff"}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if got := hex.EncodeToString(e.Payload); got != want[i] || e.Err != nil {
+			t.Errorf("event %d = %s, %v, want %s", i, got, e.Err, want[i])
+		}
+	}
+	if events[1].Elapsed < 80*time.Millisecond {
+		t.Errorf("async event elapsed = %v, want the duration of the move", events[1].Elapsed)
+	}
+}
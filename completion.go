@@ -0,0 +1,186 @@
+package viscaoverip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrCompletionLost is the error of a pending completion when the camera is
+// re-initialized or reconnected before the completion arrives, since the
+// sequence numbers start over.
+var ErrCompletionLost = errors.New("completion lost: camera re-initialized")
+
+// Completion is the pending completion of a command sent with
+// SendCommandAsync.
+//
+// The completion is received by Wait, or by any other exchange of the
+// camera that reads it first; Done alone does not read from the network.
+type Completion struct {
+	camera  *Camera
+	seqNum  uint32
+	message []byte
+	start   time.Time
+	done    chan struct{}
+	err     error
+}
+
+// SendCommandAsync is like SendCommand, but returns as soon as the camera
+// acknowledges the command, so that long moves do not hold the caller. The
+// Completion reports when the command is executed.
+func (c *Camera) SendCommandAsync(commandHex string, opts ...SendOption) (*Completion, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetDrive()
+	if err := c.connect(context.Background()); err != nil {
+		return nil, err
+	}
+
+	message, err := MakeCommand(commandHex, 0)
+	if err != nil {
+		return nil, err
+	}
+	seqNum, err := c.number(context.Background(), message)
+	if err != nil {
+		return nil, err
+	}
+	o := c.sendOptions(opts)
+	o.untilACK = true
+	start := time.Now()
+	res, err := c.send(context.Background(), message, seqNum, o)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Completion{
+		camera:  c,
+		seqNum:  seqNum,
+		message: message,
+		start:   start,
+		done:    make(chan struct{}),
+	}
+	if res.completed {
+		// The ACK was lost or skipped
+		close(p.done)
+		return p, nil
+	}
+	if c.pending == nil {
+		c.pending = make(map[uint32]*Completion)
+	}
+	c.pending[seqNum] = p
+	return p, nil
+}
+
+// Done returns a channel closed when the completion or an error is received.
+func (p *Completion) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error of the command once Done is closed: nil on
+// completion, the error reply of the camera, or ErrCompletionLost.
+func (p *Completion) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the command completes or ctx is done, reading replies
+// between the exchanges of other calls.
+func (p *Completion) Wait(ctx context.Context) error {
+	c := p.camera
+	for {
+		select {
+		case <-p.done:
+			return p.err
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		c.mu.Lock()
+		err := c.awaitPending(ctx, p)
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// finish records the outcome of the command. c.mu must be held.
+func (p *Completion) finish(err error) {
+	p.err = err
+	close(p.done)
+	p.camera.notifyCommand(p.message, p.seqNum, p.start, err)
+}
+
+// awaitPending reads replies for at most the timeout of the camera,
+// dispatching those of pending commands, until p completes. c.mu must be
+// held.
+func (c *Camera) awaitPending(ctx context.Context, p *Completion) error {
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.Conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.Config.Timeout)); err != nil {
+		return &TransportError{Op: "set read deadline", Err: err}
+	}
+	res := make([]byte, c.receiveBufferSize())
+	for {
+		select {
+		case <-p.done:
+			return nil
+		default:
+		}
+		n, addr, err := c.Conn.ReadFrom(res)
+		c.trace(traceRecv, addr, res[:n], err)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return &TransportError{Op: "read", Err: err}
+		}
+		if !c.fromCamera(addr) || n < 11 || binary.BigEndian.Uint16(res[0:2]) != PayloadTypeVISCAReply {
+			continue
+		}
+		if n, err = messageLength(res, n); err != nil {
+			continue
+		}
+		c.resolvePending(binary.BigEndian.Uint32(res[4:8]), res[8:n])
+	}
+}
+
+// resolvePending completes the pending command with the sequence number of
+// a reply. It reports whether the command was pending. c.mu must be held.
+func (c *Camera) resolvePending(seqNum uint32, payload []byte) bool {
+	p, ok := c.pending[seqNum]
+	if !ok || len(payload) < 3 {
+		return false
+	}
+	switch payload[1] >> 4 {
+	case StatusCodeACK:
+		return true
+	case StatusCodeCompletion:
+		p.finish(nil)
+	default:
+		p.finish(&CameraError{Payload: bytes.Clone(payload)})
+	}
+	delete(c.pending, seqNum)
+	c.gauge(MetricPending, float64(len(c.pending)))
+	c.debugReply(nil, payload, seqNum, "Completion of pending command")
+	return true
+}
+
+// failPending fails every pending command. c.mu must be held.
+func (c *Camera) failPending(err error) {
+	for seqNum, p := range c.pending {
+		p.finish(err)
+		delete(c.pending, seqNum)
+	}
+}
@@ -0,0 +1,128 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestSendCommandAsync(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	// Absolute pan moves of 240 units at full speed take 100ms
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	start := time.Now()
+	p, err := camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("SendCommandAsync() took %v, want return at ACK", elapsed)
+	}
+	select {
+	case <-p.Done():
+		t.Fatal("completed before the move ended")
+	default:
+	}
+
+	// Other exchanges go through while the move is pending
+	if _, err := camera.SendInquiry(voip.PowerInquiry); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Wait() returned after %v, before the move ended", elapsed)
+	}
+	if pan := emulator.State().Pan; pan != 240 {
+		t.Errorf("pan = %d, want 240", pan)
+	}
+
+	// A completion read by another exchange is dispatched without Wait
+	p, err = camera.SendCommandAsync("06 02 18 14 00 00 00 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if _, err := camera.SendInquiry(voip.PowerInquiry); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-p.Done():
+		if p.Err() != nil {
+			t.Errorf("Err() = %v", p.Err())
+		}
+	default:
+		t.Error("completion not dispatched by the inquiry")
+	}
+
+	// Waiting is bounded by the context
+	p, err = camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() = %v, want context.DeadlineExceeded", err)
+	}
+
+	camera.Close()
+	if err := p.Wait(context.Background()); err == nil {
+		t.Error("Wait() after Close(): expected error")
+	}
+}
+
+func TestCompletionTimeout(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	// Absolute pan moves of 240 units at full speed take 100ms
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 20 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	// The move outlasts Timeout but is not retried
+	if err := camera.SendCommand("06 02 18 14 00 00 0F 00 00 00 00 00"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(emulator.Requests()); n != 2 {
+		t.Errorf("got %d requests, want IF_Clear and a single move", n)
+	}
+}
+
+func TestMoveToPosition(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	// The pan move takes 100ms, the zoom move 150ms
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100, ZoomRate: 3414})
+
+	start := time.Now()
+	want := voip.Position{Pan: 240, Tilt: 0, Zoom: 0x1000}
+	if err := camera.MoveToPosition(context.Background(), want, voip.MaxPanSpeed, voip.MaxTiltSpeed); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 130*time.Millisecond {
+		t.Errorf("MoveToPosition() returned after %v, before the zoom arrived", elapsed)
+	}
+	state := emulator.State()
+	if got := (voip.Position{Pan: int(state.Pan), Tilt: int(state.Tilt), Zoom: int(state.Zoom)}); got != want {
+		t.Errorf("position = %+v, want %+v", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := camera.MoveToPosition(ctx, voip.Position{}, voip.MaxPanSpeed, voip.MaxTiltSpeed); err != context.DeadlineExceeded {
+		t.Errorf("MoveToPosition() = %v, want context.DeadlineExceeded", err)
+	}
+}
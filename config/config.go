@@ -0,0 +1,219 @@
+// Package config loads camera deployments from JSON files and builds a
+// Manager from them, so that deployments are data driven:
+//
+//	{
+//	  "cameras": [
+//	    {
+//	      "name": "stage",
+//	      "address": "10.0.0.5",
+//	      "vendor": "sony",
+//	      "timeout": "200ms",
+//	      "max_retries": 5,
+//	      "heartbeat_interval": "1s",
+//	      "presets": {"wide": 1, "podium": 2}
+//	    }
+//	  ]
+//	}
+//
+// Durations are Go duration strings. The port of an address defaults to
+// 52381 and the transport to "udp", the only one supported.
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/discovery"
+)
+
+type File struct {
+	Cameras []Camera `json:"cameras"`
+}
+
+// Camera describes one camera of a deployment. Zero values select the
+// defaults of voip.Config.
+type Camera struct {
+	Name               string         `json:"name"`
+	Address            string         `json:"address"`
+	Transport          string         `json:"transport"`
+	Vendor             string         `json:"vendor"`
+	Timeout            Duration       `json:"timeout"`
+	MaxRetries         int            `json:"max_retries"`
+	ResetFallback      bool           `json:"reset_fallback"`
+	HeartbeatInterval  Duration       `json:"heartbeat_interval"`
+	OfflineAfterMisses int            `json:"offline_after_misses"`
+	WatchdogMisses     int            `json:"watchdog_misses"`
+	WatchdogReconnect  bool           `json:"watchdog_reconnect"`
+	Debug              bool           `json:"debug"`
+	Presets            map[string]int `json:"presets"`
+}
+
+// Duration is a time.Duration encoded as a Go duration string.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Load reads and validates the deployment file at path.
+func Load(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse reads and validates a deployment. Unknown fields are errors, to
+// catch typos.
+func Parse(r io.Reader) (*File, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, f.Validate()
+}
+
+// Validate checks that the cameras have unique names, addresses and
+// supported settings.
+func (f *File) Validate() error {
+	names := make(map[string]bool)
+	var errs []error
+	for i, c := range f.Cameras {
+		switch {
+		case c.Name == "":
+			errs = append(errs, fmt.Errorf("camera %d: missing name", i))
+			continue
+		case names[c.Name]:
+			errs = append(errs, fmt.Errorf("camera %s: duplicate name", c.Name))
+		}
+		names[c.Name] = true
+		if c.Address == "" {
+			errs = append(errs, fmt.Errorf("camera %s: missing address", c.Name))
+		}
+		if c.Transport != "" && c.Transport != "udp" {
+			errs = append(errs, fmt.Errorf("camera %s: unsupported transport: %s", c.Name, c.Transport))
+		}
+		if _, err := parseVendor(c.Vendor); err != nil {
+			errs = append(errs, fmt.Errorf("camera %s: %w", c.Name, err))
+		}
+		for name, n := range c.Presets {
+			if n < 0 || n > voip.MaxPreset {
+				errs = append(errs, fmt.Errorf("camera %s: preset %s must be between 0 and %d: %d", c.Name, name, voip.MaxPreset, n))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseVendor(s string) (voip.Vendor, error) {
+	for _, v := range []voip.Vendor{voip.VendorGeneric, voip.VendorPTZOptics, voip.VendorSony} {
+		if s == v.String() {
+			return v, nil
+		}
+	}
+	if s == "" {
+		return voip.VendorGeneric, nil
+	}
+	return 0, fmt.Errorf("unknown vendor: %s", s)
+}
+
+// Config returns the camera configuration, with the defaults of NewCamera
+// for the unset timeout and retries.
+func (c Camera) Config() voip.Config {
+	vendor, _ := parseVendor(c.Vendor)
+	cfg := voip.Config{
+		MaxRetries:         c.MaxRetries,
+		Timeout:            time.Duration(c.Timeout),
+		Debug:              c.Debug,
+		ResetFallback:      c.ResetFallback,
+		HeartbeatInterval:  time.Duration(c.HeartbeatInterval),
+		OfflineAfterMisses: c.OfflineAfterMisses,
+		WatchdogMisses:     c.WatchdogMisses,
+		WatchdogReconnect:  c.WatchdogReconnect,
+		Vendor:             vendor,
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = voip.DefaultTimeout
+	}
+	return cfg
+}
+
+// DialAddress returns the address with the default port if it has none.
+func (c Camera) DialAddress() string {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return net.JoinHostPort(c.Address, strconv.Itoa(discovery.DefaultPort))
+	}
+	return c.Address
+}
+
+// Camera returns the description of the camera named name.
+func (f *File) Camera(name string) (Camera, bool) {
+	for _, c := range f.Cameras {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Camera{}, false
+}
+
+// Preset returns the number of the named preset of a camera.
+func (f *File) Preset(camera, preset string) (int, bool) {
+	c, ok := f.Camera(camera)
+	if !ok {
+		return 0, false
+	}
+	n, ok := c.Presets[preset]
+	return n, ok
+}
+
+// Manager dials and initializes every camera and registers them in a new
+// Manager. If any camera fails, the cameras already dialed are closed.
+func (f *File) Manager(ctx context.Context) (*voip.Manager, error) {
+	m := voip.NewManager()
+	for _, c := range f.Cameras {
+		camera, err := voip.Dial(ctx, c.DialAddress(), c.Config())
+		if err == nil {
+			err = m.Add(c.Name, camera)
+			if err != nil {
+				camera.Close()
+			}
+		}
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("camera %s: %w", c.Name, err)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/config"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestParse(t *testing.T) {
+	f, err := config.Parse(strings.NewReader(`{
+		"cameras": [
+			{"name": "stage", "address": "10.0.0.5", "vendor": "sony", "timeout": "200ms", "presets": {"wide": 1}},
+			{"name": "side", "address": "10.0.0.6:1259", "max_retries": 2}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stage, ok := f.Camera("stage")
+	if !ok {
+		t.Fatal("camera stage not found")
+	}
+	cfg := stage.Config()
+	if cfg.Vendor != voip.VendorSony || cfg.Timeout != 200*time.Millisecond || cfg.MaxRetries != 5 {
+		t.Errorf("stage config = %+v", cfg)
+	}
+	if got := stage.DialAddress(); got != "10.0.0.5:52381" {
+		t.Errorf("stage address = %s", got)
+	}
+	side, _ := f.Camera("side")
+	if got := side.DialAddress(); got != "10.0.0.6:1259" {
+		t.Errorf("side address = %s", got)
+	}
+	if n, ok := f.Preset("stage", "wide"); !ok || n != 1 {
+		t.Errorf("Preset(stage, wide) = %d, %v", n, ok)
+	}
+	if _, ok := f.Preset("side", "wide"); ok {
+		t.Error("Preset(side, wide) found")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":   `{"cameras": [{"name": "a", "address": "h", "colour": "red"}]}`,
+		"bad duration":    `{"cameras": [{"name": "a", "address": "h", "timeout": 100}]}`,
+		"missing name":    `{"cameras": [{"address": "h"}]}`,
+		"duplicate name":  `{"cameras": [{"name": "a", "address": "h"}, {"name": "a", "address": "i"}]}`,
+		"missing address": `{"cameras": [{"name": "a"}]}`,
+		"transport":       `{"cameras": [{"name": "a", "address": "h", "transport": "serial"}]}`,
+		"vendor":          `{"cameras": [{"name": "a", "address": "h", "vendor": "acme"}]}`,
+		"preset":          `{"cameras": [{"name": "a", "address": "h", "presets": {"x": 300}}]}`,
+	}
+	for name, input := range tests {
+		if _, err := config.Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: Parse() succeeded", name)
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	path := filepath.Join(t.TempDir(), "cameras.json")
+	data := fmt.Sprintf(`{"cameras": [{"name": "cam1", "address": %q, "vendor": "ptzoptics"}]}`, emulator.Addr())
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := f.Manager(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	camera, ok := m.Get("cam1")
+	if !ok {
+		t.Fatal("camera cam1 not registered")
+	}
+	if camera.Config.Vendor != voip.VendorPTZOptics {
+		t.Errorf("vendor = %v, want ptzoptics", camera.Config.Vendor)
+	}
+	if camera.State() != voip.StateConnected {
+		t.Errorf("state = %v, want connected", camera.State())
+	}
+}
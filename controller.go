@@ -0,0 +1,21 @@
+package viscaoverip
+
+// Controller is the camera control used by most applications. *Camera
+// implements it; applications that depend on Controller rather than
+// *Camera can be unit tested with a mock instead of a UDP socket.
+type Controller interface {
+	SendCommand(commandHex string, opts ...SendOption) error
+	SendInquiry(inquiryHex string, opts ...SendOption) ([]byte, error)
+	PanTilt(pan, tilt int) error
+	PanTiltStop() error
+	Zoom(speed int) error
+	ZoomStop() error
+	MoveTo(pan, tilt, panSpeed, tiltSpeed int) error
+	ZoomTo(zoom int) error
+	RecallPreset(preset int) error
+	Position() (Position, error)
+	State() State
+	Close() error
+}
+
+var _ Controller = (*Camera)(nil)
@@ -0,0 +1,52 @@
+package viscaoverip_test
+
+import (
+	"fmt"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// mockController records the calls of an application under test.
+type mockController struct {
+	voip.Controller // Panics on methods the test does not expect
+	calls           []string
+}
+
+func (m *mockController) RecallPreset(preset int) error {
+	m.calls = append(m.calls, fmt.Sprintf("RecallPreset(%d)", preset))
+	return nil
+}
+
+func (m *mockController) ZoomTo(zoom int) error {
+	m.calls = append(m.calls, fmt.Sprintf("ZoomTo(%d)", zoom))
+	return nil
+}
+
+// goWide is application code written against Controller.
+func goWide(c voip.Controller) error {
+	if err := c.RecallPreset(1); err != nil {
+		return err
+	}
+	return c.ZoomTo(0)
+}
+
+func TestControllerMock(t *testing.T) {
+	m := &mockController{}
+	if err := goWide(m); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"RecallPreset(1)", "ZoomTo(0)"}
+	if fmt.Sprint(m.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", m.calls, want)
+	}
+
+	// The real camera is a drop-in replacement
+	camera, emulator := newEmulatedCamera(t)
+	if err := goWide(camera); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(emulator.Requests()); n != 3 {
+		t.Errorf("camera received %d requests, want IF_Clear and 2 commands", n)
+	}
+}
@@ -0,0 +1,50 @@
+package viscaoverip
+
+import "fmt"
+
+// setOnOff sends an on/off command: the payload cmd followed by 02 or 03.
+func (c *Camera) setOnOff(cmd string, on bool) error {
+	return c.SendCommand(cmd + " " + onOff(on))
+}
+
+// inquireOnOff sends an inquiry answered with 02 (on) or 03 (off).
+func (c *Camera) inquireOnOff(inquiry string) (bool, error) {
+	data, err := c.SendInquiry(inquiry)
+	if err != nil {
+		return false, err
+	}
+	if len(data) != 1 || (data[0] != 0x02 && data[0] != 0x03) {
+		return false, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return data[0] == 0x02, nil
+}
+
+// setDirect sends a direct value command: 04 xx 00 00 0p 0q.
+func (c *Camera) setDirect(register byte, v int) error {
+	return c.SendCommand(directCommand(register, v))
+}
+
+func directCommand(register byte, v int) string {
+	return fmt.Sprintf("04 %02X 00 00 %s", register, encodeNibbles(v, 2))
+}
+
+// inquireDirect sends a direct value inquiry (09 04 xx), answered with
+// 00 00 0p 0q.
+func (c *Camera) inquireDirect(register byte) (int, error) {
+	data, err := c.SendInquiry(fmt.Sprintf("04 %02X", register))
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return decodeNibbles(data, false), nil
+}
+
+// checkRange returns ErrInvalidArgument unless min <= v <= max.
+func checkRange(name string, v, min, max int) error {
+	if v < min || v > max {
+		return fmt.Errorf("%w: %s must be between %d and %d: %d", ErrInvalidArgument, name, min, max, v)
+	}
+	return nil
+}
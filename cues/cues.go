@@ -0,0 +1,353 @@
+// Package cues loads cue lists and steps through them with GO and back
+// controls, for scripted productions. A cue runs an action on a camera of
+// a Manager:
+//
+//	preset 3          recall preset 3
+//	ptz 12 -5 0       drive pan, tilt and zoom at signed speeds
+//	zoom 4            drive zoom at a signed speed
+//	home              go to the home position
+//	stop              stop pan, tilt and zoom
+//	power on|off      power on or standby
+//	command 06 04     send a raw VISCA command (hex, without 8x 01 and FF)
+//
+// A cue list is a JSON array:
+//
+//	[
+//	  {"name": "Opening", "camera": "stage", "action": "preset", "params": "1"},
+//	  {"camera": "stage", "action": "zoom", "params": "-3", "wait": "2s", "trigger": "follow"}
+//	]
+//
+// or a CSV file with a header row naming the same columns, in any order:
+//
+//	name,camera,action,params,wait,trigger
+//	Opening,stage,preset,1,,
+//	,stage,zoom,-3,2s,follow
+//
+// A cue triggered by "go", the default, waits for GO. A cue triggered by
+// "follow" runs right after the previous cue. Either way, a cue runs wait
+// after being triggered.
+package cues
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/config"
+)
+
+// Triggers of a cue.
+const (
+	TriggerGo     = "go"
+	TriggerFollow = "follow"
+)
+
+// Cue is a step of a cue list.
+type Cue struct {
+	Name   string `json:"name,omitempty"`
+	Camera string `json:"camera"`
+	Action string `json:"action"`
+	// Params are the parameters of the action, separated by spaces.
+	Params  string          `json:"params,omitempty"`
+	Wait    config.Duration `json:"wait,omitempty"`
+	Trigger string          `json:"trigger,omitempty"`
+}
+
+// String returns the name of the cue, or its action if unnamed.
+func (c Cue) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strings.TrimSpace(c.Camera + " " + c.Action + " " + c.Params)
+}
+
+// Validate checks the trigger, the action and its parameters.
+func (c Cue) Validate() error {
+	if c.Camera == "" {
+		return errors.New("missing camera")
+	}
+	if c.Trigger != "" && c.Trigger != TriggerGo && c.Trigger != TriggerFollow {
+		return fmt.Errorf("unknown trigger: %s", c.Trigger)
+	}
+	_, err := c.action()
+	return err
+}
+
+// Run runs the action of the cue on its camera in m, without waiting.
+func (c Cue) Run(m *voip.Manager) error {
+	action, err := c.action()
+	if err != nil {
+		return err
+	}
+	camera, ok := m.Get(c.Camera)
+	if !ok {
+		return fmt.Errorf("unknown camera: %s", c.Camera)
+	}
+	return action(camera)
+}
+
+// action parses the action and its parameters.
+func (c Cue) action() (func(*voip.Camera) error, error) {
+	params := strings.Fields(c.Params)
+	ints := func(lo, hi int) ([]int, error) {
+		if len(params) < lo || len(params) > hi {
+			if lo == hi {
+				return nil, fmt.Errorf("%s takes %d parameters, got %d", c.Action, lo, len(params))
+			}
+			return nil, fmt.Errorf("%s takes %d to %d parameters, got %d", c.Action, lo, hi, len(params))
+		}
+		values := make([]int, hi)
+		for i, p := range params {
+			v, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.Action, err)
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	switch c.Action {
+	case "preset":
+		v, err := ints(1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if v[0] < 0 || v[0] > voip.MaxPreset {
+			return nil, fmt.Errorf("preset must be between 0 and %d: %d", voip.MaxPreset, v[0])
+		}
+		return func(camera *voip.Camera) error { return camera.RecallPreset(v[0]) }, nil
+	case "ptz":
+		v, err := ints(2, 3)
+		if err != nil {
+			return nil, err
+		}
+		return func(camera *voip.Camera) error {
+			return errors.Join(camera.PanTilt(v[0], v[1]), camera.Zoom(v[2]))
+		}, nil
+	case "zoom":
+		v, err := ints(1, 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(camera *voip.Camera) error { return camera.Zoom(v[0]) }, nil
+	case "home", "stop":
+		if _, err := ints(0, 0); err != nil {
+			return nil, err
+		}
+		if c.Action == "home" {
+			return (*voip.Camera).Home, nil
+		}
+		return func(camera *voip.Camera) error {
+			return errors.Join(camera.PanTiltStop(), camera.ZoomStop())
+		}, nil
+	case "power":
+		if len(params) != 1 || (params[0] != "on" && params[0] != "off") {
+			return nil, fmt.Errorf("power takes on or off, got %q", c.Params)
+		}
+		on := params[0] == "on"
+		return func(camera *voip.Camera) error { return camera.SetPower(on) }, nil
+	case "command":
+		if _, err := hex.DecodeString(strings.Join(params, "")); err != nil || len(params) == 0 {
+			return nil, fmt.Errorf("command takes hex bytes, got %q", c.Params)
+		}
+		return func(camera *voip.Camera) error { return camera.SendCommand(c.Params) }, nil
+	default:
+		return nil, fmt.Errorf("unknown action: %s", c.Action)
+	}
+}
+
+// LoadFile loads the cue list at path, a JSON file or, with the .csv
+// extension, a CSV file.
+func LoadFile(path string) ([]Cue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cues []Cue
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		cues, err = ParseCSV(f)
+	} else {
+		cues, err = ParseJSON(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cues, nil
+}
+
+// ParseJSON reads and validates a JSON cue list. Unknown fields are
+// errors, to catch typos.
+func ParseJSON(r io.Reader) ([]Cue, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var cues []Cue
+	if err := dec.Decode(&cues); err != nil {
+		return nil, err
+	}
+	return cues, validate(cues)
+}
+
+// ParseCSV reads and validates a CSV cue list.
+func ParseCSV(r io.Reader) ([]Cue, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "name", "camera", "action", "params", "wait", "trigger":
+		default:
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		columns[name] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var cues []Cue
+	for n, record := range records[1:] {
+		c := Cue{
+			Name:    field(record, "name"),
+			Camera:  field(record, "camera"),
+			Action:  field(record, "action"),
+			Params:  field(record, "params"),
+			Trigger: field(record, "trigger"),
+		}
+		if wait := field(record, "wait"); wait != "" {
+			d, err := time.ParseDuration(wait)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+2, err)
+			}
+			c.Wait = config.Duration(d)
+		}
+		cues = append(cues, c)
+	}
+	return cues, validate(cues)
+}
+
+func validate(cues []Cue) error {
+	var errs []error
+	for i, c := range cues {
+		if err := c.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("cue %d (%s): %w", i+1, c, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Runner steps through a cue list. It is safe for concurrent use; a GO
+// while another runs waits for it.
+type Runner struct {
+	manager *voip.Manager
+	cues    []Cue
+
+	run  sync.Mutex // Serializes Go
+	mu   sync.Mutex // Guards next
+	next int
+}
+
+// NewRunner returns a Runner of cues on the cameras of m, standing by on
+// the first cue.
+func NewRunner(m *voip.Manager, cues []Cue) *Runner {
+	return &Runner{manager: m, cues: cues}
+}
+
+// Next returns the index of the cue standing by, len(Cues()) at the end.
+func (r *Runner) Next() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.next
+}
+
+// Cues returns the cue list.
+func (r *Runner) Cues() []Cue {
+	return r.cues
+}
+
+// Go runs the cue standing by and the cues that follow it, then stands by
+// on the next cue waiting for GO. It stops at the first cue that fails,
+// standing by on the cue after it. Cancelling ctx interrupts the waits,
+// standing by on the cue that was waiting.
+func (r *Runner) Go(ctx context.Context) error {
+	r.run.Lock()
+	defer r.run.Unlock()
+
+	r.mu.Lock()
+	i := r.next
+	r.mu.Unlock()
+	if i >= len(r.cues) {
+		return errors.New("end of the cue list")
+	}
+	for {
+		c := r.cues[i]
+		if c.Wait > 0 {
+			timer := time.NewTimer(time.Duration(c.Wait))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				r.standBy(i)
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		err := c.Run(r.manager)
+		i++
+		r.standBy(i)
+		if err != nil {
+			return fmt.Errorf("cue %d (%s): %w", i, c, err)
+		}
+		if i >= len(r.cues) || r.cues[i].Trigger != TriggerFollow {
+			return nil
+		}
+	}
+}
+
+// Back stands by on the previous cue waiting for GO, skipping the cues
+// that follow others, without running anything.
+func (r *Runner) Back() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.next > 0 {
+		r.next--
+		if r.cues[r.next].Trigger != TriggerFollow {
+			return
+		}
+	}
+}
+
+// GoTo stands by on the cue at index i, without running anything.
+func (r *Runner) GoTo(i int) error {
+	if i < 0 || i > len(r.cues) {
+		return fmt.Errorf("no cue %d", i)
+	}
+	r.standBy(i)
+	return nil
+}
+
+func (r *Runner) standBy(i int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next = i
+}
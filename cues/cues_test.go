@@ -0,0 +1,119 @@
+package cues_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/cues"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestParse(t *testing.T) {
+	fromJSON, err := cues.ParseJSON(strings.NewReader(`[
+		{"name": "Opening", "camera": "stage", "action": "preset", "params": "1"},
+		{"camera": "stage", "action": "zoom", "params": "-3", "wait": "2s", "trigger": "follow"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := cues.ParseCSV(strings.NewReader("name,camera,action,params,wait,trigger\n" +
+		"Opening,stage,preset,1,,\n" +
+		",stage,zoom,-3,2s,follow\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cues.Cue{
+		{Name: "Opening", Camera: "stage", Action: "preset", Params: "1"},
+		{Camera: "stage", Action: "zoom", Params: "-3", Wait: 2e9, Trigger: cues.TriggerFollow},
+	}
+	for _, got := range [][]cues.Cue{fromJSON, fromCSV} {
+		if fmt.Sprint(got) != fmt.Sprint(want) || got[1] != want[1] {
+			t.Errorf("cues = %+v, want %+v", got, want)
+		}
+	}
+
+	for _, bad := range []string{
+		`[{"camera": "stage", "action": "dance"}]`,
+		`[{"camera": "stage", "action": "preset", "params": "300"}]`,
+		`[{"camera": "stage", "action": "ptz", "params": "1"}]`,
+		`[{"camera": "stage", "action": "power", "params": "maybe"}]`,
+		`[{"camera": "stage", "action": "home", "trigger": "later"}]`,
+		`[{"action": "home"}]`,
+		`[{"camera": "stage", "action": "home", "delay": "1s"}]`,
+	} {
+		if _, err := cues.ParseJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseJSON(%s) succeeded", bad)
+		}
+	}
+	if _, err := cues.ParseCSV(strings.NewReader("camera,action,speed\nstage,zoom,1\n")); err == nil {
+		t.Error("ParseCSV() with unknown column succeeded")
+	}
+}
+
+func TestRunner(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	m.Add("stage", camera)
+
+	list, err := cues.ParseCSV(strings.NewReader("camera,action,params,wait,trigger\n" +
+		"stage,home,,,\n" +
+		"stage,ptz,1 2,,\n" +
+		"stage,stop,,10ms,follow\n" +
+		"stage,command,04 07 02,,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := cues.NewRunner(m, list)
+
+	before := len(emulator.Requests())
+	step := func(wantNext int, wantSent ...[]byte) {
+		t.Helper()
+		if err := runner.Go(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if next := runner.Next(); next != wantNext {
+			t.Errorf("Next() = %d, want %d", next, wantNext)
+		}
+		requests := emulator.Requests()[before:]
+		before += len(requests)
+		if len(requests) != len(wantSent) {
+			t.Fatalf("sent % X, want % X", requests, wantSent)
+		}
+		for i := range requests {
+			if !bytes.Equal(requests[i][:len(wantSent[i])], wantSent[i]) {
+				t.Errorf("sent % X, want % X", requests[i], wantSent[i])
+			}
+		}
+	}
+
+	step(1, []byte{0x81, 0x01, 0x06, 0x04})
+	// The stop follows the pan-tilt and zoom drive
+	step(3, []byte{0x81, 0x01, 0x06, 0x01}, []byte{0x81, 0x01, 0x04, 0x07}, []byte{0x81, 0x01, 0x06, 0x01}, []byte{0x81, 0x01, 0x04, 0x07})
+
+	runner.Back()
+	if next := runner.Next(); next != 1 {
+		t.Errorf("Next() after Back() = %d, want 1, skipping the follow cue", next)
+	}
+	if err := runner.GoTo(3); err != nil {
+		t.Fatal(err)
+	}
+	step(4, []byte{0x81, 0x01, 0x04, 0x07, 0x02})
+	if err := runner.Go(context.Background()); err == nil {
+		t.Error("Go() at the end of the list succeeded")
+	}
+}
@@ -0,0 +1,160 @@
+package viscaoverip
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// DebugEvent is an event of the debug stream of a camera: a SendEvent,
+// RetryEvent, ReplyEvent or ErrorEvent. Its String method renders it as a
+// log line, as printed with Config.Debug.
+type DebugEvent interface {
+	fmt.Stringer
+	debugEvent()
+}
+
+// SendEvent reports a message written to the camera for the first time.
+type SendEvent struct {
+	Time    time.Time
+	Message []byte
+	SeqNum  uint32
+}
+
+// RetryEvent reports a message written again after an attempt failed.
+type RetryEvent struct {
+	Time    time.Time
+	Message []byte
+	SeqNum  uint32
+	// Attempt is the number of the attempt, from 2.
+	Attempt int
+	// Err is why the previous attempt failed, e.g. a read timeout.
+	Err error
+}
+
+// ReplyEvent reports a datagram received while waiting for the reply to the
+// message numbered SeqNum, and what was made of it.
+type ReplyEvent struct {
+	Time    time.Time
+	From    net.Addr
+	Message []byte
+	SeqNum  uint32
+	// Note tells how the datagram was taken, e.g. "ACK" or "old reply".
+	Note string
+}
+
+// ErrorEvent reports a failure that is not returned to any caller, e.g. of
+// the heartbeat, or an anomaly worked around.
+type ErrorEvent struct {
+	Time time.Time
+	// Op is what failed, e.g. "heartbeat".
+	Op  string
+	Err error
+}
+
+func (SendEvent) debugEvent()  {}
+func (RetryEvent) debugEvent() {}
+func (ReplyEvent) debugEvent() {}
+func (ErrorEvent) debugEvent() {}
+
+func (e SendEvent) String() string {
+	return fmt.Sprintf("Sent sequence %d: % X", e.SeqNum, e.Message)
+}
+
+func (e RetryEvent) String() string {
+	return fmt.Sprintf("Retrying sequence %d (attempt %d) after %v", e.SeqNum, e.Attempt, e.Err)
+}
+
+func (e ReplyEvent) String() string {
+	return fmt.Sprintf("Received %s for sequence %d from %v: % X", e.Note, e.SeqNum, e.From, e.Message)
+}
+
+func (e ErrorEvent) String() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+// OnDebug registers fn to be called with every debug event of the camera,
+// whether or not Config.Debug is set, and returns a function that
+// unregisters it.
+//
+// fn is called synchronously, often while the camera is busy. It must
+// return quickly, must not call methods of the Camera and must not retain
+// the Message of the events.
+func (c *Camera) OnDebug(fn func(DebugEvent)) (unsubscribe func()) {
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+	if c.debugListeners == nil {
+		c.debugListeners = make(map[int]func(DebugEvent))
+	}
+	id := c.nextDebugID
+	c.nextDebugID++
+	c.debugListeners[id] = fn
+	c.debugListening.Store(int32(len(c.debugListeners)))
+	return func() {
+		c.debugMu.Lock()
+		defer c.debugMu.Unlock()
+		delete(c.debugListeners, id)
+		c.debugListening.Store(int32(len(c.debugListeners)))
+	}
+}
+
+// DebugEvents returns a channel receiving the debug events of the camera,
+// with a buffer of size events, and a function to unsubscribe. Events that
+// do not fit in the buffer are dropped rather than slowing the camera down.
+// The Message of the events is a copy.
+func (c *Camera) DebugEvents(size int) (<-chan DebugEvent, func()) {
+	ch := make(chan DebugEvent, size)
+	unsubscribe := c.OnDebug(func(e DebugEvent) {
+		select {
+		case ch <- cloneDebugEvent(e):
+		default:
+		}
+	})
+	return ch, unsubscribe
+}
+
+func cloneDebugEvent(e DebugEvent) DebugEvent {
+	switch e := e.(type) {
+	case SendEvent:
+		e.Message = append([]byte(nil), e.Message...)
+		return e
+	case RetryEvent:
+		e.Message = append([]byte(nil), e.Message...)
+		return e
+	case ReplyEvent:
+		e.Message = append([]byte(nil), e.Message...)
+		return e
+	}
+	return e
+}
+
+// ReportError emits an ErrorEvent for a failure of op, for the background
+// tasks built on the camera, such as pollers, to report the errors they
+// have no caller to return to.
+func (c *Camera) ReportError(op string, err error) {
+	if c.debugging() {
+		c.debug(ErrorEvent{Time: time.Now(), Op: op, Err: err})
+	}
+}
+
+// debugging reports whether debug events are printed or listened to, for
+// their emitters to skip building them otherwise.
+func (c *Camera) debugging() bool {
+	return c.Config.Debug || c.debugListening.Load() > 0
+}
+
+// debug prints event if Config.Debug is set, and delivers it to the OnDebug
+// listeners.
+func (c *Camera) debug(event DebugEvent) {
+	if c.Config.Debug {
+		fmt.Println(event)
+	}
+	if c.debugListening.Load() == 0 {
+		return
+	}
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+	for _, fn := range c.debugListeners {
+		fn(event)
+	}
+}
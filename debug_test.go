@@ -0,0 +1,75 @@
+package viscaoverip_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestDebugEvents(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var commands atomic.Int32
+	server.setHandler(func(msg []byte) [][]byte {
+		if msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		if msg[10] == 0x06 && commands.Add(1) == 1 {
+			return nil // First attempt missed
+		}
+		return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera, err := voip.NewCameraWithConfig(conn, voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	events, unsubscribe := camera.DebugEvents(16)
+	defer unsubscribe()
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []voip.DebugEvent
+	for len(events) > 0 {
+		got = append(got, <-events)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d events, want 4: %v", len(got), got)
+	}
+	if _, ok := got[0].(voip.SendEvent); !ok {
+		t.Errorf("event 0 = %v, want SendEvent", got[0])
+	}
+	if e, ok := got[1].(voip.RetryEvent); !ok || e.Attempt != 2 || !errors.Is(e.Err, os.ErrDeadlineExceeded) {
+		t.Errorf("event 1 = %v, want RetryEvent after a read timeout", got[1])
+	}
+	for i, note := range []string{"ACK", "Completion"} {
+		if e, ok := got[2+i].(voip.ReplyEvent); !ok || e.Note != note {
+			t.Errorf("event %d = %v, want ReplyEvent %s", 2+i, got[2+i], note)
+		}
+	}
+
+	unsubscribe()
+	camera.ReportError("test", errors.New("unreported"))
+	if len(events) != 0 {
+		t.Errorf("event after unsubscribe: %v", <-events)
+	}
+}
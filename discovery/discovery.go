@@ -0,0 +1,157 @@
+// Package discovery finds VISCA over IP cameras on the network.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+const (
+	// DefaultPort is the VISCA over IP port
+	DefaultPort    = 52381
+	DefaultTimeout = time.Second
+
+	// maxHosts bounds the size of a scanned range (a /16 in IPv4)
+	maxHosts = 1 << 16
+)
+
+type Config struct {
+	// Port to probe, DefaultPort if zero.
+	Port int
+	// Timeout is how long to wait for replies after the last probe is sent,
+	// DefaultTimeout if zero.
+	Timeout time.Duration
+}
+
+// Device is a camera that answered a probe.
+type Device struct {
+	Addr    netip.AddrPort
+	Version voip.Version
+}
+
+// Scan probes every host of the cidr range with a version inquiry, which
+// has no side effects on the cameras, and returns the devices that answered,
+// sorted by address.
+func Scan(ctx context.Context, cidr string, cfg Config) ([]Device, error) {
+	if cfg.Port == 0 {
+		cfg.Port = DefaultPort
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	hosts, err := Hosts(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open socket: %w", err)
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	probe, err := voip.MakeInquiry(voip.VersionInquiry, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range hosts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		addr := net.UDPAddrFromAddrPort(netip.AddrPortFrom(host, uint16(cfg.Port)))
+		// Unreachable hosts are simply not found
+		_, _ = conn.WriteToUDP(probe, addr)
+	}
+
+	err = conn.SetReadDeadline(time.Now().Add(cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	found := make(map[netip.AddrPort]Device)
+	buf := make([]byte, voip.MessageBufferSize)
+	for {
+		n, addr, err := conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			return nil, err
+		}
+		version, ok := parseReply(buf[:n])
+		if !ok {
+			continue
+		}
+		addr = netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port())
+		found[addr] = Device{Addr: addr, Version: version}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	devices := make([]Device, 0, len(found))
+	for _, d := range found {
+		devices = append(devices, d)
+	}
+	slices.SortFunc(devices, func(a, b Device) int {
+		return a.Addr.Compare(b.Addr)
+	})
+	return devices, nil
+}
+
+// parseReply decodes a VISCA over IP inquiry reply carrying version data.
+func parseReply(msg []byte) (voip.Version, bool) {
+	// Header (8) + 90 50 + version data (7) + FF
+	if len(msg) != 18 || binary.BigEndian.Uint16(msg[0:2]) != 0x0111 {
+		return voip.Version{}, false
+	}
+	payload := msg[8:]
+	if !bytes.Equal(payload[0:2], []byte{0x90, 0x50}) || payload[len(payload)-1] != 0xFF {
+		return voip.Version{}, false
+	}
+	version, err := voip.ParseVersion(payload[2 : len(payload)-1])
+	if err != nil {
+		return voip.Version{}, false
+	}
+	return version, true
+}
+
+// Hosts returns the host addresses of the cidr range. For IPv4 ranges larger
+// than a /31, the network and broadcast addresses are left out.
+func Hosts(cidr string) ([]netip.Addr, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR range: %w", err)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return nil, fmt.Errorf("CIDR range too large: %s, at most %d hosts", cidr, maxHosts)
+	}
+
+	var hosts []netip.Addr
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		hosts = append(hosts, addr)
+	}
+	if prefix.Addr().Is4() && hostBits > 1 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
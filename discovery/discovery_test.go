@@ -0,0 +1,88 @@
+package discovery_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/discovery"
+)
+
+func TestHosts(t *testing.T) {
+	tests := []struct {
+		cidr      string
+		wantLen   int
+		wantFirst string
+		wantErr   bool
+	}{
+		{"192.168.1.0/24", 254, "192.168.1.1", false},
+		{"192.168.1.77/24", 254, "192.168.1.1", false},
+		{"10.0.0.8/31", 2, "10.0.0.8", false},
+		{"10.0.0.8/32", 1, "10.0.0.8", false},
+		{"10.0.0.0/8", 0, "", true},
+		{"not a cidr", 0, "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.cidr, func(t *testing.T) {
+			hosts, err := discovery.Hosts(tc.cidr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Hosts(%q) error = %v, wantErr %v", tc.cidr, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(hosts) != tc.wantLen || hosts[0].String() != tc.wantFirst {
+				t.Errorf("Hosts(%q) = %d hosts starting at %v, want %d starting at %s",
+					tc.cidr, len(hosts), hosts[0], tc.wantLen, tc.wantFirst)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			msg := buf[:n]
+			if n < 8 || msg[0] != 0x01 || msg[1] != 0x10 {
+				continue
+			}
+			reply := append([]byte{0x01, 0x11, 0x00, 0x0A}, msg[4:8]...)
+			reply = append(reply, 0x90, 0x50, 0x00, 0x01, 0x05, 0x1D, 0x01, 0x23, 0x02, 0xFF)
+			_, _ = conn.WriteToUDP(reply, addr)
+		}
+	}()
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	cfg := discovery.Config{Port: port, Timeout: 100 * time.Millisecond}
+	devices, err := discovery.Scan(context.Background(), "127.0.0.1/32", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := discovery.Device{
+		Addr: netip.AddrPortFrom(netip.MustParseAddr("127.0.0.1"), uint16(port)),
+		Version: voip.Version{
+			VendorID:   0x0001,
+			ModelID:    0x051D,
+			ROMVersion: 0x0123,
+			MaxSocket:  0x02,
+		},
+	}
+	if len(devices) != 1 || devices[0] != want {
+		t.Errorf("Scan() = %+v, want [%+v]", devices, want)
+	}
+}
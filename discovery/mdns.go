@@ -0,0 +1,334 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MDNSGroup is the IPv4 multicast group of mDNS
+	MDNSGroup = "224.0.0.251"
+	// MDNSPort is the mDNS port
+	MDNSPort = 5353
+
+	dnsTypeA    = 1
+	dnsTypePTR  = 12
+	dnsTypeTXT  = 16
+	dnsTypeAAAA = 28
+	dnsTypeSRV  = 33
+	dnsClassIN  = 1
+)
+
+// DefaultServices are service types commonly advertised by PTZ cameras.
+// NDI cameras (PTZOptics, BirdDog, ...) usually also accept VISCA over IP.
+var DefaultServices = []string{"_ptz._tcp", "_visca._udp", "_ndi._tcp"}
+
+// MDNSService is a service instance found by BrowseMDNS.
+type MDNSService struct {
+	// Instance is the full instance name, e.g. "CAM1._ndi._tcp.local."
+	Instance string
+	// Host is the target host name of the instance.
+	Host string
+	// Port is the port advertised for the service, which is not
+	// necessarily the VISCA port.
+	Port  uint16
+	Addrs []netip.Addr
+	Text  []string
+}
+
+// VISCA returns the candidate VISCA over IP endpoint of the service, on
+// DefaultPort of its first address.
+func (s MDNSService) VISCA() (netip.AddrPort, bool) {
+	if len(s.Addrs) == 0 {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(s.Addrs[0], DefaultPort), true
+}
+
+// BrowseMDNS sends an mDNS query for the given service types (such as
+// "_ptz._tcp") to group, usually MDNSGroup, and returns the instances that
+// were resolved to an address, sorted by instance name. The query is sent
+// from an ephemeral port, so responders answer it directly (legacy unicast)
+// rather than to the multicast group. cfg.Port defaults to MDNSPort.
+func BrowseMDNS(ctx context.Context, group string, services []string, cfg Config) ([]MDNSService, error) {
+	if cfg.Port == 0 {
+		cfg.Port = MDNSPort
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if len(services) == 0 {
+		services = DefaultServices
+	}
+
+	ip, err := netip.ParseAddr(group)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mDNS group address: %w", err)
+	}
+
+	names := make([]string, len(services))
+	for i, s := range services {
+		names[i] = strings.TrimSuffix(s, ".") + ".local."
+	}
+	query, err := makeDNSQuery(names)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open socket: %w", err)
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	addr := net.UDPAddrFromAddrPort(netip.AddrPortFrom(ip, uint16(cfg.Port)))
+	_, err = conn.WriteToUDP(query, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %w", err)
+	}
+
+	err = conn.SetReadDeadline(time.Now().Add(cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	var records []dnsRecord
+	buf := make([]byte, 9000)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			return nil, err
+		}
+		rrs, err := parseDNSMessage(buf[:n])
+		if err != nil {
+			continue // Not a DNS message we understand
+		}
+		records = append(records, rrs...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return resolveServices(names, records), nil
+}
+
+// resolveServices follows PTR -> SRV/TXT -> A/AAAA across all records.
+func resolveServices(names []string, records []dnsRecord) []MDNSService {
+	wanted := make(map[string]bool)
+	for _, n := range names {
+		wanted[strings.ToLower(n)] = true
+	}
+
+	instances := make(map[string]*MDNSService)
+	addrs := make(map[string][]netip.Addr)
+	for _, rr := range records {
+		switch rr.typ {
+		case dnsTypePTR:
+			if wanted[strings.ToLower(rr.name)] {
+				key := strings.ToLower(rr.target)
+				if instances[key] == nil {
+					instances[key] = &MDNSService{Instance: rr.target}
+				}
+			}
+		case dnsTypeA, dnsTypeAAAA:
+			key := strings.ToLower(rr.name)
+			if !slices.Contains(addrs[key], rr.addr) {
+				addrs[key] = append(addrs[key], rr.addr)
+			}
+		}
+	}
+	for _, rr := range records {
+		s := instances[strings.ToLower(rr.name)]
+		if s == nil {
+			continue
+		}
+		switch rr.typ {
+		case dnsTypeSRV:
+			s.Host, s.Port = rr.target, rr.port
+		case dnsTypeTXT:
+			s.Text = rr.text
+		}
+	}
+
+	var services []MDNSService
+	for _, s := range instances {
+		s.Addrs = addrs[strings.ToLower(s.Host)]
+		if len(s.Addrs) == 0 {
+			continue
+		}
+		services = append(services, *s)
+	}
+	slices.SortFunc(services, func(a, b MDNSService) int {
+		return strings.Compare(a.Instance, b.Instance)
+	})
+	return services
+}
+
+// dnsRecord is the subset of a resource record needed for service discovery.
+type dnsRecord struct {
+	name   string
+	typ    uint16
+	target string // PTR and SRV
+	port   uint16 // SRV
+	addr   netip.Addr
+	text   []string
+}
+
+func makeDNSQuery(names []string) ([]byte, error) {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[4:6], uint16(len(names))) // QDCOUNT
+	for _, name := range names {
+		var err error
+		msg, err = appendDNSName(msg, name)
+		if err != nil {
+			return nil, err
+		}
+		msg = binary.BigEndian.AppendUint16(msg, dnsTypePTR)
+		msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+	}
+	return msg, nil
+}
+
+func appendDNSName(msg []byte, name string) ([]byte, error) {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, fmt.Errorf("invalid DNS name: %q", name)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0), nil
+}
+
+var errDNSFormat = errors.New("malformed DNS message")
+
+// parseDNSMessage returns the answer, authority and additional records of a
+// DNS response.
+func parseDNSMessage(msg []byte) ([]dnsRecord, error) {
+	if len(msg) < 12 || msg[2]&0x80 == 0 { // QR bit: response
+		return nil, errDNSFormat
+	}
+	qdCount := int(binary.BigEndian.Uint16(msg[4:6]))
+	rrCount := int(binary.BigEndian.Uint16(msg[6:8])) +
+		int(binary.BigEndian.Uint16(msg[8:10])) +
+		int(binary.BigEndian.Uint16(msg[10:12]))
+
+	off := 12
+	for range qdCount {
+		_, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4 // QTYPE, QCLASS
+	}
+
+	var records []dnsRecord
+	for range rrCount {
+		name, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next
+		if off+10 > len(msg) {
+			return nil, errDNSFormat
+		}
+		rr := dnsRecord{name: name, typ: binary.BigEndian.Uint16(msg[off : off+2])}
+		rdLength := int(binary.BigEndian.Uint16(msg[off+8 : off+10]))
+		off += 10
+		if off+rdLength > len(msg) {
+			return nil, errDNSFormat
+		}
+		rdata := msg[off : off+rdLength]
+
+		switch rr.typ {
+		case dnsTypePTR:
+			rr.target, _, err = readDNSName(msg, off)
+		case dnsTypeSRV:
+			if rdLength < 7 {
+				return nil, errDNSFormat
+			}
+			rr.port = binary.BigEndian.Uint16(rdata[4:6])
+			rr.target, _, err = readDNSName(msg, off+6)
+		case dnsTypeA:
+			if rdLength != 4 {
+				return nil, errDNSFormat
+			}
+			rr.addr = netip.AddrFrom4([4]byte(rdata))
+		case dnsTypeAAAA:
+			if rdLength != 16 {
+				return nil, errDNSFormat
+			}
+			rr.addr = netip.AddrFrom16([16]byte(rdata))
+		case dnsTypeTXT:
+			for i := 0; i < len(rdata); {
+				l := int(rdata[i])
+				if i+1+l > len(rdata) {
+					return nil, errDNSFormat
+				}
+				rr.text = append(rr.text, string(rdata[i+1:i+1+l]))
+				i += 1 + l
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rr)
+		off += rdLength
+	}
+	return records, nil
+}
+
+// readDNSName reads a possibly compressed name at off, and returns it along
+// with the offset right after it.
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errDNSFormat
+		}
+		l := int(msg[off])
+		switch {
+		case l == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case l&0xC0 == 0xC0: // Compression pointer
+			if off+1 >= len(msg) {
+				return "", 0, errDNSFormat
+			}
+			if jumps++; jumps > 32 {
+				return "", 0, errDNSFormat
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:off+2]) & 0x3FFF)
+		case l > 63:
+			return "", 0, errDNSFormat
+		default:
+			if off+1+l > len(msg) {
+				return "", 0, errDNSFormat
+			}
+			labels = append(labels, string(msg[off+1:off+1+l]))
+			off += 1 + l
+		}
+	}
+}
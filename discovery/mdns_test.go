@@ -0,0 +1,97 @@
+package discovery_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quangd42/visca-over-ip/discovery"
+)
+
+func appendName(msg []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0)
+}
+
+func appendRR(msg []byte, name []byte, typ uint16, rdata []byte) []byte {
+	msg = append(msg, name...)
+	msg = binary.BigEndian.AppendUint16(msg, typ)
+	msg = binary.BigEndian.AppendUint16(msg, 1)   // Class IN
+	msg = binary.BigEndian.AppendUint32(msg, 120) // TTL
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(rdata)))
+	return append(msg, rdata...)
+}
+
+// mdnsResponse answers a single question query for _ptz._tcp with a complete
+// PTR/SRV/TXT/A set, using compression pointers like real responders do.
+func mdnsResponse(query []byte) []byte {
+	msg := []byte{0, 0, 0x84, 0, 0, 1, 0, 4, 0, 0, 0, 0}
+	// Echo the question, whose name the answers point to
+	serviceOff := len(msg)
+	msg = append(msg, query[12:]...)
+	servicePtr := []byte{0xC0, byte(serviceOff)}
+
+	// PTR: CAM1 + pointer to service name
+	instance := append([]byte{4, 'C', 'A', 'M', '1'}, servicePtr...)
+	instanceOff := len(msg) + 2 + 10 // Owner pointer + fixed RR fields
+	msg = appendRR(msg, servicePtr, 12, instance)
+	instancePtr := []byte{0xC0, byte(instanceOff)}
+
+	srv := []byte{0, 0, 0, 0, 0x00, 0x50}
+	srv = appendName(srv, "cam1.local.")
+	msg = appendRR(msg, instancePtr, 33, srv)
+	msg = appendRR(msg, instancePtr, 16, []byte("\x09model=PTZ"))
+	msg = appendRR(msg, appendName(nil, "cam1.local."), 1, []byte{127, 0, 0, 1})
+	return msg
+}
+
+func TestBrowseMDNS(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteToUDP([]byte("garbage"), addr)
+			_, _ = conn.WriteToUDP(mdnsResponse(buf[:n]), addr)
+		}
+	}()
+
+	cfg := discovery.Config{
+		Port:    conn.LocalAddr().(*net.UDPAddr).Port,
+		Timeout: 100 * time.Millisecond,
+	}
+	services, err := discovery.BrowseMDNS(context.Background(), "127.0.0.1", []string{"_ptz._tcp"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("BrowseMDNS() = %+v, want one service", services)
+	}
+
+	s := services[0]
+	if s.Instance != "CAM1._ptz._tcp.local." || s.Host != "cam1.local." || s.Port != 80 ||
+		!slices.Equal(s.Text, []string{"model=PTZ"}) {
+		t.Errorf("BrowseMDNS() = %+v", s)
+	}
+	visca, ok := s.VISCA()
+	want := netip.MustParseAddrPort("127.0.0.1:52381")
+	if !ok || visca != want {
+		t.Errorf("VISCA() = %v, %v, want %v, true", visca, ok, want)
+	}
+}
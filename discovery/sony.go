@@ -0,0 +1,152 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SonyPort is the port of the Sony camera search/setting protocol
+	SonyPort = 52380
+
+	// LimitedBroadcast reaches every host of the local network
+	LimitedBroadcast = "255.255.255.255"
+
+	stx = 0x02
+	etx = 0x03
+	sep = 0xFF
+)
+
+// SonyDevice is a Sony camera that answered a search. The fields are taken
+// from the reply as reported by the camera.
+type SonyDevice struct {
+	MAC         string
+	Model       string
+	SoftVersion string
+	IP          netip.Addr
+	Mask        string
+	Gateway     string
+	Name        string
+	// Fields holds every KEY:VALUE field of the reply, including the above.
+	Fields map[string]string
+}
+
+// SonySearch broadcasts a search request of the Sony camera search/setting
+// protocol to broadcast (usually LimitedBroadcast or the broadcast address
+// of a subnet) and returns the cameras that answered, sorted by IP.
+// cfg.Port defaults to SonyPort.
+func SonySearch(ctx context.Context, broadcast string, cfg Config) ([]SonyDevice, error) {
+	if cfg.Port == 0 {
+		cfg.Port = SonyPort
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	ip, err := netip.ParseAddr(broadcast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broadcast address: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open socket: %w", err)
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	addr := net.UDPAddrFromAddrPort(netip.AddrPortFrom(ip, uint16(cfg.Port)))
+	_, err = conn.WriteToUDP(sonySearchRequest(), addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send search request: %w", err)
+	}
+
+	err = conn.SetReadDeadline(time.Now().Add(cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	found := make(map[string]SonyDevice)
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			return nil, err
+		}
+		device, ok := ParseSonyReply(buf[:n])
+		if !ok {
+			continue
+		}
+		// Several interfaces may answer for the same camera
+		found[device.MAC] = device
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	devices := make([]SonyDevice, 0, len(found))
+	for _, d := range found {
+		devices = append(devices, d)
+	}
+	slices.SortFunc(devices, func(a, b SonyDevice) int {
+		return a.IP.Compare(b.IP)
+	})
+	return devices, nil
+}
+
+func sonySearchRequest() []byte {
+	req := []byte{stx}
+	req = append(req, "ENQ:network"...)
+	return append(req, sep, etx)
+}
+
+// ParseSonyReply decodes a reply of the Sony camera search/setting protocol:
+// STX, then KEY:VALUE fields each followed by FF, then ETX. It reports false
+// for anything else, including the search requests of other controllers.
+func ParseSonyReply(msg []byte) (SonyDevice, bool) {
+	if len(msg) < 3 || msg[0] != stx || msg[len(msg)-1] != etx {
+		return SonyDevice{}, false
+	}
+
+	fields := make(map[string]string)
+	for _, field := range bytes.Split(msg[1:len(msg)-1], []byte{sep}) {
+		key, value, ok := strings.Cut(string(field), ":")
+		if ok {
+			fields[key] = value
+		}
+	}
+	if _, ok := fields["ENQ"]; ok {
+		return SonyDevice{}, false
+	}
+	if fields["MAC"] == "" {
+		return SonyDevice{}, false
+	}
+
+	device := SonyDevice{
+		MAC:         fields["MAC"],
+		Model:       fields["MODEL"],
+		SoftVersion: fields["SOFTVERSION"],
+		Mask:        fields["MASK"],
+		Gateway:     fields["GATEWAY"],
+		Name:        fields["NAME"],
+		Fields:      fields,
+	}
+	// A camera with a broken network setting is still worth reporting
+	device.IP, _ = netip.ParseAddr(fields["IPADR"])
+	return device, true
+}
@@ -0,0 +1,91 @@
+package discovery_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/quangd42/visca-over-ip/discovery"
+)
+
+func sonyReply(fields ...string) []byte {
+	reply := []byte{0x02}
+	for _, f := range fields {
+		reply = append(reply, f...)
+		reply = append(reply, 0xFF)
+	}
+	return append(reply, 0x03)
+}
+
+func TestParseSonyReply(t *testing.T) {
+	reply := sonyReply(
+		"MAC:08-00-46-12-34-56",
+		"INFO:SRG-X120",
+		"MODEL:IPCARD",
+		"SOFTVERSION:2.10",
+		"IPADR:192.168.0.100",
+		"MASK:255.255.255.0",
+		"NAME:CAM1",
+		"WRITE:on",
+	)
+	device, ok := discovery.ParseSonyReply(reply)
+	if !ok {
+		t.Fatal("ParseSonyReply() = false, want true")
+	}
+	if device.MAC != "08-00-46-12-34-56" || device.Model != "IPCARD" ||
+		device.IP != netip.MustParseAddr("192.168.0.100") || device.Name != "CAM1" ||
+		device.Fields["INFO"] != "SRG-X120" {
+		t.Errorf("ParseSonyReply() = %+v", device)
+	}
+
+	invalid := [][]byte{
+		nil,
+		[]byte("garbage"),
+		sonyReply("ENQ:network"),
+		sonyReply("MODEL:IPCARD"),
+	}
+	for _, msg := range invalid {
+		if _, ok := discovery.ParseSonyReply(msg); ok {
+			t.Errorf("ParseSonyReply(%q) = true, want false", msg)
+		}
+	}
+}
+
+func TestSonySearch(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if !bytes.Contains(buf[:n], []byte("ENQ:network")) {
+				continue
+			}
+			reply := sonyReply("MAC:08-00-46-12-34-56", "MODEL:IPCARD", "IPADR:127.0.0.1")
+			_, _ = conn.WriteToUDP(reply, addr)
+			_, _ = conn.WriteToUDP(reply, addr) // Duplicates are merged
+		}
+	}()
+
+	cfg := discovery.Config{
+		Port:    conn.LocalAddr().(*net.UDPAddr).Port,
+		Timeout: 100 * time.Millisecond,
+	}
+	devices, err := discovery.SonySearch(context.Background(), "127.0.0.1", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].MAC != "08-00-46-12-34-56" {
+		t.Errorf("SonySearch() = %+v, want one device", devices)
+	}
+}
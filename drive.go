@@ -0,0 +1,103 @@
+package viscaoverip
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Velocity is a drive input, such as the deflection of a joystick: pan,
+// tilt and zoom from -1 to 1, right, up and tele being positive.
+type Velocity struct {
+	Pan, Tilt, Zoom float64
+}
+
+// Drive drives pan, tilt and zoom continuously at a fraction of their
+// maximum speeds. Inputs beyond -1 and 1 are clamped.
+func (c *Camera) Drive(v Velocity) error {
+	pan := driveSpeed(v.Pan, MaxPanSpeed)
+	tilt := driveSpeed(v.Tilt, MaxTiltSpeed)
+	zoom := driveSpeed(v.Zoom, MaxZoomSpeed)
+	return errors.Join(c.PanTilt(pan, tilt), c.Zoom(zoom))
+}
+
+func driveSpeed(v float64, maxSpeed int) int {
+	return int(math.Round(clamp(v) * float64(maxSpeed)))
+}
+
+func clamp(v float64) float64 {
+	return max(-1, min(1, v))
+}
+
+type DriveFilterConfig struct {
+	// DeadZone is the input magnitude under which an axis stays still, so
+	// that a joystick at rest does not creep. Larger inputs are rescaled to
+	// start from zero at the edge of the dead zone.
+	DeadZone float64
+	// Expo curves the response, from 0 (linear) to 1 (cubic), for fine
+	// control around the center while keeping full speed at full
+	// deflection.
+	Expo float64
+	// Smoothing is the time constant of a low-pass filter on the inputs,
+	// so that jerky inputs become gradual speed changes.
+	// Zero disables smoothing.
+	Smoothing time.Duration
+}
+
+// DriveFilter conditions the Velocity inputs of cheap joysticks and gamepads
+// before they are passed to Drive: dead zone, then expo curve, then
+// smoothing. Smoothing only progresses when Filter is called, so inputs
+// should be filtered at a regular rate rather than only on change.
+//
+// A DriveFilter is not safe for concurrent use.
+type DriveFilter struct {
+	cfg  DriveFilterConfig
+	last Velocity  // Last output
+	at   time.Time // Time of the last output, zero after Reset
+}
+
+func NewDriveFilter(cfg DriveFilterConfig) *DriveFilter {
+	return &DriveFilter{cfg: cfg}
+}
+
+// Filter returns the conditioned velocity of an input.
+func (f *DriveFilter) Filter(v Velocity) Velocity {
+	return f.filter(v, time.Now())
+}
+
+func (f *DriveFilter) filter(v Velocity, now time.Time) Velocity {
+	v = Velocity{Pan: f.shape(v.Pan), Tilt: f.shape(v.Tilt), Zoom: f.shape(v.Zoom)}
+	if f.cfg.Smoothing > 0 {
+		// Smoothing starts from rest
+		var dt time.Duration
+		if !f.at.IsZero() {
+			dt = now.Sub(f.at)
+		}
+		alpha := 1 - math.Exp(-float64(dt)/float64(f.cfg.Smoothing))
+		v = Velocity{
+			Pan:  f.last.Pan + alpha*(v.Pan-f.last.Pan),
+			Tilt: f.last.Tilt + alpha*(v.Tilt-f.last.Tilt),
+			Zoom: f.last.Zoom + alpha*(v.Zoom-f.last.Zoom),
+		}
+	}
+	f.last, f.at = v, now
+	return v
+}
+
+// shape applies the dead zone and the expo curve to the input of an axis.
+func (f *DriveFilter) shape(x float64) float64 {
+	x = clamp(x)
+	mag := math.Abs(x)
+	if mag <= f.cfg.DeadZone {
+		return 0
+	}
+	mag = (mag - f.cfg.DeadZone) / (1 - f.cfg.DeadZone)
+	mag = (1-f.cfg.Expo)*mag + f.cfg.Expo*mag*mag*mag
+	return math.Copysign(mag, x)
+}
+
+// Reset returns the filter to rest, e.g. after the camera was stopped by
+// other means.
+func (f *DriveFilter) Reset() {
+	f.last, f.at = Velocity{}, time.Time{}
+}
@@ -0,0 +1,69 @@
+package viscaoverip_test
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestDriveFilterShape(t *testing.T) {
+	f := voip.NewDriveFilter(voip.DriveFilterConfig{DeadZone: 0.1, Expo: 0.5})
+	tests := []struct {
+		in, want float64
+	}{
+		{0.05, 0},
+		{-0.1, 0},
+		{0.55, 0.5*0.5 + 0.5*0.125},
+		{-1, -1},
+		{2, 1},
+	}
+	for _, tc := range tests {
+		got := f.Filter(voip.Velocity{Pan: tc.in}).Pan
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Filter(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDriveFilterSmoothing(t *testing.T) {
+	f := voip.NewDriveFilter(voip.DriveFilterConfig{Smoothing: 100 * time.Millisecond})
+	start := time.Now()
+	in := voip.Velocity{Pan: 1, Zoom: -1}
+
+	if v := f.FilterAt(in, start); v != (voip.Velocity{}) {
+		t.Errorf("first output = %+v, want rest", v)
+	}
+	v := f.FilterAt(in, start.Add(100*time.Millisecond))
+	if want := 1 - math.Exp(-1); math.Abs(v.Pan-want) > 1e-9 || math.Abs(v.Zoom+want) > 1e-9 {
+		t.Errorf("output after one time constant = %+v, want pan %v", v, want)
+	}
+	v = f.FilterAt(in, start.Add(time.Second))
+	if math.Abs(v.Pan-1) > 1e-3 {
+		t.Errorf("output after ten time constants = %+v, want pan 1", v)
+	}
+
+	f.Reset()
+	if v := f.FilterAt(in, start.Add(2*time.Second)); v != (voip.Velocity{}) {
+		t.Errorf("output after Reset = %+v, want rest", v)
+	}
+}
+
+func TestDrive(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	if err := camera.Drive(voip.Velocity{Pan: -0.5, Tilt: 1, Zoom: 2}); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()[1:]
+	want := []string{"810106010c140101ff", "8101040726ff"}
+	if len(requests) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(requests), len(want))
+	}
+	for i := range want {
+		if got := hex.EncodeToString(requests[i]); got != want[i] {
+			t.Errorf("request %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
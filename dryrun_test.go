@@ -0,0 +1,63 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestDryRun(t *testing.T) {
+	// Nothing listens there, and the name does not even resolve
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond, DryRun: true}
+	camera, err := voip.Dial(context.Background(), "camera.invalid:52381", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	events, unsubscribe := camera.DebugEvents(16)
+	defer unsubscribe()
+	if err := camera.Home(); err != nil {
+		t.Errorf("Home() = %v", err)
+	}
+	if err := camera.SendCommand("06 0G"); err == nil {
+		t.Error("invalid command succeeded")
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	e, ok := (<-events).(voip.SendEvent)
+	if want := []byte{0x81, 0x01, 0x06, 0x04, 0xFF}; !ok || string(e.Message[voip.HeaderSize:]) != string(want) {
+		t.Errorf("event = %v, want SendEvent of % X", e, want)
+	}
+	if m := camera.Metrics(); m.Retries != 0 || m.MissedResponses != 0 {
+		t.Errorf("metrics = %+v, want no retry", m)
+	}
+}
+
+func TestDryRunReplies(t *testing.T) {
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond, DryRun: true, DryRunReplies: true}
+	camera, err := voip.Dial(context.Background(), "camera.invalid:52381", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	completion, err := camera.SendCommandAsync("06 04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := completion.Wait(ctx); err != nil {
+		t.Errorf("Wait() = %v", err)
+	}
+	if data, err := camera.SendInquiry("04 00"); err != nil || len(data) != 0 {
+		t.Errorf("SendInquiry() = % X, %v, want no data", data, err)
+	}
+	if m := camera.Metrics(); m.Retries != 0 || m.Errors != 0 {
+		t.Errorf("metrics = %+v, want no retry nor error", m)
+	}
+}
@@ -0,0 +1,85 @@
+package viscaoverip
+
+import "fmt"
+
+// OpticalZoomMax is the zoom position at the tele end of the optical zoom.
+// In combined mode, positions beyond it are digital zoom.
+const OpticalZoomMax = 0x4000
+
+// MaxDigitalZoom is the largest digital zoom position in separate mode.
+const MaxDigitalZoom = 0xEB
+
+// SetDigitalZoom turns digital zoom on or off (CAM_DZoom On/Off).
+func (c *Camera) SetDigitalZoom(on bool) error {
+	return c.setOnOff("04 06", on)
+}
+
+// DigitalZoom reports whether digital zoom is on.
+func (c *Camera) DigitalZoom() (bool, error) {
+	return c.inquireOnOff("04 06")
+}
+
+// SetDigitalZoomSeparate selects the separate digital zoom mode, in which
+// digital zoom is set apart from optical zoom with SetDigitalZoomPosition,
+// or the combined mode, in which zoom commands go on with digital zoom past
+// OpticalZoomMax.
+func (c *Camera) SetDigitalZoomSeparate(separate bool) error {
+	if separate {
+		return c.SendCommand("04 36 01")
+	}
+	return c.SendCommand("04 36 00")
+}
+
+// DigitalZoomSeparate reports whether digital zoom is in separate mode.
+func (c *Camera) DigitalZoomSeparate() (bool, error) {
+	data, err := c.SendInquiry("04 36")
+	if err != nil {
+		return false, err
+	}
+	if len(data) != 1 || data[0] > 0x01 {
+		return false, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return data[0] == 0x01, nil
+}
+
+// SetDigitalZoomPosition sets the digital zoom position in separate mode,
+// from 0 (x1) to MaxDigitalZoom (CAM_DZoom Direct).
+func (c *Camera) SetDigitalZoomPosition(zoom int) error {
+	if err := checkRange("digital zoom", zoom, 0, MaxDigitalZoom); err != nil {
+		return err
+	}
+	return c.setDirect(0x46, zoom)
+}
+
+// ZoomPositions are the optical and digital parts of the zoom position.
+type ZoomPositions struct {
+	// Optical is from 0 (wide) to OpticalZoomMax.
+	Optical int
+	// Digital is the digital zoom position: the position past
+	// OpticalZoomMax in combined mode, or from 0 to MaxDigitalZoom in
+	// separate mode.
+	Digital int
+}
+
+// ZoomPositions inquires the optical and digital zoom positions.
+func (c *Camera) ZoomPositions() (ZoomPositions, error) {
+	separate, err := c.DigitalZoomSeparate()
+	if err != nil {
+		return ZoomPositions{}, err
+	}
+	zoom, err := c.ZoomPosition()
+	if err != nil {
+		return ZoomPositions{}, err
+	}
+	if !separate {
+		if zoom <= OpticalZoomMax {
+			return ZoomPositions{Optical: zoom}, nil
+		}
+		return ZoomPositions{Optical: OpticalZoomMax, Digital: zoom - OpticalZoomMax}, nil
+	}
+	digital, err := c.inquireDirect(0x46)
+	if err != nil {
+		return ZoomPositions{}, err
+	}
+	return ZoomPositions{Optical: zoom, Digital: digital}, nil
+}
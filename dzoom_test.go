@@ -0,0 +1,40 @@
+package viscaoverip_test
+
+import (
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestDigitalZoom(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Zoom: 0x2000})
+
+	if err := camera.SetDigitalZoom(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.DigitalZoom(); err != nil || !on {
+		t.Errorf("DigitalZoom() = %v, %v, want true", on, err)
+	}
+
+	// Combined mode
+	if z, err := camera.ZoomPositions(); err != nil || z != (voip.ZoomPositions{Optical: 0x2000}) {
+		t.Errorf("ZoomPositions() = %+v, %v, want optical 0x2000", z, err)
+	}
+
+	// Separate mode
+	if err := camera.SetDigitalZoomSeparate(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.SetDigitalZoomPosition(0x40); err != nil {
+		t.Fatal(err)
+	}
+	if z, err := camera.ZoomPositions(); err != nil || z != (voip.ZoomPositions{Optical: 0x2000, Digital: 0x40}) {
+		t.Errorf("ZoomPositions() = %+v, %v, want optical 0x2000 and digital 0x40", z, err)
+	}
+	if err := camera.SetDigitalZoomPosition(voip.MaxDigitalZoom + 1); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetDigitalZoomPosition() out of range = %v, want ErrInvalidArgument", err)
+	}
+}
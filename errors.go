@@ -0,0 +1,139 @@
+package viscaoverip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors of the control replies a camera sends instead of a VISCA reply
+// when it rejects a message at the VISCA over IP layer.
+var (
+	// ErrAbnormalSequence is reported by a control reply 0F 01, when the
+	// sequence number of the message is not the one the camera expects.
+	ErrAbnormalSequence = errors.New("camera reported an abnormal sequence number")
+	// ErrAbnormalMessage is reported by a control reply 0F 02, when the
+	// message does not follow the protocol.
+	ErrAbnormalMessage = errors.New("camera reported an abnormal message")
+)
+
+// CommandError annotates the error of an exchange with the message sent, so
+// that a log tells which command to which camera failed.
+type CommandError struct {
+	// Message is the VISCA over IP message, header included.
+	Message  []byte
+	Address  string
+	SeqNum   uint32
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	kind := "command"
+	if len(e.Message) >= 2 && binary.BigEndian.Uint16(e.Message) == 0x0110 {
+		kind = "inquiry"
+	}
+	var payload []byte
+	if len(e.Message) > 8 {
+		payload = e.Message[8:]
+	}
+	return fmt.Sprintf("%s % X to %s (seq %d, attempt %d, %v): %v",
+		kind, payload, e.Address, e.SeqNum, e.Attempts, e.Elapsed.Round(time.Microsecond), e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// SequenceError is the error of a reply numbered after the message it
+// answers, which happens when the camera restarted its numbering or answers
+// another controller on the same socket.
+type SequenceError struct {
+	Expected uint32
+	Got      uint32
+}
+
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("reply sequence number %d ahead of expected %d", e.Got, e.Expected)
+}
+
+// The errors of an exchange fall in four categories, which tell the caller
+// what may help:
+//
+//   - TransportError: the socket failed; Reconnect may help.
+//   - TimeoutError: the camera did not answer; retrying later may help.
+//   - ProtocolError: the reply was malformed or out of sequence.
+//   - CameraError: the camera rejected the message; sending it again as is
+//     will not help.
+//
+// They are wrapped in a CommandError, and can be told apart with errors.As.
+
+// TransportError is the error of the network socket of the camera.
+type TransportError struct {
+	// Op is the socket operation that failed, e.g. "write" or "read".
+	Op  string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is the error of a message the camera did not answer after
+// every attempt. It matches ErrNotResponsive.
+type TimeoutError struct {
+	Attempts int
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v after %d attempts", ErrNotResponsive, e.Attempts)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrNotResponsive
+}
+
+// ProtocolError is the error of a reply that does not follow the protocol:
+// truncated, or numbered ahead of the message (SequenceError), or of a
+// control reply rejecting the message (ErrAbnormalSequence,
+// ErrAbnormalMessage).
+type ProtocolError struct {
+	Err error
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
+// CameraError is an error reply of the camera (y0 6z ... FF), such as a
+// syntax error or a command not executable in the current mode.
+type CameraError struct {
+	Payload []byte
+}
+
+// Code returns the error code of the reply, e.g. 02 for a syntax error or
+// 41 for a command not executable, or 0 if the reply has none.
+func (e *CameraError) Code() byte {
+	if len(e.Payload) < 4 {
+		return 0
+	}
+	return e.Payload[2]
+}
+
+func (e *CameraError) Error() string {
+	var statusCode byte
+	if len(e.Payload) > 1 {
+		statusCode = e.Payload[1] >> 4
+	}
+	return fmt.Sprintf("peripheral device error: payload=%x, statusCode=%x", e.Payload, statusCode)
+}
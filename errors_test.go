@@ -0,0 +1,187 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestCommandError(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	// Unknown inquiries are answered with a syntax error
+	_, err := camera.SendInquiry("7E 7E 7E")
+	var cmdErr *voip.CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("SendInquiry() = %v, want a CommandError", err)
+	}
+	if cmdErr.Attempts != 1 || cmdErr.Address != emulator.Addr() || cmdErr.SeqNum == 0 {
+		t.Errorf("CommandError = %+v", cmdErr)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "inquiry 81 09 7E 7E 7E FF to "+emulator.Addr()) {
+		t.Errorf("Error() = %q", msg)
+	}
+
+	// A peer that never answers
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	conn, err := net.DialUDP("udp", nil, silent.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera = voip.New(conn, voip.Config{MaxRetries: 2, Timeout: 10 * time.Millisecond})
+	defer camera.Close()
+
+	err = camera.SendCommand("06 04")
+	if !errors.Is(err, voip.ErrNotResponsive) || !errors.As(err, &cmdErr) {
+		t.Fatalf("SendCommand() = %v, want a CommandError wrapping ErrNotResponsive", err)
+	}
+	if cmdErr.Attempts != 2 || cmdErr.Elapsed < 20*time.Millisecond {
+		t.Errorf("CommandError = %+v, want 2 attempts of 10ms", cmdErr)
+	}
+	if !strings.HasPrefix(err.Error(), "command 81 01 06 04 FF") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestSequenceError(t *testing.T) {
+	for _, resync := range []bool{false, true} {
+		server, addr := newMockServer(t)
+		defer server.close()
+		var mu sync.Mutex
+		resets := 0
+		server.setHandler(func(msg []byte) [][]byte {
+			if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+				mu.Lock()
+				resets++
+				mu.Unlock()
+				return [][]byte{makeResetResponse()}
+			}
+			seqNum := binary.BigEndian.Uint32(msg[4:8])
+			if bytes.HasSuffix(msg, []byte{0x06, 0x04, 0xFF}) {
+				// Numbered as if the camera had counted other messages
+				seqNum += 5
+			}
+			return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+		})
+
+		udpAddr, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := net.DialUDP("udp", nil, udpAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond, ResyncOnSequenceError: resync}
+		camera, err := voip.NewCameraWithConfig(conn, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer camera.Close()
+
+		err = camera.SendCommand("06 04")
+		var seqErr *voip.SequenceError
+		var protoErr *voip.ProtocolError
+		if !errors.As(err, &seqErr) || !errors.As(err, &protoErr) {
+			t.Fatalf("SendCommand() = %v, want a ProtocolError wrapping a SequenceError", err)
+		}
+		if seqErr.Got != seqErr.Expected+5 {
+			t.Errorf("SequenceError = %+v", seqErr)
+		}
+
+		mu.Lock()
+		n := resets
+		mu.Unlock()
+		if want := map[bool]int{false: 1, true: 2}[resync]; n != want {
+			t.Errorf("ResyncOnSequenceError %v: %d resets, want %d", resync, n, want)
+		}
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	_, err := camera.SendInquiry("7E 7E 7E")
+	var camErr *voip.CameraError
+	if !errors.As(err, &camErr) || camErr.Code() != 0x02 {
+		t.Errorf("unknown inquiry: %v, want a CameraError with code 02", err)
+	}
+	emulator.SetState(viscatest.State{Power: false})
+	err = camera.Home()
+	if !errors.As(err, &camErr) || camErr.Code() != 0x41 {
+		t.Errorf("command while off: %v, want a CameraError with code 41", err)
+	}
+
+	// A peer that never answers
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	conn, err := net.DialUDP("udp", nil, silent.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera = voip.New(conn, voip.Config{MaxRetries: 2, Timeout: 10 * time.Millisecond})
+	err = camera.Home()
+	var timeoutErr *voip.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Attempts != 2 || !errors.Is(err, voip.ErrNotResponsive) {
+		t.Errorf("no reply: %v, want a TimeoutError of 2 attempts matching ErrNotResponsive", err)
+	}
+
+	conn.Close()
+	err = camera.Home()
+	var transportErr *voip.TransportError
+	if !errors.As(err, &transportErr) || !errors.Is(err, net.ErrClosed) {
+		t.Errorf("closed socket: %v, want a TransportError wrapping net.ErrClosed", err)
+	}
+}
+
+func TestMultiController(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		ack := viscatest.ScriptReply{Payload: "90 41 FF"}
+		completion := viscatest.ScriptReply{Payload: "90 51 FF"}
+		script, err := viscatest.NewScript(
+			viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, completion}},
+			viscatest.Step{Request: "81 01 06 04 FF", Replies: []viscatest.ScriptReply{
+				// Replies to the other controller, numbered on its own
+				{Payload: "90 41 FF", SeqDelta: 40},
+				{Payload: "90 51 FF", SeqDelta: 40},
+				ack, completion,
+			}},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer script.Close()
+
+		cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond, MultiController: shared}
+		camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer camera.Close()
+
+		err = camera.SendCommand("06 04")
+		var seqErr *voip.SequenceError
+		if shared && err != nil {
+			t.Errorf("MultiController: SendCommand() = %v, want replies to the other controller ignored", err)
+		}
+		if !shared && !errors.As(err, &seqErr) {
+			t.Errorf("SendCommand() = %v, want a SequenceError", err)
+		}
+	}
+}
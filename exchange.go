@@ -0,0 +1,106 @@
+package viscaoverip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Exchange is a message sent to the camera and the replies received for it,
+// for protocol debug panes.
+type Exchange struct {
+	// Sent is the VISCA over IP message, header included.
+	Sent []byte
+	// Replies are the replies to Sent in the order received, headers
+	// included.
+	Replies [][]byte
+	Time    time.Time
+	Elapsed time.Duration
+	Err     error
+}
+
+// LastExchange returns the most recent exchange with the camera. It does
+// not wait for an exchange in progress.
+func (c *Camera) LastExchange() Exchange {
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	return c.last
+}
+
+// recordExchange saves the current exchange as the last one. c.mu must be
+// held.
+func (c *Camera) recordExchange(message []byte, start time.Time, err error) {
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	c.last = Exchange{
+		Sent:    message,
+		Replies: c.replies,
+		Time:    start,
+		Elapsed: time.Since(start),
+		Err:     err,
+	}
+}
+
+// Summary decodes the exchange, e.g. "81 01 06 04 FF -> ACK, Completion".
+func (e Exchange) Summary() string {
+	if len(e.Sent) == 0 {
+		return "no exchange"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "% X ->", payloadOf(e.Sent))
+	for i, r := range e.Replies {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(' ')
+		b.WriteString(DescribeReply(payloadOf(r)))
+	}
+	if e.Err != nil && len(e.Replies) == 0 {
+		b.WriteString(" no reply")
+	}
+	return b.String()
+}
+
+func payloadOf(message []byte) []byte {
+	if len(message) < 8 || int(binary.BigEndian.Uint16(message[2:4])) > len(message)-8 {
+		return message
+	}
+	return message[8:]
+}
+
+var replyErrors = map[byte]string{
+	0x01: "message length error",
+	0x02: "syntax error",
+	0x03: "command buffer full",
+	0x04: "command canceled",
+	0x05: "no socket",
+	0x41: "command not executable",
+}
+
+// DescribeReply decodes a reply payload (y0 ... FF) in words, e.g. "ACK",
+// "Completion 02" or "Error: syntax error".
+func DescribeReply(payload []byte) string {
+	if len(payload) < 3 || payload[len(payload)-1] != 0xFF {
+		return fmt.Sprintf("malformed % X", payload)
+	}
+	data := payload[2 : len(payload)-1]
+	switch payload[1] >> 4 {
+	case StatusCodeACK:
+		return "ACK"
+	case StatusCodeCompletion:
+		if len(data) > 0 {
+			return fmt.Sprintf("Completion % X", data)
+		}
+		return "Completion"
+	case 6:
+		if len(data) > 0 {
+			if desc, ok := replyErrors[data[0]]; ok {
+				return "Error: " + desc
+			}
+		}
+		return fmt.Sprintf("Error % X", payload)
+	default:
+		return fmt.Sprintf("% X", payload)
+	}
+}
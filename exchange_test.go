@@ -0,0 +1,34 @@
+package viscaoverip_test
+
+import (
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestLastExchange(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+	e := camera.LastExchange()
+	if len(e.Replies) != 2 || e.Err != nil {
+		t.Errorf("LastExchange() = %+v, want ACK and Completion", e)
+	}
+	if got, want := e.Summary(), "81 01 06 04 FF -> ACK, Completion"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	if _, err := camera.SendInquiry(voip.PowerInquiry); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := camera.LastExchange().Summary(), "81 09 04 00 FF -> Completion 02"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	camera.SendInquiry("7E 7E")
+	if got, want := camera.LastExchange().Summary(), "81 09 7E 7E FF -> Error: syntax error"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+package viscaoverip
+
+import "time"
+
+var SeqCompare = seqCompare
+
+func (f *DriveFilter) FilterAt(v Velocity, now time.Time) Velocity {
+	return f.filter(v, now)
+}
+
+func (c *Camera) SetSeqNum(n uint32) {
+	c.seqNum.Store(n)
+}
@@ -0,0 +1,202 @@
+package viscaoverip
+
+import "fmt"
+
+// Exposure compensation levels, in steps of the camera (typically 1/3 or
+// 1/2 EV). The direct value register holds the level offset by 7: 0x00 is
+// -7, 0x07 is 0 and 0x0E is +7.
+const (
+	MinExposureCompensation = -7
+	MaxExposureCompensation = 7
+)
+
+// SetExposureCompensationOn turns exposure compensation on or off
+// (CAM_ExpComp On/Off).
+func (c *Camera) SetExposureCompensationOn(on bool) error {
+	return c.setOnOff("04 3E", on)
+}
+
+// ExposureCompensationOn reports whether exposure compensation is on.
+func (c *Camera) ExposureCompensationOn() (bool, error) {
+	return c.inquireOnOff("04 3E")
+}
+
+// ExposureCompensationUp raises the exposure compensation by one step.
+func (c *Camera) ExposureCompensationUp() error {
+	return c.SendCommand("04 0E 02")
+}
+
+// ExposureCompensationDown lowers the exposure compensation by one step.
+func (c *Camera) ExposureCompensationDown() error {
+	return c.SendCommand("04 0E 03")
+}
+
+// ExposureCompensationReset sets the exposure compensation back to 0.
+func (c *Camera) ExposureCompensationReset() error {
+	return c.SendCommand("04 0E 00")
+}
+
+// SetExposureCompensation sets the exposure compensation level, from
+// MinExposureCompensation to MaxExposureCompensation.
+func (c *Camera) SetExposureCompensation(level int) error {
+	if err := checkRange("exposure compensation", level, MinExposureCompensation, MaxExposureCompensation); err != nil {
+		return err
+	}
+	return c.setDirect(0x4E, level-MinExposureCompensation)
+}
+
+// ExposureCompensation inquires the exposure compensation level.
+func (c *Camera) ExposureCompensation() (int, error) {
+	v, err := c.inquireDirect(0x4E)
+	if err != nil {
+		return 0, err
+	}
+	return v + MinExposureCompensation, nil
+}
+
+// SetIris sets the iris step (CAM_Iris Direct), in manual and iris priority
+// exposure modes.
+func (c *Camera) SetIris(step int) error {
+	if err := checkRange("iris", step, 0, 0xFF); err != nil {
+		return err
+	}
+	return c.setDirect(0x4B, step)
+}
+
+// Iris inquires the iris step.
+func (c *Camera) Iris() (int, error) {
+	return c.inquireDirect(0x4B)
+}
+
+// SetGain sets the gain step (CAM_Gain Direct), in manual exposure mode.
+func (c *Camera) SetGain(step int) error {
+	if err := checkRange("gain", step, 0, 0xFF); err != nil {
+		return err
+	}
+	return c.setDirect(0x4C, step)
+}
+
+// Gain inquires the gain step.
+func (c *Camera) Gain() (int, error) {
+	return c.inquireDirect(0x4C)
+}
+
+// SetShutter sets the shutter step (CAM_Shutter Direct), in manual and
+// shutter priority exposure modes.
+func (c *Camera) SetShutter(step int) error {
+	if err := checkRange("shutter", step, 0, 0xFF); err != nil {
+		return err
+	}
+	return c.setDirect(0x4A, step)
+}
+
+// Shutter inquires the shutter step.
+func (c *Camera) Shutter() (int, error) {
+	return c.inquireDirect(0x4A)
+}
+
+// SetIrisFNumber sets the iris step closest to the f-number f, according to
+// the IrisTable of the vendor profile.
+func (c *Camera) SetIrisFNumber(f float64) error {
+	return c.setInUnits(c.Config.Vendor.IrisTable(), f, c.SetIris)
+}
+
+// IrisFNumber inquires the iris as an f-number.
+func (c *Camera) IrisFNumber() (float64, error) {
+	return c.inUnits(c.Config.Vendor.IrisTable(), c.Iris)
+}
+
+// SetGainDecibels sets the gain step closest to db, according to the
+// GainTable of the vendor profile.
+func (c *Camera) SetGainDecibels(db float64) error {
+	return c.setInUnits(c.Config.Vendor.GainTable(), db, c.SetGain)
+}
+
+// GainDecibels inquires the gain in decibels.
+func (c *Camera) GainDecibels() (float64, error) {
+	return c.inUnits(c.Config.Vendor.GainTable(), c.Gain)
+}
+
+// SetShutterSpeed sets the shutter step closest to the exposure time d, in
+// seconds, according to the ShutterTable of the vendor profile.
+func (c *Camera) SetShutterSpeed(d float64) error {
+	return c.setInUnits(c.Config.Vendor.ShutterTable(), d, c.SetShutter)
+}
+
+// ShutterSpeed inquires the exposure time of the shutter, in seconds.
+func (c *Camera) ShutterSpeed() (float64, error) {
+	return c.inUnits(c.Config.Vendor.ShutterTable(), c.Shutter)
+}
+
+func (c *Camera) setInUnits(t UnitTable, v float64, set func(int) error) error {
+	step, ok := t.Step(v)
+	if !ok {
+		return fmt.Errorf("%w: no unit table for %s", ErrUnsupported, c.Config.Vendor)
+	}
+	return set(step)
+}
+
+func (c *Camera) inUnits(t UnitTable, get func() (int, error)) (float64, error) {
+	if t == nil {
+		return 0, fmt.Errorf("%w: no unit table for %s", ErrUnsupported, c.Config.Vendor)
+	}
+	step, err := get()
+	if err != nil {
+		return 0, err
+	}
+	v, ok := t.Value(step)
+	if !ok {
+		return 0, fmt.Errorf("step %#x not in the unit table of %s", step, c.Config.Vendor)
+	}
+	return v, nil
+}
+
+// MaxSpotPosition is the largest coordinate of the spot AE area, on a grid
+// from 0 (left, top) to MaxSpotPosition (right, bottom).
+const MaxSpotPosition = 0x0F
+
+// SetBacklight turns backlight compensation on or off (CAM_Backlight).
+func (c *Camera) SetBacklight(on bool) error {
+	return c.setOnOff("04 33", on)
+}
+
+// Backlight reports whether backlight compensation is on.
+func (c *Camera) Backlight() (bool, error) {
+	return c.inquireOnOff("04 33")
+}
+
+// SetSpotAE turns spot automatic exposure on or off (CAM_SpotAE), which
+// exposes for the area at the spot position, e.g. a lit speaker on a dark
+// stage.
+func (c *Camera) SetSpotAE(on bool) error {
+	return c.setOnOff("04 59", on)
+}
+
+// SpotAE reports whether spot automatic exposure is on.
+func (c *Camera) SpotAE() (bool, error) {
+	return c.inquireOnOff("04 59")
+}
+
+// SetSpotAEPosition sets the spot AE area, from 0 to MaxSpotPosition on
+// each axis.
+func (c *Camera) SetSpotAEPosition(x, y int) error {
+	if err := checkRange("spot x", x, 0, MaxSpotPosition); err != nil {
+		return err
+	}
+	if err := checkRange("spot y", y, 0, MaxSpotPosition); err != nil {
+		return err
+	}
+	return c.SendCommand(fmt.Sprintf("04 29 %s %s", encodeNibbles(x, 2), encodeNibbles(y, 2)))
+}
+
+// SpotAEPosition inquires the spot AE area.
+func (c *Camera) SpotAEPosition() (x, y int, err error) {
+	data, err := c.SendInquiry("04 29")
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(data) != 4 {
+		return 0, 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return decodeNibbles(data[:2], false), decodeNibbles(data[2:], false), nil
+}
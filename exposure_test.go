@@ -0,0 +1,132 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestExposureCompensation(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	if err := camera.SetExposureCompensationOn(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.ExposureCompensationOn(); err != nil || !on {
+		t.Errorf("ExposureCompensationOn() = %v, %v, want true", on, err)
+	}
+
+	if err := camera.SetExposureCompensation(-3); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x04, 0x4E, 0x00, 0x00, 0x00, 0x04, 0xFF}) {
+		t.Errorf("SetExposureCompensation(-3) sent % X", last)
+	}
+	if level, err := camera.ExposureCompensation(); err != nil || level != -3 {
+		t.Errorf("ExposureCompensation() = %d, %v, want -3", level, err)
+	}
+
+	if err := camera.ExposureCompensationUp(); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := camera.ExposureCompensation(); err != nil || level != -2 {
+		t.Errorf("ExposureCompensation() after Up = %d, %v, want -2", level, err)
+	}
+	if err := camera.ExposureCompensationReset(); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := camera.ExposureCompensation(); err != nil || level != 0 {
+		t.Errorf("ExposureCompensation() after Reset = %d, %v, want 0", level, err)
+	}
+
+	if err := camera.SetExposureCompensation(8); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetExposureCompensation(8) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestExposureUnits(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.SetShutterSpeed(1.0 / 250); !errors.Is(err, voip.ErrUnsupported) {
+		t.Errorf("SetShutterSpeed() with the generic profile = %v, want ErrUnsupported", err)
+	}
+
+	camera.Config.Vendor = voip.VendorSony
+	if err := camera.SetIrisFNumber(5.6); err != nil {
+		t.Fatal(err)
+	}
+	if step, err := camera.Iris(); err != nil || step != 0x0A {
+		t.Errorf("Iris() = %#x, %v, want 0x0A", step, err)
+	}
+	if f, err := camera.IrisFNumber(); err != nil || f != 5.6 {
+		t.Errorf("IrisFNumber() = %v, %v, want 5.6", f, err)
+	}
+
+	// The closest step is chosen
+	if err := camera.SetGainDecibels(10); err != nil {
+		t.Fatal(err)
+	}
+	if db, err := camera.GainDecibels(); err != nil || db != 9 {
+		t.Errorf("GainDecibels() = %v, %v, want 9", db, err)
+	}
+
+	if err := camera.SetShutterSpeed(1.0 / 240); err != nil {
+		t.Fatal(err)
+	}
+	if step, err := camera.Shutter(); err != nil || step != 0x0B {
+		t.Errorf("Shutter() = %#x, %v, want 0x0B (1/250)", step, err)
+	}
+}
+
+func TestUnitTable(t *testing.T) {
+	for _, v := range []voip.Vendor{voip.VendorSony, voip.VendorPTZOptics} {
+		for name, table := range map[string]voip.UnitTable{
+			"iris":    v.IrisTable(),
+			"gain":    v.GainTable(),
+			"shutter": v.ShutterTable(),
+		} {
+			for step, value := range table {
+				if got, ok := table.Step(value); !ok || got != step {
+					t.Errorf("%s %s: Step(%v) = %#x, want %#x", v, name, value, got, step)
+				}
+			}
+		}
+	}
+	if _, ok := voip.VendorGeneric.IrisTable().Step(2.8); ok {
+		t.Error("Step() of the generic profile: want false")
+	}
+}
+
+func TestBacklightAndSpotAE(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	if err := camera.SetBacklight(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.Backlight(); err != nil || !on {
+		t.Errorf("Backlight() = %v, %v, want true", on, err)
+	}
+	if err := camera.SetSpotAE(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.SpotAE(); err != nil || !on {
+		t.Errorf("SpotAE() = %v, %v, want true", on, err)
+	}
+
+	if err := camera.SetSpotAEPosition(3, 12); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x04, 0x29, 0x00, 0x03, 0x00, 0x0C, 0xFF}) {
+		t.Errorf("SetSpotAEPosition(3, 12) sent % X", last)
+	}
+	if x, y, err := camera.SpotAEPosition(); err != nil || x != 3 || y != 12 {
+		t.Errorf("SpotAEPosition() = %d, %d, %v, want 3, 12", x, y, err)
+	}
+	if err := camera.SetSpotAEPosition(16, 0); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetSpotAEPosition(16, 0) = %v, want ErrInvalidArgument", err)
+	}
+}
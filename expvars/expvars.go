@@ -0,0 +1,28 @@
+// Package expvars publishes the Metrics of the cameras of a Manager with
+// the expvar package, so that services get camera telemetry on /debug/vars
+// without extra dependencies.
+//
+// Importing this package registers the expvar handler on
+// http.DefaultServeMux, like importing expvar does.
+package expvars
+
+import (
+	"expvar"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// Publish publishes the variable prefix, a map from camera name to its
+// Metrics, evaluated on every read so that cameras added to m later are
+// included. Like expvar.Publish, it panics if prefix is already in use.
+func Publish(prefix string, m *voip.Manager) {
+	expvar.Publish(prefix, expvar.Func(func() any {
+		metrics := make(map[string]voip.Metrics)
+		for _, name := range m.Names() {
+			if c, ok := m.Get(name); ok {
+				metrics[name] = c.Metrics()
+			}
+		}
+		return metrics
+	}))
+}
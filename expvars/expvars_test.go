@@ -0,0 +1,42 @@
+package expvars_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/expvars"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestPublish(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+
+	expvars.Publish("cameras", m)
+	m.Add("cam1", camera)
+	camera.Home()
+	camera.SendInquiry("7E 7E") // Syntax error
+
+	var got map[string]voip.Metrics
+	if err := json.Unmarshal([]byte(expvar.Get("cameras").String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	// IF_Clear, Home and the inquiry
+	if want := (voip.Metrics{Sent: 3, Errors: 1}); got["cam1"] != want {
+		t.Errorf("cam1 metrics = %+v, want %+v", got["cam1"], want)
+	}
+}
@@ -0,0 +1,93 @@
+package viscaoverip
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// failsafeTimer stops a drive group after Config.DriveTimeout.
+type failsafeTimer struct {
+	timer *time.Timer
+	gen   uint64 // Incremented by every drive command of the group
+}
+
+// refreshFailsafe arms the failsafe of a moving drive command, or disarms
+// it on a stop.
+func (c *Camera) refreshFailsafe(cmd string) {
+	if c.Config.DriveTimeout <= 0 {
+		return
+	}
+	group, normalized := driveGroup(cmd)
+	if group == "" {
+		return
+	}
+	c.failsafeMu.Lock()
+	defer c.failsafeMu.Unlock()
+	if c.failsafe == nil {
+		c.failsafe = make(map[string]*failsafeTimer)
+	}
+	f := c.failsafe[group]
+	if f == nil {
+		f = &failsafeTimer{}
+		c.failsafe[group] = f
+	}
+	f.gen++
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	if isDriveStop(group, normalized) {
+		return
+	}
+	gen := f.gen
+	f.timer = time.AfterFunc(c.Config.DriveTimeout, func() {
+		c.fireFailsafe(group, gen)
+	})
+}
+
+// isDriveStop reports whether a normalized drive command stops its group.
+func isDriveStop(group, normalized string) bool {
+	if group == "pan-tilt" {
+		return strings.HasSuffix(normalized, "0303")
+	}
+	return normalized == "040700"
+}
+
+// fireFailsafe stops a drive group, unless a drive command was sent since
+// the timer of gen was armed.
+func (c *Camera) fireFailsafe(group string, gen uint64) {
+	c.failsafeMu.Lock()
+	f := c.failsafe[group]
+	current := f != nil && f.gen == gen
+	c.failsafeMu.Unlock()
+	if !current || c.closing.Load() {
+		return
+	}
+
+	if c.debugging() {
+		c.ReportError("failsafe", fmt.Errorf("no %s drive command for %v, stopping", group, c.Config.DriveTimeout))
+	}
+	var err error
+	if group == "pan-tilt" {
+		err = c.PanTiltStop()
+	} else {
+		err = c.ZoomStop()
+	}
+	if err != nil {
+		c.ReportError("failsafe "+group+" stop", err)
+	}
+}
+
+// stopFailsafe disarms the failsafe of every drive group.
+func (c *Camera) stopFailsafe() {
+	c.failsafeMu.Lock()
+	defer c.failsafeMu.Unlock()
+	for _, f := range c.failsafe {
+		f.gen++
+		if f.timer != nil {
+			f.timer.Stop()
+			f.timer = nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package viscaoverip_test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestDriveTimeout(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	camera.Config.DriveTimeout = 50 * time.Millisecond
+
+	const (
+		panTiltStop = "8101060101010303ff"
+		zoomStop    = "8101040700ff"
+	)
+	sent := func(want string) int {
+		n := 0
+		for _, r := range emulator.Requests() {
+			if hex.EncodeToString(r) == want {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Refreshed drive commands keep moving
+	for range 6 {
+		if err := camera.PanTilt(5, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := camera.Zoom(2); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := sent(panTiltStop) + sent(zoomStop); n != 0 {
+		t.Fatalf("%d stops sent while drive commands were refreshed", n)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := sent(panTiltStop); n != 1 {
+		t.Errorf("%d pan-tilt stops sent after the timeout, want 1", n)
+	}
+	if n := sent(zoomStop); n != 1 {
+		t.Errorf("%d zoom stops sent after the timeout, want 1", n)
+	}
+
+	// A stop disarms the failsafe
+	if err := camera.PanTilt(5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.PanTiltStop(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := sent(panTiltStop); n != 2 {
+		t.Errorf("%d pan-tilt stops sent in total, want 2", n)
+	}
+}
@@ -0,0 +1,153 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// datagramConn is an in-memory socket returning queued datagrams, then a
+// deadline error at once, so that fuzzed replies are read without network
+// I/O or waiting.
+type datagramConn struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	remote *net.UDPAddr
+}
+
+func newDatagramConn() *datagramConn {
+	return &datagramConn{remote: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 52381}}
+}
+
+// push queues datagrams to be read.
+func (c *datagramConn) push(datagrams ...[]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queue = append(c.queue, datagrams...)
+}
+
+func (c *datagramConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queue) == 0 {
+		return 0, nil, os.ErrDeadlineExceeded
+	}
+	n := copy(b, c.queue[0])
+	c.queue = c.queue[1:]
+	return n, c.remote, nil
+}
+
+func (c *datagramConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+func (c *datagramConn) Write(b []byte) (int, error)               { return len(b), nil }
+func (c *datagramConn) WriteTo(b []byte, _ net.Addr) (int, error) { return len(b), nil }
+func (c *datagramConn) Close() error                              { return nil }
+func (c *datagramConn) LocalAddr() net.Addr                       { return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)} }
+func (c *datagramConn) RemoteAddr() net.Addr                      { return c.remote }
+func (c *datagramConn) SetDeadline(time.Time) error               { return nil }
+func (c *datagramConn) SetReadDeadline(time.Time) error           { return nil }
+func (c *datagramConn) SetWriteDeadline(time.Time) error          { return nil }
+
+// newFuzzCamera returns a camera on a datagramConn, sending each message
+// once and numbering messages from 1.
+func newFuzzCamera() (*voip.Camera, *datagramConn) {
+	conn := newDatagramConn()
+	camera := voip.New(conn, voip.Config{MaxRetries: 1, Timeout: time.Millisecond, CompletionTimeout: time.Millisecond})
+	return camera, conn
+}
+
+// fuzzSeeds are replies, well formed or not, to the message numbered 2.
+var fuzzSeeds = [][]byte{
+	makeResponse(2, 0x40),
+	makeResponse(2, 0x50),
+	makeResponse(2, 0x60),
+	makeResponse(1, 0x50),
+	makeResponse(3, 0x50),
+	{0x01, 0x11, 0x00, 0x0B, 0x00, 0x00, 0x00, 0x02, 0x90, 0x50, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0xFF},
+	{0x01, 0x11, 0x00, 0x07, 0x00, 0x00, 0x00, 0x02, 0x90, 0x50, 0x00, 0x00, 0x04, 0x00, 0xFF},
+	{0x01, 0x11, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x90, 0x51, 0xFF},
+	{0x01, 0x11, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x02, 0x90, 0x50, 0xFF},
+	{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x02, 0x90},
+	{0x01, 0x11, 0x00},
+	{0x02, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x01},
+	{},
+}
+
+// FuzzCommandReply checks that no datagram panics or hangs a command, even
+// with a command pending its completion.
+func FuzzCommandReply(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, datagram []byte) {
+		camera, conn := newFuzzCamera()
+		// Command 1 pending, its completion read along with command 2
+		conn.push(makeResponse(1, 0x40))
+		if _, err := camera.SendCommandAsync("06 04"); err != nil {
+			t.Fatal(err)
+		}
+		conn.push(datagram)
+		_ = camera.SendCommand("06 04")
+	})
+}
+
+// FuzzInquiryReply checks that no datagram panics or hangs an inquiry or
+// the decoding of its reply.
+func FuzzInquiryReply(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, datagram []byte) {
+		camera, conn := newFuzzCamera()
+		conn.push(datagram)
+		_, _ = camera.Position()
+		conn.push(bytes.Clone(datagram))
+		_, _ = camera.Version()
+		conn.push(bytes.Clone(datagram))
+		_, _ = camera.SendRaw(0x0210, []byte{0x01})
+	})
+}
+
+// FuzzControlReply checks that no datagram panics the RESET exchange.
+func FuzzControlReply(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, datagram []byte) {
+		conn := newDatagramConn()
+		camera := voip.New(conn, voip.Config{MaxRetries: 1, Timeout: time.Millisecond})
+		conn.push(datagram)
+		_ = camera.ResetSequenceNumber()
+	})
+}
+
+// FuzzReadMessage checks the framing of messages read from a stream.
+func FuzzReadMessage(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, 16)
+	}
+	f.Fuzz(func(t *testing.T, stream []byte, maxPayload int) {
+		r := bytes.NewReader(stream)
+		for {
+			message, err := voip.ReadMessage(r, maxPayload)
+			if err != nil {
+				if r.Len() == len(stream) && len(stream) >= voip.HeaderSize {
+					t.Fatalf("ReadMessage failed without reading: %v", err)
+				}
+				return
+			}
+			if len(message) < voip.HeaderSize {
+				t.Fatalf("message shorter than a header: % X", message)
+			}
+			_ = voip.DescribeReply(message[voip.HeaderSize:])
+		}
+	})
+}
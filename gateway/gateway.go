@@ -0,0 +1,269 @@
+// Package gateway bridges VISCA over IP to a serial VISCA chain, so legacy
+// cameras can be controlled by VISCA over IP controllers.
+//
+// The gateway answers the control commands (RESET) and IF_Clear itself and
+// echoes the sequence number of each request in its replies. Everything else
+// is forwarded to the serial chain, and the camera replies are sent back.
+package gateway
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReplyTimeout = 2 * time.Second
+
+	payloadTypeCommand       = 0x0100
+	payloadTypeInquiry       = 0x0110
+	payloadTypeReply         = 0x0111
+	payloadTypeDeviceSetting = 0x0120
+	payloadTypeControl       = 0x0200
+	payloadTypeControlReply  = 0x0201
+
+	headerSize     = 8
+	maxPayloadSize = 16
+)
+
+type Config struct {
+	// CameraAddress is the serial address (1 to 7) of the camera the
+	// requests are forwarded to. Defaults to 1.
+	CameraAddress int
+	// ReplyTimeout is how long to wait for each reply of the camera.
+	// Defaults to DefaultReplyTimeout.
+	ReplyTimeout time.Duration
+	// ErrorHandler, if set, is called with the errors the gateway handles by
+	// itself, e.g. to log them.
+	ErrorHandler func(error)
+}
+
+// Gateway forwards VISCA over IP requests to a serial VISCA chain.
+type Gateway struct {
+	serial  io.Writer
+	replies chan []byte
+	cfg     Config
+	mu      sync.Mutex // Serializes requests on the serial chain
+}
+
+// New returns a Gateway forwarding to serial, which is typically a serial
+// port opened at the baud rate of the chain. The serial reader goroutine
+// exits once reading from serial fails, e.g. after it is closed.
+func New(serial io.ReadWriter, cfg Config) *Gateway {
+	if cfg.CameraAddress == 0 {
+		cfg.CameraAddress = 1
+	}
+	if cfg.ReplyTimeout == 0 {
+		cfg.ReplyTimeout = DefaultReplyTimeout
+	}
+	g := &Gateway{
+		serial:  serial,
+		replies: make(chan []byte, 16),
+		cfg:     cfg,
+	}
+	go g.readSerial(serial)
+	return g
+}
+
+func (g *Gateway) readSerial(serial io.Reader) {
+	defer close(g.replies)
+	r := bufio.NewReader(serial)
+	for {
+		packet, err := r.ReadBytes(0xFF)
+		if err != nil {
+			return
+		}
+		g.replies <- packet
+	}
+}
+
+// ServeUDP answers the requests received on conn until reading from it
+// fails, e.g. after it is closed.
+func (g *Gateway) ServeUDP(conn net.PacketConn) error {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		for _, reply := range g.Handle(buf[:n]) {
+			if _, err := conn.WriteTo(reply, addr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ServeTCP accepts connections on l and answers their requests until
+// accepting fails, e.g. after l is closed.
+func (g *Gateway) ServeTCP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go g.serveConn(conn)
+	}
+}
+
+func (g *Gateway) serveConn(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		for _, reply := range g.Handle(msg) {
+			if _, err := conn.Write(reply); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readMessage reads exactly one message, as framed by its payload length.
+func readMessage(r io.Reader) ([]byte, error) {
+	msg := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:4]))
+	if length > maxPayloadSize {
+		return nil, fmt.Errorf("payload too long: %d bytes", length)
+	}
+	msg = append(msg, make([]byte, length)...)
+	if _, err := io.ReadFull(r, msg[headerSize:]); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Handle processes a single VISCA over IP message and returns the messages
+// to send back. Malformed messages get no reply.
+func (g *Gateway) Handle(msg []byte) [][]byte {
+	if len(msg) < headerSize {
+		return nil
+	}
+	payloadType := binary.BigEndian.Uint16(msg[0:2])
+	seqNum := binary.BigEndian.Uint32(msg[4:8])
+	payload := msg[headerSize:]
+	if int(binary.BigEndian.Uint16(msg[2:4])) != len(payload) || len(payload) == 0 {
+		return nil
+	}
+
+	switch payloadType {
+	case payloadTypeControl:
+		// RESET (01) is acknowledged with 01, a sequence error (0F 01)
+		// needs no answer.
+		if payload[0] == 0x01 {
+			return [][]byte{makeMessage(payloadTypeControlReply, seqNum, []byte{0x01})}
+		}
+		return nil
+	case payloadTypeCommand, payloadTypeInquiry, payloadTypeDeviceSetting:
+	default:
+		return nil
+	}
+	if len(payload) < 3 || payload[0]&0xF0 != 0x80 || payload[len(payload)-1] != 0xFF {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	packet := make([]byte, len(payload))
+	copy(packet, payload)
+	packet[0] = 0x80 | byte(g.cfg.CameraAddress)
+
+	if isIFClear(payload) {
+		// Forward to cancel pending commands, but answer locally. The reply
+		// of the chain is read and discarded, so that it is not taken for
+		// the reply of the next request.
+		g.drain()
+		if err := g.write(packet); err == nil {
+			g.readReplies(seqNum)
+		}
+		return [][]byte{makeMessage(payloadTypeReply, seqNum, []byte{0x90, 0x50, 0xFF})}
+	}
+
+	g.drain()
+	if err := g.write(packet); err != nil {
+		return nil
+	}
+	return g.readReplies(seqNum)
+}
+
+// readReplies reads the replies of the chain to the request numbered seqNum
+// until the final one, as messages to send back.
+func (g *Gateway) readReplies(seqNum uint32) [][]byte {
+	var replies [][]byte
+	timeout := time.NewTimer(g.cfg.ReplyTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case reply, ok := <-g.replies:
+			if !ok {
+				return replies
+			}
+			if len(reply) < 3 {
+				continue
+			}
+			// Controllers always see the reply as coming from address 1
+			reply[0] = 0x90
+			replies = append(replies, makeMessage(payloadTypeReply, seqNum, reply))
+			// Only ACKs are followed by another reply
+			if reply[1]&0xF0 != 0x40 {
+				return replies
+			}
+			timeout.Reset(g.cfg.ReplyTimeout)
+		case <-timeout.C:
+			g.report(fmt.Errorf("no reply from camera for sequence %d", seqNum))
+			return replies
+		}
+	}
+}
+
+func (g *Gateway) write(packet []byte) error {
+	_, err := g.serial.Write(packet)
+	if err != nil {
+		g.report(fmt.Errorf("write to serial: %w", err))
+	}
+	return err
+}
+
+func (g *Gateway) report(err error) {
+	if g.cfg.ErrorHandler != nil {
+		g.cfg.ErrorHandler(err)
+	}
+}
+
+// drain discards replies left over from earlier requests.
+func (g *Gateway) drain() {
+	for {
+		select {
+		case _, ok := <-g.replies:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isIFClear(payload []byte) bool {
+	return len(payload) == 5 && payload[1] == 0x01 && payload[2] == 0x00 &&
+		payload[3] == 0x01 && payload[4] == 0xFF
+}
+
+func makeMessage(payloadType uint16, seqNum uint32, payload []byte) []byte {
+	msg := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint16(msg[0:2], payloadType)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(payload)))
+	binary.BigEndian.PutUint32(msg[4:8], seqNum)
+	return append(msg, payload...)
+}
@@ -0,0 +1,144 @@
+package gateway_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/gateway"
+)
+
+// serialCamera emulates a camera at address 1 on a serial chain, which
+// answers IF_Clear after ifClearDelay.
+func serialCamera(conn net.Conn, ifClearDelay time.Duration) {
+	r := bufio.NewReader(conn)
+	for {
+		packet, err := r.ReadBytes(0xFF)
+		if err != nil {
+			return
+		}
+		if packet[0] != 0x81 {
+			continue
+		}
+		switch {
+		case bytes.Equal(packet, []byte{0x81, 0x01, 0x00, 0x01, 0xFF}):
+			time.Sleep(ifClearDelay)
+			conn.Write([]byte{0x90, 0x50, 0xFF})
+		case packet[1] == 0x01:
+			conn.Write([]byte{0x90, 0x41, 0xFF})
+			conn.Write([]byte{0x90, 0x51, 0xFF})
+		case packet[1] == 0x09:
+			conn.Write([]byte{0x90, 0x50, 0x02, 0xFF})
+		}
+	}
+}
+
+func TestGatewayUDP(t *testing.T) {
+	serial, cameraEnd := net.Pipe()
+	defer serial.Close()
+	go serialCamera(cameraEnd, 0)
+
+	g := gateway.New(serial, gateway.Config{ReplyTimeout: 100 * time.Millisecond})
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go g.ServeUDP(listener)
+
+	conn, err := net.DialUDP("udp", nil, listener.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := voip.Config{MaxRetries: 3, Timeout: 200 * time.Millisecond}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Errorf("SendCommand() error = %v", err)
+	}
+	data, err := camera.SendInquiry("04 00")
+	if err != nil || !bytes.Equal(data, []byte{0x02}) {
+		t.Errorf("SendInquiry() = %x, %v, want 02, nil", data, err)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	serial, cameraEnd := net.Pipe()
+	defer serial.Close()
+	go serialCamera(cameraEnd, 0)
+
+	g := gateway.New(serial, gateway.Config{ReplyTimeout: 100 * time.Millisecond})
+
+	tests := []struct {
+		name string
+		msg  []byte
+		want [][]byte
+	}{
+		{
+			"RESET is answered locally",
+			[]byte{0x02, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x07, 0x01},
+			[][]byte{{0x02, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x07, 0x01}},
+		},
+		{
+			"IF_Clear is answered locally",
+			[]byte{0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x08, 0x81, 0x01, 0x00, 0x01, 0xFF},
+			[][]byte{{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x08, 0x90, 0x50, 0xFF}},
+		},
+		{
+			"Command gets ACK and Completion",
+			[]byte{0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x09, 0x81, 0x01, 0x06, 0x04, 0xFF},
+			[][]byte{
+				{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x09, 0x90, 0x41, 0xFF},
+				{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x09, 0x90, 0x51, 0xFF},
+			},
+		},
+		{
+			"Bad payload length is ignored",
+			[]byte{0x01, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x0A, 0x81, 0x01, 0x06, 0x04, 0xFF},
+			nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := g.Handle(tc.msg)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Handle() = %x, want %x", got, tc.want)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tc.want[i]) {
+					t.Errorf("Handle()[%d] = %x, want %x", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleLateIFClearReply(t *testing.T) {
+	serial, cameraEnd := net.Pipe()
+	defer serial.Close()
+	go serialCamera(cameraEnd, 30*time.Millisecond)
+
+	g := gateway.New(serial, gateway.Config{ReplyTimeout: 100 * time.Millisecond})
+
+	ifClear := []byte{0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01, 0x81, 0x01, 0x00, 0x01, 0xFF}
+	if got := g.Handle(ifClear); len(got) != 1 {
+		t.Fatalf("Handle(IF_Clear) = %x, want one reply", got)
+	}
+	home := []byte{0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x02, 0x81, 0x01, 0x06, 0x04, 0xFF}
+	want := [][]byte{
+		{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x02, 0x90, 0x41, 0xFF},
+		{0x01, 0x11, 0x00, 0x03, 0x00, 0x00, 0x00, 0x02, 0x90, 0x51, 0xFF},
+	}
+	got := g.Handle(home)
+	if len(got) != len(want) || !bytes.Equal(got[0], want[0]) || !bytes.Equal(got[1], want[1]) {
+		t.Errorf("Handle() after IF_Clear = %x, want %x", got, want)
+	}
+}
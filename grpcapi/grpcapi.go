@@ -0,0 +1,356 @@
+// Package grpcapi serves the cameras of a Manager as the gRPC service
+// visca.v1.CameraService, defined in proto/visca/v1/camera.proto, for
+// integrating camera control into microservice based production systems.
+//
+// The module has no dependencies, so the service is implemented on
+// net/http instead of grpc-go: Handler speaks the gRPC protocol with a hand
+// written protobuf encoding. gRPC requires HTTP/2, which net/http serves
+// over TLS:
+//
+//	server := &http.Server{Addr: ":8443", Handler: grpcapi.NewHandler(m, grpcapi.Config{})}
+//	log.Fatal(server.ListenAndServeTLS("cert.pem", "key.pem"))
+//
+// Clients generate their stubs from the proto file. Compressed messages
+// are not supported.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+const (
+	DefaultPollInterval = 100 * time.Millisecond
+
+	serviceName = "visca.v1.CameraService"
+)
+
+// gRPC status codes
+const (
+	codeOK              = 0
+	codeCanceled        = 1
+	codeInvalidArgument = 3
+	codeNotFound        = 5
+	codeUnimplemented   = 12
+	codeInternal        = 13
+	codeUnavailable     = 14
+)
+
+type Config struct {
+	// PollInterval is the interval of position inquiries of WatchPosition
+	// calls that do not set one. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// Handler serves the gRPC calls of CameraService.
+type Handler struct {
+	manager *voip.Manager
+	cfg     Config
+}
+
+func NewHandler(m *voip.Manager, cfg Config) *Handler {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	return &Handler{manager: m, cfg: cfg}
+}
+
+// unaryMethods answer a request message with a response message.
+var unaryMethods = map[string]func(*Handler, fields) ([]byte, error){
+	"ListCameras":  (*Handler).listCameras,
+	"SendCommand":  (*Handler).sendCommand,
+	"SendInquiry":  (*Handler).sendInquiry,
+	"RecallPreset": (*Handler).recallPreset,
+	"SetPreset":    (*Handler).setPreset,
+	"PanTilt":      (*Handler).panTilt,
+	"Zoom":         (*Handler).zoom,
+	"Home":         (*Handler).home,
+	"Stop":         (*Handler).stop,
+}
+
+// streamMethods send response messages until the call is done.
+var streamMethods = map[string]func(*Handler, context.Context, *stream, fields) error{
+	"WatchPosition": (*Handler).watchPosition,
+	"WatchState":    (*Handler).watchState,
+}
+
+// statusError is an error with the gRPC status code it is reported with.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// statusCode returns the gRPC status code reporting err.
+func statusCode(err error) int {
+	var s *statusError
+	switch {
+	case err == nil:
+		return codeOK
+	case errors.As(err, &s):
+		return s.code
+	case errors.Is(err, context.Canceled):
+		return codeCanceled
+	case errors.Is(err, voip.ErrInvalidArgument):
+		return codeInvalidArgument
+	case errors.Is(err, voip.ErrUnsupported):
+		return codeUnimplemented
+	default:
+		return codeUnavailable
+	}
+}
+
+// stream writes the response messages of a call.
+type stream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *stream) send(msg []byte) error {
+	if _, err := s.w.Write(appendMessage(nil, msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "want a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	err := h.call(r, &stream{w: w, rc: http.NewResponseController(w)})
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(statusCode(err)))
+	if err != nil {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeStatusMessage(err.Error()))
+	}
+}
+
+// call runs the method of r, sending its response messages on s.
+func (h *Handler) call(r *http.Request, s *stream) error {
+	method, ok := strings.CutPrefix(r.URL.Path, "/"+serviceName+"/")
+	unary, isUnary := unaryMethods[method]
+	watch, isStream := streamMethods[method]
+	if !ok || !isUnary && !isStream {
+		return &statusError{codeUnimplemented, fmt.Errorf("unknown method: %s", r.URL.Path)}
+	}
+
+	msg, err := readMessage(r.Body)
+	if err != nil {
+		return &statusError{codeInternal, err}
+	}
+	req, err := decodeFields(msg)
+	if err != nil {
+		return &statusError{codeInternal, fmt.Errorf("invalid request message: %w", err)}
+	}
+
+	if isStream {
+		return watch(h, r.Context(), s, req)
+	}
+	res, err := unary(h, req)
+	if err != nil {
+		return err
+	}
+	return s.send(res)
+}
+
+// encodeStatusMessage percent-encodes a grpc-message.
+func encodeStatusMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c < 0x20 || c > 0x7E || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// camera returns the camera named by field 1 of a request.
+func (h *Handler) camera(req fields) (*voip.Camera, error) {
+	name := req.string(1)
+	c, ok := h.manager.Get(name)
+	if !ok {
+		return nil, &statusError{codeNotFound, fmt.Errorf("unknown camera: %s", name)}
+	}
+	return c, nil
+}
+
+// stateEnum returns the State enum value of s.
+func stateEnum(s voip.State) uint64 {
+	return uint64(s) + 1
+}
+
+func (h *Handler) listCameras(fields) ([]byte, error) {
+	var res encoder
+	for _, name := range h.manager.Names() {
+		c, ok := h.manager.Get(name)
+		if !ok {
+			continue
+		}
+		var camera encoder
+		camera.string(1, name)
+		camera.uint(2, stateEnum(c.State()))
+		res.message(1, camera.b)
+	}
+	return res.b, nil
+}
+
+func (h *Handler) sendCommand(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.SendCommand(req.string(2))
+}
+
+func (h *Handler) sendInquiry(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.SendInquiry(req.string(2))
+	if err != nil {
+		return nil, err
+	}
+	var res encoder
+	res.bytes(1, data)
+	return res.b, nil
+}
+
+func (h *Handler) recallPreset(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.RecallPreset(int(req.uint32(2)))
+}
+
+func (h *Handler) setPreset(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.SetPreset(int(req.uint32(2)))
+}
+
+func (h *Handler) panTilt(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.PanTilt(int(req.sint32(2)), int(req.sint32(3)))
+}
+
+func (h *Handler) zoom(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.Zoom(int(req.sint32(2)))
+}
+
+func (h *Handler) home(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.Home()
+}
+
+func (h *Handler) stop(req fields) ([]byte, error) {
+	c, err := h.camera(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.Join(c.PanTiltStop(), c.ZoomStop())
+}
+
+// watchPosition streams the position of a camera whenever it changes,
+// starting with the current one.
+func (h *Handler) watchPosition(ctx context.Context, s *stream, req fields) error {
+	c, err := h.camera(req)
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(req.uint32(2)) * time.Millisecond
+	if interval == 0 {
+		interval = h.cfg.PollInterval
+	}
+
+	positions, unsubscribe := voip.NewPoller(c, interval).Subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case pos := <-positions:
+			var res encoder
+			res.sint(1, int64(pos.Pan))
+			res.sint(2, int64(pos.Tilt))
+			res.uint(3, uint64(pos.Zoom))
+			res.uint(4, uint64(time.Now().UnixNano()))
+			if err := s.send(res.b); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchState streams the state transitions of a camera, starting with its
+// current state.
+func (h *Handler) watchState(ctx context.Context, s *stream, req fields) error {
+	c, err := h.camera(req)
+	if err != nil {
+		return err
+	}
+
+	type change struct{ from, to voip.State }
+	changes := make(chan change, 8)
+	unsubscribe := c.OnStateChange(func(from, to voip.State) {
+		select {
+		case changes <- change{from, to}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	var res encoder
+	res.uint(2, stateEnum(c.State()))
+	if err := s.send(res.b); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch := <-changes:
+			var res encoder
+			res.uint(1, stateEnum(ch.from))
+			res.uint(2, stateEnum(ch.to))
+			if err := s.send(res.b); err != nil {
+				return err
+			}
+		}
+	}
+}
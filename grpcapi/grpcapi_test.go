@@ -0,0 +1,229 @@
+package grpcapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/grpcapi"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+// field is a field of a protobuf message, for building requests and
+// reading responses: a varint if data is nil.
+type field struct {
+	num    int
+	varint uint64
+	data   []byte
+}
+
+func encode(fields ...field) []byte {
+	var b []byte
+	for _, f := range fields {
+		if f.data != nil {
+			b = binary.AppendUvarint(b, uint64(f.num)<<3|2)
+			b = binary.AppendUvarint(b, uint64(len(f.data)))
+			b = append(b, f.data...)
+		} else {
+			b = binary.AppendUvarint(b, uint64(f.num)<<3)
+			b = binary.AppendUvarint(b, f.varint)
+		}
+	}
+	return b
+}
+
+// decode returns the varint and length-delimited fields of a message.
+func decode(t *testing.T, b []byte) map[int]field {
+	t.Helper()
+	fields := make(map[int]field)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		f := field{num: int(key >> 3)}
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("invalid message % X", b)
+		}
+		b = b[n:]
+		switch key & 7 {
+		case 0:
+			f.varint = v
+		case 2:
+			f.data, b = b[:v], b[v:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+		fields[f.num] = f
+	}
+	return fields
+}
+
+func zigzag(v uint64) int {
+	return int(int64(v>>1) ^ -int64(v&1))
+}
+
+func frame(msg []byte) []byte {
+	b := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// readFrame reads a length-prefixed gRPC message.
+func readFrame(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	_, err := io.ReadFull(r, msg)
+	return msg, err
+}
+
+func post(ctx context.Context, t *testing.T, server *httptest.Server, method string, req []byte) *http.Response {
+	t.Helper()
+	r, err := http.NewRequestWithContext(ctx, "POST", server.URL+"/visca.v1.CameraService/"+method, bytes.NewReader(frame(req)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/grpc")
+	res, err := server.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ProtoMajor != 2 {
+		t.Fatalf("response over HTTP/%d, want HTTP/2", res.ProtoMajor)
+	}
+	return res
+}
+
+// call runs a unary call, returning its response message and status.
+func call(t *testing.T, server *httptest.Server, method string, req []byte) ([]byte, string) {
+	t.Helper()
+	res := post(context.Background(), t, server, method, req)
+	defer res.Body.Close()
+	msg, err := readFrame(res.Body)
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	return msg, res.Trailer.Get("Grpc-Status")
+}
+
+func TestHandler(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	if err := m.Add("cam1", camera); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(grpcapi.NewHandler(m, grpcapi.Config{PollInterval: 10 * time.Millisecond}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	cam1 := field{num: 1, data: []byte("cam1")}
+	// Signed speeds are zigzag encoded: 24 is 12, 9 is -5 and 5 is -3
+	tests := []struct {
+		name       string
+		method     string
+		req        []byte
+		wantStatus string
+		wantSent   string // Last VISCA packet received by the camera
+	}{
+		{"Recall Preset", "RecallPreset", encode(cam1, field{num: 2, varint: 3}), "0", "81 01 04 3F 02 03 FF"},
+		{"Preset Out Of Range", "RecallPreset", encode(cam1, field{num: 2, varint: 300}), "3", ""},
+		{"PanTilt", "PanTilt", encode(cam1, field{num: 2, varint: 24}, field{num: 3, varint: 9}), "0", "81 01 06 01 0C 05 02 02 FF"},
+		{"Zoom", "Zoom", encode(cam1, field{num: 2, varint: 5}), "0", "81 01 04 07 32 FF"},
+		{"Home", "Home", encode(cam1), "0", "81 01 06 04 FF"},
+		{"Command", "SendCommand", encode(cam1, field{num: 2, data: []byte("04 07 00")}), "0", "81 01 04 07 00 FF"},
+		{"Unknown Camera", "Home", encode(field{num: 1, data: []byte("cam9")}), "5", ""},
+		{"Unknown Method", "Reboot", encode(cam1), "12", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before := len(emulator.Requests())
+			_, status := call(t, server, tc.method, tc.req)
+			if status != tc.wantStatus {
+				t.Errorf("grpc-status = %s, want %s", status, tc.wantStatus)
+			}
+			requests := emulator.Requests()[before:]
+			if tc.wantSent == "" {
+				if len(requests) != 0 {
+					t.Errorf("sent % X, want nothing", requests)
+				}
+				return
+			}
+			want, _ := hex.DecodeString(strings.ReplaceAll(tc.wantSent, " ", ""))
+			if len(requests) == 0 || !bytes.Equal(requests[len(requests)-1], want) {
+				t.Errorf("sent % X, want % X last", requests, want)
+			}
+		})
+	}
+
+	t.Run("ListCameras", func(t *testing.T) {
+		msg, status := call(t, server, "ListCameras", nil)
+		if status != "0" {
+			t.Fatalf("grpc-status = %s, want 0", status)
+		}
+		cam := decode(t, decode(t, msg)[1].data)
+		if string(cam[1].data) != "cam1" || cam[2].varint != 2 {
+			t.Errorf("camera = %q in state %d, want cam1 in STATE_CONNECTED", cam[1].data, cam[2].varint)
+		}
+	})
+
+	t.Run("SendInquiry", func(t *testing.T) {
+		msg, status := call(t, server, "SendInquiry", encode(cam1, field{num: 2, data: []byte("04 00")}))
+		if status != "0" {
+			t.Fatalf("grpc-status = %s, want 0", status)
+		}
+		if data := decode(t, msg)[1].data; !bytes.Equal(data, []byte{0x02}) {
+			t.Errorf("data = % X, want 02", data)
+		}
+	})
+
+	t.Run("WatchPosition", func(t *testing.T) {
+		emulator.SetState(viscatest.State{Power: true, Pan: 42})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		res := post(ctx, t, server, "WatchPosition", encode(cam1))
+		defer res.Body.Close()
+
+		next := func() (pan, tilt int) {
+			t.Helper()
+			msg, err := readFrame(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos := decode(t, msg)
+			if pos[4].varint == 0 {
+				t.Error("position without time")
+			}
+			return zigzag(pos[1].varint), zigzag(pos[2].varint)
+		}
+		if pan, tilt := next(); pan != 42 || tilt != 0 {
+			t.Errorf("first position = %d, %d, want 42, 0", pan, tilt)
+		}
+		emulator.SetState(viscatest.State{Power: true, Pan: -10, Tilt: 5})
+		if pan, tilt := next(); pan != -10 || tilt != 5 {
+			t.Errorf("next position = %d, %d, want -10, 5", pan, tilt)
+		}
+	})
+}
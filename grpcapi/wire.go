@@ -0,0 +1,153 @@
+package grpcapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the size limit of request messages.
+const maxMessageSize = 1 << 16
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder appends the fields of a protobuf message. Fields of zero value are
+// omitted, as in proto3.
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) key(field, wireType int) {
+	e.b = binary.AppendUvarint(e.b, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(field, wireVarint)
+	e.b = binary.AppendUvarint(e.b, v)
+}
+
+// sint appends a sint32 or sint64 field, zigzag encoded.
+func (e *encoder) sint(field int, v int64) {
+	e.uint(field, uint64(v<<1)^uint64(v>>63))
+}
+
+func (e *encoder) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.key(field, wireBytes)
+	e.b = binary.AppendUvarint(e.b, uint64(len(b)))
+	e.b = append(e.b, b...)
+}
+
+func (e *encoder) string(field int, s string) {
+	e.bytes(field, []byte(s))
+}
+
+// message appends an embedded message, even if empty, as for repeated
+// fields.
+func (e *encoder) message(field int, m []byte) {
+	e.key(field, wireBytes)
+	e.b = binary.AppendUvarint(e.b, uint64(len(m)))
+	e.b = append(e.b, m...)
+}
+
+// fields are the scalar fields of a decoded protobuf message, by number.
+// The last value of a repeated field wins, and unknown fields are kept
+// unread.
+type fields struct {
+	varints map[int]uint64
+	bytes   map[int][]byte
+}
+
+func decodeFields(b []byte) (fields, error) {
+	f := fields{varints: make(map[int]uint64), bytes: make(map[int][]byte)}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return f, errors.New("invalid field key")
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return f, fmt.Errorf("invalid varint of field %d", field)
+			}
+			f.varints[field] = v
+			b = b[n:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return f, fmt.Errorf("invalid length of field %d", field)
+			}
+			f.bytes[field] = b[n : n+int(size)]
+			b = b[n+int(size):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return f, fmt.Errorf("truncated field %d", field)
+			}
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return f, fmt.Errorf("truncated field %d", field)
+			}
+			b = b[4:]
+		default:
+			return f, fmt.Errorf("unsupported wire type of field %d: %d", field, key&7)
+		}
+	}
+	return f, nil
+}
+
+func (f fields) string(field int) string {
+	return string(f.bytes[field])
+}
+
+func (f fields) uint32(field int) uint32 {
+	return uint32(f.varints[field])
+}
+
+// sint32 returns a zigzag encoded sint32 field.
+func (f fields) sint32(field int) int32 {
+	v := uint32(f.varints[field])
+	return int32(v>>1) ^ -int32(v&1)
+}
+
+// readMessage reads a length-prefixed gRPC message.
+func readMessage(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, fmt.Errorf("read message prefix: %w", err)
+	}
+	if prefix[0] != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds %d", size, maxMessageSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	return msg, nil
+}
+
+// appendMessage appends a length-prefixed, uncompressed gRPC message.
+func appendMessage(b, msg []byte) []byte {
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(msg)))
+	return append(b, msg...)
+}
@@ -0,0 +1,123 @@
+package viscaoverip
+
+import (
+	"context"
+	"time"
+)
+
+// PowerInquiry (CAM_PowerInq) is answered by virtually every camera
+// without side effects, which makes it a good liveness probe.
+const PowerInquiry = "04 00"
+
+// Probe is the message sent to check that a camera is alive: by the
+// heartbeat, by Ping and, with Config.InitializeWithProbe, by Initialize.
+// Either Inquiry or Command is set, as the hex accepted by SendInquiry or
+// SendCommand.
+type Probe struct {
+	Inquiry string
+	Command string
+}
+
+// livenessProbe returns the Probe of the camera: Config.LivenessProbe, or
+// the probe of its vendor profile.
+func (c *Camera) livenessProbe() Probe {
+	if c.Config.LivenessProbe != (Probe{}) {
+		return c.Config.LivenessProbe
+	}
+	return c.Config.Vendor.LivenessProbe()
+}
+
+// sendProbe sends the liveness probe. c.mu must be held.
+func (c *Camera) sendProbe(ctx context.Context) error {
+	p := c.livenessProbe()
+	var message []byte
+	var err error
+	o := c.sendOptions(nil)
+	if p.Command != "" {
+		message, err = MakeCommand(p.Command, 0)
+	} else {
+		message, err = MakeInquiry(p.Inquiry, 0)
+		o = c.inquiryOptions(nil)
+	}
+	if err != nil {
+		return err
+	}
+	seqNum, err := c.number(ctx, message)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, message, seqNum, o)
+	return err
+}
+
+// Ping sends the liveness probe, a power inquiry by default, and returns the
+// round trip time until its reply, retries included. It has no side effects
+// on the camera state, so it can be used to pre-flight a camera.
+func (c *Camera) Ping(ctx context.Context) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.connect(ctx); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if err := c.sendProbe(ctx); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+func (c *Camera) startHeartbeat() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startHeartbeatLocked()
+}
+
+// startHeartbeatLocked is startHeartbeat with c.mu held.
+func (c *Camera) startHeartbeatLocked() {
+	if c.Config.HeartbeatInterval <= 0 {
+		return
+	}
+	if c.heartbeatStop != nil {
+		return // Already running, e.g. Initialize called again
+	}
+	c.heartbeatStop = make(chan struct{})
+	c.heartbeatDone = make(chan struct{})
+	go c.heartbeat(c.heartbeatStop, c.heartbeatDone)
+}
+
+func (c *Camera) stopHeartbeat() {
+	c.mu.Lock()
+	stop, done := c.heartbeatStop, c.heartbeatDone
+	c.heartbeatStop, c.heartbeatDone = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (c *Camera) heartbeat(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.Config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		// The State is updated by the exchange itself
+		c.mu.Lock()
+		err := c.sendProbe(context.Background())
+		c.mu.Unlock()
+		if err != nil {
+			c.ReportError("heartbeat", err)
+		}
+	}
+}
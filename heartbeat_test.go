@@ -0,0 +1,179 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestHeartbeat(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var silent atomic.Bool
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		if silent.Load() {
+			return nil
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		if msg[0] == 0x01 && msg[1] == 0x10 {
+			return [][]byte{makeResponse(seqNum, 0x50)}
+		}
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{
+		MaxRetries:         1,
+		Timeout:            20 * time.Millisecond,
+		HeartbeatInterval:  30 * time.Millisecond,
+		OfflineAfterMisses: 2,
+	}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	waitState := func(want voip.State) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for camera.State() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("State() = %v, want %v", camera.State(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitState(voip.StateConnected)
+	silent.Store(true)
+	waitState(voip.StateDegraded)
+	waitState(voip.StateOffline)
+	silent.Store(false)
+	waitState(voip.StateConnected)
+}
+
+func TestPing(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		if msg[0] == 0x01 && msg[1] == 0x10 {
+			return [][]byte{makeResponse(seqNum, 0x50)}
+		}
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	latency, err := camera.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latency <= 0 || latency > cfg.Timeout {
+		t.Errorf("Ping() = %v, want between 0 and %v", latency, cfg.Timeout)
+	}
+}
+
+func TestLivenessProbe(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{
+		MaxRetries:          3,
+		Timeout:             100 * time.Millisecond,
+		LivenessProbe:       voip.Probe{Inquiry: "00 02"}, // Version inquiry
+		InitializeWithProbe: true,
+	}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+	if _, err := camera.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	versionInquiry := []byte{0x81, 0x09, 0x00, 0x02, 0xFF}
+	requests := emulator.Requests()
+	if len(requests) != 2 || !bytes.Equal(requests[0], versionInquiry) || !bytes.Equal(requests[1], versionInquiry) {
+		t.Errorf("requests = % X, want the probe at Initialize and Ping", requests)
+	}
+}
+
+func TestHeartbeatAfterReconnect(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond, HeartbeatInterval: 10 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := camera.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.Reconnect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	before := len(emulator.Requests())
+	deadline := time.Now().Add(time.Second)
+	for len(emulator.Requests()) < before+2 {
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeat after Reconnect()")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,150 @@
+// Package httpapi exposes the cameras of a Manager over HTTP, so web control
+// panels and stream decks can drive cameras without speaking VISCA.
+//
+// Routes:
+//
+//	GET  /cameras                         list cameras and their state
+//	GET  /cameras/{name}                  state of one camera
+//	POST /cameras/{name}/preset/{n}       recall preset n
+//	POST /cameras/{name}/preset/{n}/store store the current position as preset n
+//	POST /cameras/{name}/ptz              drive pan-tilt, body {"pan": 12, "tilt": -5}
+//	POST /cameras/{name}/zoom             drive zoom, body {"speed": 3}
+//	POST /cameras/{name}/home             move to home position
+//	POST /cameras/{name}/stop             stop pan, tilt and zoom
+//
+// Speeds are signed as in Camera.PanTilt and Camera.Zoom; zero stops.
+// Commands answer 204 No Content on success, and errors are reported as
+// {"error": "..."}.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+type cameraInfo struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type ptzRequest struct {
+	Pan  int `json:"pan"`
+	Tilt int `json:"tilt"`
+}
+
+type zoomRequest struct {
+	Speed int `json:"speed"`
+}
+
+// NewHandler returns an http.Handler serving the cameras of m.
+func NewHandler(m *voip.Manager) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /cameras", func(w http.ResponseWriter, r *http.Request) {
+		infos := []cameraInfo{}
+		for _, name := range m.Names() {
+			if c, ok := m.Get(name); ok {
+				infos = append(infos, cameraInfo{Name: name, State: c.State().String()})
+			}
+		}
+		writeJSON(w, http.StatusOK, infos)
+	})
+
+	mux.HandleFunc("GET /cameras/{name}", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		writeJSON(w, http.StatusOK, cameraInfo{Name: r.PathValue("name"), State: c.State().String()})
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/preset/{n}", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		preset, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid preset: %s", r.PathValue("n")))
+			return
+		}
+		writeResult(w, c.RecallPreset(preset))
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/preset/{n}/store", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		preset, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid preset: %s", r.PathValue("n")))
+			return
+		}
+		writeResult(w, c.SetPreset(preset))
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/ptz", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		var req ptzRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		writeResult(w, c.PanTilt(req.Pan, req.Tilt))
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/zoom", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		var req zoomRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		writeResult(w, c.Zoom(req.Speed))
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/home", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		writeResult(w, c.Home())
+	}))
+
+	mux.HandleFunc("POST /cameras/{name}/stop", withCamera(m, func(w http.ResponseWriter, r *http.Request, c *voip.Camera) {
+		writeResult(w, errors.Join(c.PanTiltStop(), c.ZoomStop()))
+	}))
+
+	return mux
+}
+
+func withCamera(m *voip.Manager, h func(http.ResponseWriter, *http.Request, *voip.Camera)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, ok := m.Get(r.PathValue("name"))
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("unknown camera: %s", r.PathValue("name")))
+			return
+		}
+		h(w, r, c)
+	}
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<12))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeResult reports the outcome of a camera command.
+func writeResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, voip.ErrInvalidArgument):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, voip.ErrUnsupported):
+		writeError(w, http.StatusNotImplemented, err)
+	default:
+		writeError(w, http.StatusBadGateway, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,90 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/httpapi"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestHandler(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	if err := m.Add("cam1", camera); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(httpapi.NewHandler(m))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string // Substring of the response body
+		wantSent   string // Last VISCA packet received by the camera
+	}{
+		{"List", "GET", "/cameras", "", 200, `[{"name":"cam1","state":"connected"}]`, ""},
+		{"Get", "GET", "/cameras/cam1", "", 200, `"state":"connected"`, ""},
+		{"Unknown Camera", "POST", "/cameras/cam9/home", "", 404, "unknown camera", ""},
+		{"Recall Preset", "POST", "/cameras/cam1/preset/3", "", 204, "", "81 01 04 3F 02 03 FF"},
+		{"Store Preset", "POST", "/cameras/cam1/preset/4/store", "", 204, "", "81 01 04 3F 01 04 FF"},
+		{"Invalid Preset", "POST", "/cameras/cam1/preset/x", "", 400, "invalid preset", ""},
+		{"Preset Out Of Range", "POST", "/cameras/cam1/preset/300", "", 400, "invalid argument", ""},
+		{"PTZ", "POST", "/cameras/cam1/ptz", `{"pan": 12, "tilt": -5}`, 204, "", "81 01 06 01 0C 05 02 02 FF"},
+		{"PTZ Bad Body", "POST", "/cameras/cam1/ptz", `{"pan": "fast"}`, 400, "invalid request body", ""},
+		{"Zoom", "POST", "/cameras/cam1/zoom", `{"speed": -3}`, 204, "", "81 01 04 07 32 FF"},
+		{"Home", "POST", "/cameras/cam1/home", "", 204, "", "81 01 06 04 FF"},
+		{"Stop", "POST", "/cameras/cam1/stop", "", 204, "", "81 01 04 07 00 FF"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != tc.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", res.StatusCode, tc.wantStatus, body)
+			}
+			if !strings.Contains(string(body), tc.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", body, tc.wantBody)
+			}
+			if tc.wantSent != "" {
+				requests := emulator.Requests()
+				got := requests[len(requests)-1]
+				want, _ := hex.DecodeString(strings.ReplaceAll(tc.wantSent, " ", ""))
+				if string(got) != string(want) {
+					t.Errorf("sent % X, want %s", got, tc.wantSent)
+				}
+			}
+		})
+	}
+}
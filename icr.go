@@ -0,0 +1,40 @@
+package viscaoverip
+
+// MaxICRThreshold is the largest Auto ICR threshold.
+const MaxICRThreshold = 0xFF
+
+// SetNightMode removes the IR cut filter (night mode) or puts it back (day
+// mode), CAM_ICR On/Off. The picture turns black and white in night mode.
+func (c *Camera) SetNightMode(on bool) error {
+	return c.setOnOff("04 01", on)
+}
+
+// NightMode reports whether the IR cut filter is removed.
+func (c *Camera) NightMode() (bool, error) {
+	return c.inquireOnOff("04 01")
+}
+
+// SetAutoICR turns the automatic switching between day and night modes on
+// or off (CAM_AutoICR), according to the scene brightness.
+func (c *Camera) SetAutoICR(on bool) error {
+	return c.setOnOff("04 51", on)
+}
+
+// AutoICR reports whether automatic day and night switching is on.
+func (c *Camera) AutoICR() (bool, error) {
+	return c.inquireOnOff("04 51")
+}
+
+// SetAutoICRThreshold sets the brightness threshold of the switch to night
+// mode, from 0 to MaxICRThreshold; higher values switch earlier.
+func (c *Camera) SetAutoICRThreshold(threshold int) error {
+	if err := checkRange("auto ICR threshold", threshold, 0, MaxICRThreshold); err != nil {
+		return err
+	}
+	return c.setDirect(0x21, threshold)
+}
+
+// AutoICRThreshold inquires the threshold of the switch to night mode.
+func (c *Camera) AutoICRThreshold() (int, error) {
+	return c.inquireDirect(0x21)
+}
@@ -0,0 +1,34 @@
+package viscaoverip_test
+
+import (
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestICR(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.SetNightMode(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.NightMode(); err != nil || !on {
+		t.Errorf("NightMode() = %v, %v, want true", on, err)
+	}
+	if err := camera.SetAutoICR(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := camera.AutoICR(); err != nil || !on {
+		t.Errorf("AutoICR() = %v, %v, want true", on, err)
+	}
+	if err := camera.SetAutoICRThreshold(0x80); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := camera.AutoICRThreshold(); err != nil || v != 0x80 {
+		t.Errorf("AutoICRThreshold() = %#x, %v, want 0x80", v, err)
+	}
+	if err := camera.SetAutoICRThreshold(0x100); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetAutoICRThreshold(0x100) = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,258 @@
+package viscaoverip
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PollUpdate is a consolidated update of an InquiryPoller: the replies that
+// changed since the previous update, by inquiry.
+type PollUpdate struct {
+	Time time.Time
+	// Data is the new reply data of the inquiries whose reply changed, by
+	// inquiry hex as added to the poller.
+	Data map[string][]byte
+	// Errors are the errors of the inquiries that failed, by inquiry hex.
+	// The last reply of a failed inquiry is kept.
+	Errors map[string]error
+}
+
+// InquiryPoller polls a set of inquiries, each at its own interval, from a
+// single goroutine: the inquiries due at about the same time are sent in
+// one round, spaced by a minimum gap so that polling never floods the
+// camera, and the changes of a round are delivered as one PollUpdate. It
+// only polls while it has subscribers.
+type InquiryPoller struct {
+	camera *Camera
+	minGap time.Duration
+
+	mu        sync.Mutex
+	inquiries map[string]*polledInquiry
+	subs      map[int]chan PollUpdate
+	nextID    int
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type polledInquiry struct {
+	interval time.Duration
+	next     time.Time // Zero until first polled
+	last     []byte
+	known    bool // last holds a reply
+}
+
+// NewInquiryPoller returns an InquiryPoller of c, sending inquiries at
+// least minGap apart.
+func NewInquiryPoller(c *Camera, minGap time.Duration) *InquiryPoller {
+	return &InquiryPoller{
+		camera:    c,
+		minGap:    minGap,
+		inquiries: make(map[string]*polledInquiry),
+		subs:      make(map[int]chan PollUpdate),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Add polls an inquiry, as accepted by SendInquiry, every interval. The
+// updates key its reply by inquiryHex as given. Adding an inquiry again
+// changes its interval.
+func (p *InquiryPoller) Add(inquiryHex string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: poll interval must be positive: %v", ErrInvalidArgument, interval)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if q, ok := p.inquiries[inquiryHex]; ok {
+		q.interval = interval
+	} else {
+		p.inquiries[inquiryHex] = &polledInquiry{interval: interval}
+	}
+	p.signal()
+	return nil
+}
+
+// Remove stops polling an inquiry.
+func (p *InquiryPoller) Remove(inquiryHex string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inquiries, inquiryHex)
+}
+
+// signal wakes the polling goroutine up to reschedule. p.mu must be held.
+func (p *InquiryPoller) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe returns a channel receiving the updates, starting with the
+// first round, and a function to unsubscribe. A slow subscriber receives
+// the updates it missed merged into one. Each subscriber receives its own
+// copy of the updates.
+func (p *InquiryPoller) Subscribe() (<-chan PollUpdate, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan PollUpdate, 1)
+	id := p.nextID
+	p.nextID++
+	p.subs[id] = ch
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		for _, q := range p.inquiries {
+			q.next, q.known = time.Time{}, false
+		}
+		go p.run(p.stop, p.done)
+	}
+
+	return ch, func() {
+		p.mu.Lock()
+		if _, ok := p.subs[id]; !ok {
+			p.mu.Unlock()
+			return
+		}
+		delete(p.subs, id)
+		var stop, done chan struct{}
+		if len(p.subs) == 0 {
+			stop, done = p.stop, p.done
+			p.stop, p.done = nil, nil
+		}
+		p.mu.Unlock()
+
+		if stop != nil {
+			close(stop)
+			<-done
+		}
+	}
+}
+
+func (p *InquiryPoller) run(stop, done chan struct{}) {
+	defer close(done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		case <-p.wake:
+		}
+
+		p.round(stop)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait, ok := p.untilNext(); ok {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// due returns the inquiries due by now, or due within a quarter of their
+// interval, so that inquiries of similar intervals are polled in the same
+// round, in the order they are due.
+func (p *InquiryPoller) due(now time.Time) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var keys []string
+	for key, q := range p.inquiries {
+		if !q.next.After(now.Add(q.interval / 4)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := p.inquiries[keys[i]], p.inquiries[keys[j]]
+		if !a.next.Equal(b.next) {
+			return a.next.Before(b.next)
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// round polls the due inquiries and publishes the changes.
+func (p *InquiryPoller) round(stop chan struct{}) {
+	update := PollUpdate{Data: make(map[string][]byte), Errors: make(map[string]error)}
+	for i, key := range p.due(time.Now()) {
+		if i > 0 && p.minGap > 0 {
+			select {
+			case <-stop:
+				return
+			case <-time.After(p.minGap):
+			}
+		}
+		data, err := p.camera.SendInquiry(key, WithNoCache())
+
+		p.mu.Lock()
+		q, ok := p.inquiries[key]
+		if !ok {
+			p.mu.Unlock()
+			continue // Removed meanwhile
+		}
+		q.next = time.Now().Add(q.interval)
+		switch {
+		case err != nil:
+			update.Errors[key] = err
+		case !q.known || !bytes.Equal(data, q.last):
+			q.last, q.known = data, true
+			update.Data[key] = data
+		}
+		p.mu.Unlock()
+	}
+	if len(update.Data) == 0 && len(update.Errors) == 0 {
+		return
+	}
+	update.Time = time.Now()
+	p.publish(update)
+}
+
+// untilNext returns the time until the next inquiry is due, if any.
+func (p *InquiryPoller) untilNext() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var next time.Time
+	for _, q := range p.inquiries {
+		if next.IsZero() || q.next.Before(next) {
+			next = q.next
+		}
+	}
+	if len(p.inquiries) == 0 {
+		return 0, false
+	}
+	return max(0, time.Until(next)), true
+}
+
+func (p *InquiryPoller) publish(update PollUpdate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ch := range p.subs {
+		u := PollUpdate{Time: update.Time, Data: make(map[string][]byte), Errors: make(map[string]error)}
+		// Merge an update the subscriber has not received yet, which only
+		// it holds
+		select {
+		case missed := <-ch:
+			u.Data, u.Errors = missed.Data, missed.Errors
+			for key := range update.Data {
+				delete(u.Errors, key)
+			}
+		default:
+		}
+		for key, data := range update.Data {
+			u.Data[key] = bytes.Clone(data)
+		}
+		maps.Copy(u.Errors, update.Errors)
+		ch <- u
+	}
+}
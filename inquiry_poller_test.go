@@ -0,0 +1,78 @@
+package viscaoverip_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestInquiryPoller(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Zoom: 0x1000})
+
+	poller := voip.NewInquiryPoller(camera, time.Millisecond)
+	if err := poller.Add(voip.PowerInquiry, 0); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("Add(interval 0) = %v, want ErrInvalidArgument", err)
+	}
+	for inquiry, interval := range map[string]time.Duration{
+		voip.PowerInquiry:        10 * time.Millisecond,
+		voip.ZoomPositionInquiry: time.Hour,
+	} {
+		if err := poller.Add(inquiry, interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updates, unsubscribe := poller.Subscribe()
+	defer unsubscribe()
+	// A second subscriber must not see the changes of the first
+	others, unsubscribeOthers := poller.Subscribe()
+	defer unsubscribeOthers()
+
+	next := func() voip.PollUpdate {
+		t.Helper()
+		select {
+		case update := <-updates:
+			return update
+		case <-time.After(time.Second):
+			t.Fatal("no poll update")
+			return voip.PollUpdate{}
+		}
+	}
+
+	// Both inquiries in the first update
+	update := next()
+	if len(update.Data) != 2 || len(update.Errors) != 0 {
+		t.Fatalf("first update = %+v, want both inquiries", update)
+	}
+	if got := update.Data[voip.PowerInquiry]; len(got) != 1 || got[0] != 0x02 {
+		t.Errorf("power = % X, want 02", got)
+	}
+	update.Data[voip.PowerInquiry][0] = 0xFF
+	delete(update.Data, voip.ZoomPositionInquiry)
+	select {
+	case other := <-others:
+		if len(other.Data) != 2 || other.Data[voip.PowerInquiry][0] != 0x02 {
+			t.Errorf("first update of the other subscriber = %+v, want both inquiries, power 02", other)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no poll update for the other subscriber")
+	}
+
+	// Only the change of the fast inquiry next
+	emulator.SetState(viscatest.State{Power: false, Zoom: 0x2000})
+	update = next()
+	if len(update.Data) != 1 || update.Data[voip.PowerInquiry][0] != 0x03 {
+		t.Errorf("second update = %+v, want power 03 only", update)
+	}
+
+	unsubscribe()
+	unsubscribeOthers()
+	before := len(emulator.Requests())
+	time.Sleep(50 * time.Millisecond)
+	if after := len(emulator.Requests()); after != before {
+		t.Errorf("poller kept polling after the last unsubscribe: %d inquiries", after-before)
+	}
+}
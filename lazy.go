@@ -0,0 +1,44 @@
+package viscaoverip
+
+import (
+	"context"
+	"net"
+)
+
+// DialLazy returns a Camera for the peripheral device at address (host:port)
+// without any network I/O: the camera is dialed and initialized by Connect,
+// or by its first exchange, so that a fleet can be configured at startup
+// while some cameras are powered off or unreachable. A failed connection is
+// attempted again by the next exchange.
+//
+// The heartbeat, if configured, starts once the camera is connected.
+func DialLazy(address string, cfg Config) *Camera {
+	c := New(nil, cfg)
+	c.address = address
+	c.lazy = true
+	return c
+}
+
+// Connect dials and initializes a camera made with DialLazy, which is
+// otherwise done by its first exchange. It does nothing if the camera is
+// already connected. Cancelling ctx interrupts the pending network I/O.
+func (c *Camera) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connect(ctx)
+}
+
+// connect is Connect with c.mu held.
+func (c *Camera) connect(ctx context.Context) error {
+	if !c.lazy {
+		return nil
+	}
+	if c.closing.Load() {
+		return net.ErrClosed
+	}
+	if err := c.reinitialize(ctx); err != nil {
+		return err
+	}
+	c.startHeartbeatLocked()
+	return nil
+}
@@ -0,0 +1,62 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestDialLazy(t *testing.T) {
+	// Reserve a port, with no camera listening on it yet
+	probe, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := probe.LocalAddr().String()
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: 50 * time.Millisecond}
+	camera := voip.DialLazy(addr, cfg)
+	defer camera.Close()
+	if state := camera.State(); state != voip.StateInitializing {
+		t.Errorf("State() = %v before connecting, want initializing", state)
+	}
+
+	// Powered off camera: the command fails, the camera stays lazy
+	if err := camera.SendCommand("06 04"); err == nil {
+		t.Error("SendCommand() succeeded without a camera")
+	}
+	probe.Close()
+
+	emulator, err := viscatest.NewEmulatorAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatalf("SendCommand() once the camera is up = %v", err)
+	}
+	requests := emulator.Requests()
+	if len(requests) != 2 || requests[0][2] != 0x00 {
+		t.Errorf("requests = % X, want IF_Clear then the command", requests)
+	}
+	if err := camera.Connect(context.Background()); err != nil {
+		t.Errorf("Connect() when connected = %v", err)
+	}
+	if n := len(emulator.Requests()); n != 2 {
+		t.Errorf("Connect() when connected sent %d requests", n-2)
+	}
+}
+
+func TestDialLazyClosed(t *testing.T) {
+	camera := voip.DialLazy("127.0.0.1:1", voip.Config{MaxRetries: 1, Timeout: 50 * time.Millisecond})
+	camera.Close()
+	if err := camera.Connect(context.Background()); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Connect() after Close() = %v, want net.ErrClosed", err)
+	}
+}
@@ -0,0 +1,103 @@
+package viscaoverip
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Manager is a registry of named cameras, for applications controlling a
+// fleet of them. It is safe for concurrent use.
+type Manager struct {
+	mu      sync.RWMutex
+	cameras map[string]*Camera
+}
+
+func NewManager() *Manager {
+	return &Manager{cameras: make(map[string]*Camera)}
+}
+
+// Add registers a camera under name, which must not be in use.
+func (m *Manager) Add(name string, c *Camera) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.cameras[name]; ok {
+		return fmt.Errorf("camera already exists: %s", name)
+	}
+	m.cameras[name] = c
+	return nil
+}
+
+// Get returns the camera registered under name.
+func (m *Manager) Get(name string) (*Camera, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.cameras[name]
+	return c, ok
+}
+
+// Remove unregisters the camera under name and returns it, without closing
+// it.
+func (m *Manager) Remove(name string) (*Camera, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.cameras[name]
+	delete(m.cameras, name)
+	return c, ok
+}
+
+// Names returns the names of the registered cameras, sorted.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.cameras))
+	for name := range m.cameras {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Close closes and unregisters every camera.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for name, c := range m.cameras {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		delete(m.cameras, name)
+	}
+	return errors.Join(errs...)
+}
+
+// each runs fn concurrently on the named cameras, or on every camera when
+// no name is given, and collects the results.
+func (m *Manager) each(names []string, fn func(*Camera) error) map[string]error {
+	if len(names) == 0 {
+		names = m.Names()
+	}
+	type result struct {
+		name string
+		err  error
+	}
+	ch := make(chan result)
+	for _, name := range names {
+		go func() {
+			c, ok := m.Get(name)
+			if !ok {
+				ch <- result{name, fmt.Errorf("unknown camera: %s", name)}
+				return
+			}
+			ch <- result{name, fn(c)}
+		}()
+	}
+	results := make(map[string]error, len(names))
+	for range names {
+		r := <-ch
+		results[r.name] = r.err
+	}
+	return results
+}
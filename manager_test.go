@@ -0,0 +1,43 @@
+package viscaoverip_test
+
+import (
+	"slices"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestManager(t *testing.T) {
+	m := voip.NewManager()
+	cam1, cam2 := &voip.Camera{}, &voip.Camera{}
+
+	if err := m.Add("cam2", cam2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add("cam1", cam1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add("cam1", cam2); err == nil {
+		t.Error("Add() with duplicate name: expected error")
+	}
+
+	if names := m.Names(); !slices.Equal(names, []string{"cam1", "cam2"}) {
+		t.Errorf("Names() = %v, want [cam1 cam2]", names)
+	}
+	if c, ok := m.Get("cam1"); !ok || c != cam1 {
+		t.Errorf("Get(cam1) = %p, %v, want %p, true", c, ok, cam1)
+	}
+	if c, ok := m.Remove("cam2"); !ok || c != cam2 {
+		t.Errorf("Remove(cam2) = %p, %v, want %p, true", c, ok, cam2)
+	}
+	if _, ok := m.Get("cam2"); ok {
+		t.Error("Get() after Remove(): camera still registered")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if names := m.Names(); len(names) != 0 {
+		t.Errorf("Names() after Close() = %v, want none", names)
+	}
+}
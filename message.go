@@ -0,0 +1,43 @@
+package viscaoverip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// HeaderSize is the size of the VISCA over IP header: payload type, payload
+// length and sequence number.
+const HeaderSize = 8
+
+// ReadMessage reads one VISCA over IP message, header included, from a
+// stream such as a TCP connection, on which a message may arrive over
+// several reads and several messages may arrive in one. It reads the header,
+// then as many bytes as its payload length announces. Messages with a
+// payload longer than maxPayload are an error; their payload is skipped so
+// that the stream stays in sync.
+//
+// A datagram socket returns one whole message per read, and must not be read
+// with ReadMessage.
+func ReadMessage(r io.Reader, maxPayload int) ([]byte, error) {
+	header := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if length > maxPayload {
+		if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%w: payload of %d bytes, at most %d expected", ErrReplyTruncated, length, maxPayload)
+	}
+	message := make([]byte, HeaderSize+length)
+	copy(message, header)
+	if _, err := io.ReadFull(r, message[HeaderSize:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return message, nil
+}
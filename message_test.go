@@ -0,0 +1,47 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestReadMessage(t *testing.T) {
+	first := makeResponse(1, 0x41)
+	second := makeResponse(1, 0x51)
+	stream := append(bytes.Clone(first), second...)
+
+	// One byte per read, as a stream may split messages anywhere
+	r := iotest.OneByteReader(bytes.NewReader(stream))
+	for _, want := range [][]byte{first, second} {
+		got, err := voip.ReadMessage(r, 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadMessage() = % X, want % X", got, want)
+		}
+	}
+	if _, err := voip.ReadMessage(r, 16); err != io.EOF {
+		t.Errorf("ReadMessage() at end = %v, want io.EOF", err)
+	}
+
+	// A message cut short
+	if _, err := voip.ReadMessage(bytes.NewReader(first[:10]), 16); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadMessage() of partial message = %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	// An oversized payload is skipped
+	r = bytes.NewReader(append(bytes.Clone(first), second...))
+	if _, err := voip.ReadMessage(r, 2); !errors.Is(err, voip.ErrReplyTruncated) {
+		t.Errorf("ReadMessage() of oversized payload = %v, want ErrReplyTruncated", err)
+	}
+	got, err := voip.ReadMessage(r, 16)
+	if err != nil || !bytes.Equal(got, second) {
+		t.Errorf("ReadMessage() after oversized payload = % X, %v, want % X", got, err, second)
+	}
+}
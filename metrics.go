@@ -0,0 +1,49 @@
+package viscaoverip
+
+import "time"
+
+// Names of the metrics reported to a MetricsSink.
+const (
+	// Counters, matching the fields of Metrics
+	MetricSent            = "sent"
+	MetricRetries         = "retries"
+	MetricTimeouts        = "timeouts"
+	MetricMissedResponses = "missed_responses"
+	MetricErrors          = "errors"
+	// MetricPending is a gauge of the commands awaiting their completion.
+	MetricPending = "pending"
+	// MetricExchange is the timing of each command and inquiry, from the
+	// first write to the final reply or error, retries included.
+	MetricExchange = "exchange"
+)
+
+// MetricsSink receives the metrics of a camera as they change, for
+// telemetry backends such as StatsD or InfluxDB (see Config.Metrics). The
+// callbacks run on the goroutine of the exchange, with the camera locked:
+// they must return quickly and not use the camera.
+type MetricsSink interface {
+	// Count adds delta to the counter name.
+	Count(name string, delta int)
+	// Gauge sets the gauge name to value.
+	Gauge(name string, value float64)
+	// Timing records a duration of name.
+	Timing(name string, d time.Duration)
+}
+
+func (c *Camera) count(name string, delta int) {
+	if c.Config.Metrics != nil && delta != 0 {
+		c.Config.Metrics.Count(name, delta)
+	}
+}
+
+func (c *Camera) gauge(name string, value float64) {
+	if c.Config.Metrics != nil {
+		c.Config.Metrics.Gauge(name, value)
+	}
+}
+
+func (c *Camera) timing(name string, d time.Duration) {
+	if c.Config.Metrics != nil {
+		c.Config.Metrics.Timing(name, d)
+	}
+}
@@ -0,0 +1,91 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+// recordingSink is a MetricsSink keeping the counters, gauges and timings
+// reported.
+type recordingSink struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	gauges  map[string]float64
+	timings map[string][]time.Duration
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{
+		counts:  make(map[string]int),
+		gauges:  make(map[string]float64),
+		timings: make(map[string][]time.Duration),
+	}
+}
+
+func (s *recordingSink) Count(name string, delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[name] += delta
+}
+
+func (s *recordingSink) Gauge(name string, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gauges[name] = value
+}
+
+func (s *recordingSink) Timing(name string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timings[name] = append(s.timings[name], d)
+}
+
+func TestMetricsSink(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	sink := newRecordingSink()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond, Metrics: sink}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	camera.Home()
+	camera.SendInquiry("7E 7E") // Syntax error
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	m := camera.Metrics()
+	want := map[string]int{
+		voip.MetricSent:            m.Sent,
+		voip.MetricRetries:         m.Retries,
+		voip.MetricTimeouts:        m.Timeouts,
+		voip.MetricMissedResponses: m.MissedResponses,
+		voip.MetricErrors:          m.Errors,
+	}
+	for name, n := range want {
+		if sink.counts[name] != n {
+			t.Errorf("counter %s = %d, want %d", name, sink.counts[name], n)
+		}
+	}
+	// IF_Clear, Home and the inquiry
+	if m.Sent != 3 || m.Errors != 1 {
+		t.Errorf("metrics = %+v, want 3 sent and 1 error", m)
+	}
+	if n := len(sink.timings[voip.MetricExchange]); n != m.Sent {
+		t.Errorf("%d exchange timings, want %d", n, m.Sent)
+	}
+	if g, ok := sink.gauges[voip.MetricPending]; !ok || g != 0 {
+		t.Errorf("pending gauge = %v, %v, want 0", g, ok)
+	}
+}
@@ -0,0 +1,168 @@
+package mqttbridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Just enough of MQTT 3.1.1 for the bridge: QoS 0 publish and subscribe.
+
+const (
+	packetConnect     = 1
+	packetConnAck     = 2
+	packetPublish     = 3
+	packetSubscribe   = 8
+	packetPingReq     = 12
+	maxRemainingBytes = 4
+)
+
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu sync.Mutex // Serializes writes
+}
+
+func newClient(conn net.Conn) *client {
+	return &client{conn: conn, r: bufio.NewReader(conn)}
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func (c *client) writePacket(header byte, body []byte) error {
+	packet := []byte{header}
+	// Remaining length, 7 bits per byte, least significant first
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *client) readPacket() (byte, []byte, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == maxRemainingBytes {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// connect performs the CONNECT/CONNACK handshake with a clean session.
+func (c *client) connect(clientID, username, password string, keepAlive time.Duration) error {
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive/time.Second))
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+	}
+	if password != "" {
+		body = appendString(body, password)
+	}
+	if err := c.writePacket(packetConnect<<4, body); err != nil {
+		return err
+	}
+
+	header, body, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if header>>4 != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("unexpected packet type %d, want CONNACK", header>>4)
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("connection refused: return code %d", body[1])
+	}
+	return nil
+}
+
+func (c *client) subscribe(packetID uint16, topics ...string) error {
+	body := binary.BigEndian.AppendUint16(nil, packetID)
+	for _, topic := range topics {
+		body = appendString(body, topic)
+		body = append(body, 0) // QoS 0
+	}
+	return c.writePacket(packetSubscribe<<4|0x02, body)
+}
+
+func (c *client) publish(topic string, payload []byte, retain bool) error {
+	header := byte(packetPublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	return c.writePacket(header, append(body, payload...))
+}
+
+func (c *client) ping() error {
+	return c.writePacket(packetPingReq<<4, nil)
+}
+
+// parsePublish returns the topic and payload of a PUBLISH packet.
+func parsePublish(header byte, body []byte) (string, []byte, error) {
+	if len(body) < 2 {
+		return "", nil, errors.New("short PUBLISH packet")
+	}
+	n := int(binary.BigEndian.Uint16(body))
+	body = body[2:]
+	if len(body) < n {
+		return "", nil, errors.New("short PUBLISH topic")
+	}
+	topic, body := string(body[:n]), body[n:]
+	if qos := header >> 1 & 0x03; qos > 0 {
+		// We only subscribe at QoS 0, skip the packet id anyway
+		if len(body) < 2 {
+			return "", nil, errors.New("short PUBLISH packet id")
+		}
+		body = body[2:]
+	}
+	return topic, body, nil
+}
@@ -0,0 +1,280 @@
+// Package mqttbridge connects the cameras of a Manager to an MQTT broker,
+// for building automation and show control systems.
+//
+// The bridge subscribes to command topics, where name is the camera name in
+// the Manager:
+//
+//	camera/{name}/ptz     {"pan": 12, "tilt": -5, "zoom": 0}
+//	camera/{name}/preset  3
+//	camera/{name}/home
+//	camera/{name}/stop
+//
+// Speeds are signed as in Camera.PanTilt and Camera.Zoom. It publishes
+// retained telemetry topics:
+//
+//	camera/{name}/state     connected
+//	camera/{name}/position  {"pan": 0, "tilt": 0, "zoom": 0}
+//
+// and failed commands to camera/{name}/error. Only QoS 0 is used.
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+const (
+	DefaultPrefix       = "camera"
+	DefaultClientID     = "visca-over-ip"
+	DefaultKeepAlive    = 30 * time.Second
+	DefaultPollInterval = 500 * time.Millisecond
+
+	// commandQueueSize is the number of commands a camera may have waiting
+	// before the read loop waits for them.
+	commandQueueSize = 16
+)
+
+type Config struct {
+	// Broker is the host:port of the MQTT broker.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// Prefix is the first levels of every topic, e.g. "studio/cameras".
+	// Defaults to DefaultPrefix.
+	Prefix       string
+	KeepAlive    time.Duration
+	PollInterval time.Duration
+}
+
+type Bridge struct {
+	manager *voip.Manager
+	cfg     Config
+}
+
+func New(m *voip.Manager, cfg Config) *Bridge {
+	if cfg.ClientID == "" {
+		cfg.ClientID = DefaultClientID
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+	if cfg.KeepAlive == 0 {
+		cfg.KeepAlive = DefaultKeepAlive
+	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	return &Bridge{manager: m, cfg: cfg}
+}
+
+// command is a command received for a camera.
+type command struct {
+	name    string
+	payload []byte
+}
+
+type ptzMessage struct {
+	Pan  int `json:"pan"`
+	Tilt int `json:"tilt"`
+	Zoom int `json:"zoom"`
+}
+
+// Run connects to the broker and bridges until ctx is done or the
+// connection fails. Telemetry is published for the cameras registered when
+// Run is called; commands are accepted for any registered camera.
+func (b *Bridge) Run(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", b.cfg.Broker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stopClose := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopClose()
+
+	c := newClient(conn)
+	if err := c.connect(b.cfg.ClientID, b.cfg.Username, b.cfg.Password, b.cfg.KeepAlive); err != nil {
+		return fmt.Errorf("mqtt connect: %w", err)
+	}
+	var topics []string
+	for _, command := range []string{"ptz", "preset", "home", "stop"} {
+		topics = append(topics, b.cfg.Prefix+"/+/"+command)
+	}
+	if err := c.subscribe(1, topics...); err != nil {
+		return fmt.Errorf("mqtt subscribe: %w", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(done)
+
+	for _, name := range b.manager.Names() {
+		camera, ok := b.manager.Get(name)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.telemetry(c, name, camera, done)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(b.cfg.KeepAlive / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if c.ping() != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Each camera runs its commands in order on its own goroutine, so that
+	// a slow camera neither delays the others nor the keep-alive replies
+	queues := make(map[string]chan command)
+	for {
+		header, body, err := c.readPacket()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if header>>4 != packetPublish {
+			continue
+		}
+		topic, payload, err := parsePublish(header, body)
+		if err != nil {
+			return err
+		}
+		name, cmd, ok := b.parseTopic(topic)
+		if !ok {
+			continue
+		}
+		if _, ok := b.manager.Get(name); !ok {
+			continue
+		}
+		queue := queues[name]
+		if queue == nil {
+			queue = make(chan command, commandQueueSize)
+			queues[name] = queue
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.runCommands(c, name, queue, done)
+			}()
+		}
+		select {
+		case queue <- command{name: cmd, payload: payload}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// parseTopic returns the camera name and command of a command topic.
+func (b *Bridge) parseTopic(topic string) (name, command string, ok bool) {
+	rest, ok := strings.CutPrefix(topic, b.cfg.Prefix+"/")
+	if !ok {
+		return "", "", false
+	}
+	name, command, ok = strings.Cut(rest, "/")
+	if !ok || name == "" || strings.Contains(command, "/") {
+		return "", "", false
+	}
+	return name, command, true
+}
+
+// runCommands handles the commands queued for the camera under name until
+// done.
+func (b *Bridge) runCommands(c *client, name string, queue chan command, done chan struct{}) {
+	for {
+		select {
+		case cmd := <-queue:
+			b.handle(c, name, cmd.name, cmd.payload)
+		case <-done:
+			return
+		}
+	}
+}
+
+// telemetry publishes the state and position of a camera until done.
+func (b *Bridge) telemetry(c *client, name string, camera *voip.Camera, done chan struct{}) {
+	states := make(chan voip.State, 8)
+	unsubscribeState := camera.OnStateChange(func(from, to voip.State) {
+		select {
+		case states <- to:
+		default:
+		}
+	})
+	defer unsubscribeState()
+
+	positions, unsubscribePosition := voip.NewPoller(camera, b.cfg.PollInterval).Subscribe()
+	defer unsubscribePosition()
+
+	prefix := b.cfg.Prefix + "/" + name + "/"
+	c.publish(prefix+"state", []byte(camera.State().String()), true)
+	for {
+		select {
+		case state := <-states:
+			c.publish(prefix+"state", []byte(state.String()), true)
+		case pos := <-positions:
+			payload, _ := json.Marshal(ptzMessage{Pan: pos.Pan, Tilt: pos.Tilt, Zoom: pos.Zoom})
+			c.publish(prefix+"position", payload, true)
+		case <-done:
+			return
+		}
+	}
+}
+
+// handle executes a command received for the camera under name, publishing
+// any error.
+func (b *Bridge) handle(c *client, name, command string, payload []byte) {
+	camera, ok := b.manager.Get(name)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch command {
+	case "ptz":
+		var msg ptzMessage
+		if err = json.Unmarshal(payload, &msg); err == nil {
+			err = errors.Join(camera.PanTilt(msg.Pan, msg.Tilt), camera.Zoom(msg.Zoom))
+		}
+	case "preset":
+		var preset int
+		if preset, err = strconv.Atoi(strings.TrimSpace(string(payload))); err == nil {
+			err = camera.RecallPreset(preset)
+		}
+	case "home":
+		err = camera.Home()
+	case "stop":
+		err = errors.Join(camera.PanTiltStop(), camera.ZoomStop())
+	default:
+		return
+	}
+
+	if err != nil {
+		c.publish(b.cfg.Prefix+"/"+name+"/error", []byte(fmt.Sprintf("%s: %v", command, err)), false)
+	}
+}
@@ -0,0 +1,209 @@
+package mqttbridge_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/mqttbridge"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+// broker is a fake MQTT broker accepting a single client.
+type broker struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (b *broker) read(t *testing.T) (byte, []byte) {
+	t.Helper()
+	b.conn.SetReadDeadline(time.Now().Add(time.Second))
+	header, err := b.r.ReadByte()
+	if err != nil {
+		t.Fatal(err)
+	}
+	length, multiplier := 0, 1
+	for {
+		c, _ := b.r.ReadByte()
+		length += int(c&0x7F) * multiplier
+		multiplier *= 128
+		if c&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(b.r, body); err != nil {
+		t.Fatal(err)
+	}
+	return header, body
+}
+
+func (b *broker) write(t *testing.T, header byte, body []byte) {
+	t.Helper()
+	packet := append([]byte{header, byte(len(body))}, body...)
+	if _, err := b.conn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (b *broker) publish(t *testing.T, topic, payload string) {
+	t.Helper()
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(body, topic...)
+	b.write(t, 0x30, append(body, payload...))
+}
+
+// next returns the payload of the next PUBLISH to topic.
+func (b *broker) next(t *testing.T, topic string) string {
+	t.Helper()
+	for {
+		header, body := b.read(t)
+		if header>>4 != 3 {
+			continue
+		}
+		n := int(binary.BigEndian.Uint16(body))
+		if string(body[2:2+n]) == topic {
+			return string(body[2+n:])
+		}
+	}
+}
+
+// startBridge runs a bridge of an emulated camera named cam1 with cfg, and
+// returns the broker it connected to once CONNECT is acknowledged, and a
+// function stopping the bridge and returning the error of Run.
+func startBridge(t *testing.T, cfg mqttbridge.Config) (*broker, *viscatest.Emulator, func() error) {
+	t.Helper()
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { emulator.Close() })
+	emulator.SetState(viscatest.State{Power: true, Pan: 42})
+
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	t.Cleanup(func() { m.Close() })
+	m.Add("cam1", camera)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg.Broker = ln.Addr().String()
+	bridge := mqttbridge.New(m, cfg)
+	errc := make(chan error, 1)
+	go func() { errc <- bridge.Run(ctx) }()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	b := &broker{conn: conn, r: bufio.NewReader(conn)}
+
+	header, body := b.read(t)
+	if header != 0x10 || !strings.Contains(string(body), cfg.ClientID) {
+		t.Fatalf("CONNECT = %02x %q", header, body)
+	}
+	b.write(t, 0x20, []byte{0, 0})
+
+	return b, emulator, func() error {
+		cancel()
+		return <-errc
+	}
+}
+
+func TestBridge(t *testing.T) {
+	b, emulator, stop := startBridge(t, mqttbridge.Config{
+		ClientID:     "test",
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	header, body := b.read(t)
+	if header != 0x82 {
+		t.Fatalf("header = %02x, want SUBSCRIBE", header)
+	}
+	for _, topic := range []string{"camera/+/ptz", "camera/+/preset", "camera/+/home", "camera/+/stop"} {
+		if !strings.Contains(string(body), topic) {
+			t.Errorf("SUBSCRIBE missing %s", topic)
+		}
+	}
+	b.write(t, 0x90, []byte{0, 1, 0, 0, 0, 0})
+
+	if got := b.next(t, "camera/cam1/state"); got != "connected" {
+		t.Errorf("state = %q, want connected", got)
+	}
+	var pos struct{ Pan int }
+	if err := json.Unmarshal([]byte(b.next(t, "camera/cam1/position")), &pos); err != nil || pos.Pan != 42 {
+		t.Errorf("position pan = %d (%v), want 42", pos.Pan, err)
+	}
+
+	b.publish(t, "camera/cam1/preset", "5")
+	b.publish(t, "camera/cam1/ptz", `{"pan": 99}`)
+	if got := b.next(t, "camera/cam1/error"); !strings.Contains(got, "invalid argument") {
+		t.Errorf("error = %q", got)
+	}
+	recalled := false
+	for _, req := range emulator.Requests() {
+		if strings.Contains(string(req), "\x81\x01\x04\x3F\x02\x05\xFF") {
+			recalled = true
+		}
+	}
+	if !recalled {
+		t.Error("preset 5 not recalled")
+	}
+
+	if err := stop(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+}
+
+func TestBridgePrefix(t *testing.T) {
+	b, emulator, stop := startBridge(t, mqttbridge.Config{
+		ClientID: "test",
+		Prefix:   "studio/cameras",
+	})
+	defer stop()
+
+	header, body := b.read(t)
+	if header != 0x82 || !strings.Contains(string(body), "studio/cameras/+/home") {
+		t.Fatalf("SUBSCRIBE = %02x %q", header, body)
+	}
+	b.write(t, 0x90, []byte{0, 1, 0, 0, 0, 0})
+
+	home := "\x81\x01\x06\x04\xFF"
+	b.publish(t, "camera/cam1/home", "")
+	b.publish(t, "studio/cameras/cam1/extra/home", "")
+	b.publish(t, "studio/cameras/cam1/home", "")
+	deadline := time.Now().Add(time.Second)
+	for {
+		n := 0
+		for _, req := range emulator.Requests() {
+			if string(req) == home {
+				n++
+			}
+		}
+		if n == 1 {
+			break
+		}
+		if n > 1 || time.Now().After(deadline) {
+			t.Fatalf("%d homes sent, want 1", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
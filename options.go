@@ -0,0 +1,77 @@
+package viscaoverip
+
+import "time"
+
+// SendOption overrides the Config of the Camera for a single call, e.g. to
+// let a preset recall take seconds while a joystick update fails fast.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	timeout           time.Duration
+	completionTimeout time.Duration
+	maxRetries        int
+	noCache           bool
+	untilACK          bool // Set by SendCommandAsync
+	anyReply          bool // Set by SendRaw for non-VISCA payload types
+}
+
+// WithTimeout sets the reply timeout of each attempt.
+func WithTimeout(d time.Duration) SendOption {
+	return func(o *sendOptions) { o.timeout = d }
+}
+
+// WithCompletionTimeout sets the time to wait for the Completion of an
+// acknowledged command.
+func WithCompletionTimeout(d time.Duration) SendOption {
+	return func(o *sendOptions) { o.completionTimeout = d }
+}
+
+// WithRetries sets the number of attempts before giving up.
+func WithRetries(n int) SendOption {
+	return func(o *sendOptions) { o.maxRetries = n }
+}
+
+// WithNoRetry makes a single attempt, for updates that are stale by the time
+// a retry would be sent.
+func WithNoRetry() SendOption {
+	return WithRetries(1)
+}
+
+// WithNoCache makes SendInquiry bypass the inquiry cache: the reply is
+// neither read from nor written to it.
+func WithNoCache() SendOption {
+	return func(o *sendOptions) { o.noCache = true }
+}
+
+// sendOptions returns the Config of the camera overridden by opts.
+func (c *Camera) sendOptions(opts []SendOption) sendOptions {
+	return c.options(c.Config.Timeout, c.Config.MaxRetries, opts)
+}
+
+// inquiryOptions is sendOptions for an inquiry, which uses the inquiry
+// timeout and retries of the Config if set.
+func (c *Camera) inquiryOptions(opts []SendOption) sendOptions {
+	timeout, maxRetries := c.Config.Timeout, c.Config.MaxRetries
+	if c.Config.InquiryTimeout > 0 {
+		timeout = c.Config.InquiryTimeout
+	}
+	if c.Config.InquiryMaxRetries > 0 {
+		maxRetries = c.Config.InquiryMaxRetries
+	}
+	return c.options(timeout, maxRetries, opts)
+}
+
+func (c *Camera) options(timeout time.Duration, maxRetries int, opts []SendOption) sendOptions {
+	o := sendOptions{
+		timeout:           timeout,
+		completionTimeout: c.Config.CompletionTimeout,
+		maxRetries:        maxRetries,
+	}
+	if o.completionTimeout == 0 {
+		o.completionTimeout = DefaultCompletionTimeout
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
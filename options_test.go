@@ -0,0 +1,133 @@
+package viscaoverip_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestSendOptions(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var homes atomic.Int32
+	server.setHandler(func(msg []byte) [][]byte {
+		if msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		payload := msg[8:]
+		switch {
+		case len(payload) == 5 && payload[2] == 0x06 && payload[3] == 0x04:
+			// Home is never answered
+			homes.Add(1)
+			return nil
+		case len(payload) == 6 && payload[2] == 0x04 && payload[3] == 0x3F:
+			// Preset recall is slow to complete
+			time.Sleep(60 * time.Millisecond)
+		}
+		return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := voip.Config{MaxRetries: 4, Timeout: 20 * time.Millisecond}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	tests := []struct {
+		name string
+		opts []voip.SendOption
+		want int32
+	}{
+		{"Config", nil, 4},
+		{"WithRetries", []voip.SendOption{voip.WithRetries(2)}, 2},
+		{"WithNoRetry", []voip.SendOption{voip.WithNoRetry()}, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			homes.Store(0)
+			err := camera.SendCommand("06 04", tc.opts...)
+			if !errors.Is(err, voip.ErrNotResponsive) {
+				t.Errorf("SendCommand() = %v, want ErrNotResponsive", err)
+			}
+			if got := homes.Load(); got != tc.want {
+				t.Errorf("sent %d times, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// The default timeout is too short for the slow recall
+	if err := camera.SendCommand("04 3F 02 01", voip.WithTimeout(200*time.Millisecond), voip.WithNoRetry()); err != nil {
+		t.Errorf("SendCommand() with long timeout = %v", err)
+	}
+}
+
+func TestInquiryTimeout(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var inquiries, commands atomic.Int32
+	server.setHandler(func(msg []byte) [][]byte {
+		if msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		switch {
+		case msg[1] == 0x10:
+			inquiries.Add(1)
+			return nil
+		case msg[10] == 0x06:
+			commands.Add(1)
+			return nil
+		}
+		return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond, InquiryTimeout: 10 * time.Millisecond, InquiryMaxRetries: 1}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	start := time.Now()
+	if _, err := camera.SendInquiry("04 00"); !errors.Is(err, voip.ErrNotResponsive) {
+		t.Errorf("SendInquiry() = %v, want ErrNotResponsive", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("inquiry failed after %v, want the inquiry timeout", elapsed)
+	}
+	if n := inquiries.Load(); n != 1 {
+		t.Errorf("inquiry sent %d times, want 1", n)
+	}
+
+	if err := camera.SendCommand("06 04"); !errors.Is(err, voip.ErrNotResponsive) {
+		t.Errorf("SendCommand() = %v, want ErrNotResponsive", err)
+	}
+	if n := commands.Load(); n != 3 {
+		t.Errorf("command sent %d times, want 3", n)
+	}
+}
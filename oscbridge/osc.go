@@ -0,0 +1,102 @@
+package oscbridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// message is a decoded OSC message. Arguments are int32, float32 or string.
+type message struct {
+	address string
+	args    []any
+}
+
+// parsePacket decodes an OSC packet, flattening bundles into their
+// messages. Time tags are ignored: messages apply on receipt.
+func parsePacket(b []byte) ([]message, error) {
+	if bytes.HasPrefix(b, []byte("#bundle\x00")) {
+		if len(b) < 16 {
+			return nil, errors.New("short bundle")
+		}
+		var msgs []message
+		b = b[16:]
+		for len(b) > 0 {
+			if len(b) < 4 {
+				return nil, errors.New("short bundle element size")
+			}
+			n := binary.BigEndian.Uint32(b)
+			b = b[4:]
+			if uint32(len(b)) < n {
+				return nil, errors.New("short bundle element")
+			}
+			elem, err := parsePacket(b[:n])
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, elem...)
+			b = b[n:]
+		}
+		return msgs, nil
+	}
+
+	address, b, err := readString(b)
+	if err != nil {
+		return nil, err
+	}
+	msg := message{address: address}
+	if len(b) == 0 {
+		// Old implementations omit the type tag string
+		return []message{msg}, nil
+	}
+	tags, b, err := readString(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 || tags[0] != ',' {
+		return nil, fmt.Errorf("invalid type tag string: %q", tags)
+	}
+	for _, tag := range tags[1:] {
+		switch tag {
+		case 'i', 'f':
+			if len(b) < 4 {
+				return nil, errors.New("short argument")
+			}
+			v := binary.BigEndian.Uint32(b)
+			b = b[4:]
+			if tag == 'i' {
+				msg.args = append(msg.args, int32(v))
+			} else {
+				msg.args = append(msg.args, math.Float32frombits(v))
+			}
+		case 's':
+			var s string
+			if s, b, err = readString(b); err != nil {
+				return nil, err
+			}
+			msg.args = append(msg.args, s)
+		case 'T':
+			msg.args = append(msg.args, int32(1))
+		case 'F', 'N':
+			msg.args = append(msg.args, int32(0))
+		default:
+			return nil, fmt.Errorf("unsupported argument type: %c", tag)
+		}
+	}
+	return []message{msg}, nil
+}
+
+// readString reads a null terminated string padded to 4 bytes.
+func readString(b []byte) (string, []byte, error) {
+	n := bytes.IndexByte(b, 0)
+	if n < 0 {
+		return "", nil, errors.New("unterminated string")
+	}
+	padded := (n + 4) &^ 3
+	if padded > len(b) {
+		padded = len(b)
+	}
+	return string(b[:n]), b[padded:], nil
+}
@@ -0,0 +1,155 @@
+// Package oscbridge maps Open Sound Control messages to VISCA commands,
+// for lighting and sound consoles and control surfaces such as TouchOSC.
+//
+// Addresses name the camera as registered in the Manager:
+//
+//	/camera/{name}/pan     speed
+//	/camera/{name}/tilt    speed
+//	/camera/{name}/zoom    speed
+//	/camera/{name}/preset  number
+//	/camera/{name}/home
+//	/camera/{name}/stop
+//
+// An integer speed is used as is, signed as in Camera.PanTilt and
+// Camera.Zoom. A float speed is a fraction of the maximum speed between -1
+// and 1, as sent by faders and XY pads. Pan and tilt are independent
+// messages: the bridge remembers the speed of the other axis.
+package oscbridge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+type Config struct {
+	// ErrorHandler, if set, is called with the errors Serve handles by
+	// itself, e.g. to log them.
+	ErrorHandler func(error)
+}
+
+type Bridge struct {
+	manager *voip.Manager
+	cfg     Config
+	axes    map[*voip.Camera]*axes
+}
+
+// axes holds the last pan and tilt speeds sent to a camera.
+type axes struct {
+	pan, tilt int
+}
+
+func New(m *voip.Manager, cfg Config) *Bridge {
+	return &Bridge{
+		manager: m,
+		cfg:     cfg,
+		axes:    make(map[*voip.Camera]*axes),
+	}
+}
+
+// Serve handles the OSC packets received on conn until reading from it
+// fails, e.g. after it is closed. Messages are handled in the order
+// received.
+func (b *Bridge) Serve(conn net.PacketConn) error {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		msgs, err := parsePacket(buf[:n])
+		if err != nil {
+			b.report(fmt.Errorf("invalid OSC packet: %w", err))
+			continue
+		}
+		for _, msg := range msgs {
+			if err := b.handle(msg); err != nil {
+				b.report(fmt.Errorf("%s: %w", msg.address, err))
+			}
+		}
+	}
+}
+
+func (b *Bridge) report(err error) {
+	if b.cfg.ErrorHandler != nil {
+		b.cfg.ErrorHandler(err)
+	}
+}
+
+func (b *Bridge) handle(msg message) error {
+	levels := strings.Split(msg.address, "/")
+	if len(levels) != 4 || levels[0] != "" || levels[1] != "camera" {
+		return errors.New("unknown address")
+	}
+	name, command := levels[2], levels[3]
+	camera, ok := b.manager.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown camera: %s", name)
+	}
+	a := b.axes[camera]
+	if a == nil {
+		a = &axes{}
+		b.axes[camera] = a
+	}
+
+	switch command {
+	case "pan", "tilt", "zoom", "preset":
+		if len(msg.args) == 0 {
+			return fmt.Errorf("%w: missing argument", voip.ErrInvalidArgument)
+		}
+	}
+	switch command {
+	case "pan":
+		pan, err := speed(msg.args[0], voip.MaxPanSpeed)
+		if err != nil {
+			return err
+		}
+		a.pan = pan
+		return camera.PanTilt(a.pan, a.tilt)
+	case "tilt":
+		tilt, err := speed(msg.args[0], voip.MaxTiltSpeed)
+		if err != nil {
+			return err
+		}
+		a.tilt = tilt
+		return camera.PanTilt(a.pan, a.tilt)
+	case "zoom":
+		zoom, err := speed(msg.args[0], voip.MaxZoomSpeed)
+		if err != nil {
+			return err
+		}
+		return camera.Zoom(zoom)
+	case "preset":
+		preset, ok := msg.args[0].(int32)
+		if !ok {
+			return fmt.Errorf("%w: preset must be an integer", voip.ErrInvalidArgument)
+		}
+		return camera.RecallPreset(int(preset))
+	case "home":
+		return camera.Home()
+	case "stop":
+		*a = axes{}
+		return errors.Join(camera.PanTiltStop(), camera.ZoomStop())
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// speed converts an OSC argument to a signed speed up to maxSpeed.
+func speed(arg any, maxSpeed int) (int, error) {
+	switch v := arg.(type) {
+	case int32:
+		return int(v), nil
+	case float32:
+		if v < -1 || v > 1 {
+			return 0, fmt.Errorf("%w: speed fraction must be between -1 and 1: %g", voip.ErrInvalidArgument, v)
+		}
+		return int(math.Round(float64(v) * float64(maxSpeed))), nil
+	default:
+		return 0, fmt.Errorf("%w: speed must be a number", voip.ErrInvalidArgument)
+	}
+}
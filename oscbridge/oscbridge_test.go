@@ -0,0 +1,135 @@
+package oscbridge_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/oscbridge"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func oscString(s string) []byte {
+	b := append([]byte(s), 0)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func oscMessage(address string, args ...any) []byte {
+	tags := ","
+	var data []byte
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case int:
+			tags += "i"
+			data = binary.BigEndian.AppendUint32(data, uint32(int32(v)))
+		case float32:
+			tags += "f"
+			data = binary.BigEndian.AppendUint32(data, math.Float32bits(v))
+		}
+	}
+	msg := append(oscString(address), oscString(tags)...)
+	return append(msg, data...)
+}
+
+func oscBundle(msgs ...[]byte) []byte {
+	b := append(oscString("#bundle"), 0, 0, 0, 0, 0, 0, 0, 1)
+	for _, msg := range msgs {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(msg)))
+		b = append(b, msg...)
+	}
+	return b
+}
+
+func TestBridge(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	m.Add("1", camera)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	errs := make(chan error, 10)
+	handler := func(err error) { errs <- err }
+	go oscbridge.New(m, oscbridge.Config{ErrorHandler: handler}).Serve(conn)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	packets := [][]byte{
+		oscBundle(
+			oscMessage("/camera/1/pan", float32(0.5)),
+			oscMessage("/camera/1/tilt", -3),
+		),
+		oscMessage("/camera/2/pan", 1),  // Unknown camera
+		oscMessage("/camera/1/pan", 99), // Out of range
+		oscMessage("/camera/1/preset", 2),
+		oscMessage("/camera/1/stop"),
+	}
+	for _, p := range packets {
+		if _, err := client.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := [][]byte{
+		{0x81, 0x01, 0x00, 0x01, 0xFF}, // IF_Clear of Dial
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x01, 0x02, 0x03, 0xFF},
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x03, 0x02, 0x02, 0xFF},
+		{0x81, 0x01, 0x04, 0x3F, 0x02, 0x02, 0xFF},
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF},
+		{0x81, 0x01, 0x04, 0x07, 0x00, 0xFF},
+	}
+	var got [][]byte
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		got = emulator.Requests()
+		if len(got) >= len(want) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d: % X", len(got), len(want), got)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+	// The later messages were handled, so the errors were reported
+	for _, address := range []string{"/camera/2/pan", "/camera/1/pan"} {
+		select {
+		case err := <-errs:
+			if !strings.HasPrefix(err.Error(), address+": ") {
+				t.Errorf("handled error %q, want one of %s", err, address)
+			}
+		default:
+			t.Errorf("no error handled for %s", address)
+		}
+	}
+}
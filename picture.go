@@ -0,0 +1,103 @@
+package viscaoverip
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PictureEffect is a picture effect (CAM_PictureEffect).
+type PictureEffect byte
+
+const (
+	PictureEffectOff           PictureEffect = 0x00
+	PictureEffectNegative      PictureEffect = 0x02
+	PictureEffectBlackAndWhite PictureEffect = 0x04
+)
+
+// SetFreeze freezes the picture on its current frame, or releases it
+// (CAM_Freeze).
+func (c *Camera) SetFreeze(on bool) error {
+	return c.setOnOff("04 62", on)
+}
+
+// Freeze reports whether the picture is frozen.
+func (c *Camera) Freeze() (bool, error) {
+	return c.inquireOnOff("04 62")
+}
+
+// FreezeDuring freezes the picture while fn runs, e.g. a preset recall, so
+// that the audience does not see the move, and releases it after fn even if
+// fn fails.
+func (c *Camera) FreezeDuring(fn func() error) error {
+	if err := c.SetFreeze(true); err != nil {
+		return fmt.Errorf("freeze: %w", err)
+	}
+	err := fn()
+	if uerr := c.SetFreeze(false); uerr != nil {
+		err = errors.Join(err, fmt.Errorf("release freeze: %w", uerr))
+	}
+	return err
+}
+
+// RecallPresetFrozen recalls a preset with the picture frozen on the frame
+// before the move, until the move is complete.
+func (c *Camera) RecallPresetFrozen(preset int) error {
+	if err := checkRange("preset", preset, 0, MaxPreset); err != nil {
+		return err
+	}
+	return c.FreezeDuring(func() error { return c.RecallPreset(preset) })
+}
+
+// SetPictureEffect selects a picture effect.
+func (c *Camera) SetPictureEffect(effect PictureEffect) error {
+	switch effect {
+	case PictureEffectOff, PictureEffectNegative, PictureEffectBlackAndWhite:
+	default:
+		return fmt.Errorf("%w: unknown picture effect: %#x", ErrInvalidArgument, byte(effect))
+	}
+	return c.SendCommand(fmt.Sprintf("04 63 %02X", byte(effect)))
+}
+
+// PictureEffect inquires the picture effect.
+func (c *Camera) PictureEffect() (PictureEffect, error) {
+	data, err := c.SendInquiry("04 63")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return PictureEffect(data[0]), nil
+}
+
+// MaxAperture is the largest aperture (sharpness) level.
+const MaxAperture = 0x0F
+
+// ApertureUp raises the aperture (sharpness) by one level.
+func (c *Camera) ApertureUp() error {
+	return c.SendCommand("04 02 02")
+}
+
+// ApertureDown lowers the aperture by one level.
+func (c *Camera) ApertureDown() error {
+	return c.SendCommand("04 02 03")
+}
+
+// ApertureReset sets the aperture back to its default level.
+func (c *Camera) ApertureReset() error {
+	return c.SendCommand("04 02 00")
+}
+
+// SetAperture sets the aperture, from 0 to MaxAperture (CAM_Aperture
+// Direct).
+func (c *Camera) SetAperture(level int) error {
+	if err := checkRange("aperture", level, 0, MaxAperture); err != nil {
+		return err
+	}
+	return c.setDirect(0x42, level)
+}
+
+// Aperture inquires the aperture level.
+func (c *Camera) Aperture() (int, error) {
+	return c.inquireDirect(0x42)
+}
@@ -0,0 +1,80 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestPictureEffect(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.SetPictureEffect(voip.PictureEffectBlackAndWhite); err != nil {
+		t.Fatal(err)
+	}
+	if effect, err := camera.PictureEffect(); err != nil || effect != voip.PictureEffectBlackAndWhite {
+		t.Errorf("PictureEffect() = %#x, %v, want black and white", effect, err)
+	}
+	if err := camera.SetPictureEffect(0x01); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetPictureEffect(0x01) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRecallPresetFrozen(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	if err := camera.RecallPresetFrozen(4); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]byte{
+		{0x81, 0x01, 0x04, 0x62, 0x02, 0xFF},
+		{0x81, 0x01, 0x04, 0x3F, 0x02, 0x04, 0xFF},
+		{0x81, 0x01, 0x04, 0x62, 0x03, 0xFF},
+	}
+	got := emulator.Requests()[1:] // After IF_Clear
+	if len(got) != len(want) {
+		t.Fatalf("requests = % X, want % X", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+	if frozen, err := camera.Freeze(); err != nil || frozen {
+		t.Errorf("Freeze() = %v, %v, want false", frozen, err)
+	}
+
+	// The freeze is released when fn fails
+	errMove := errors.New("move failed")
+	if err := camera.FreezeDuring(func() error { return errMove }); !errors.Is(err, errMove) {
+		t.Errorf("FreezeDuring() = %v, want %v", err, errMove)
+	}
+	if frozen, err := camera.Freeze(); err != nil || frozen {
+		t.Errorf("Freeze() after a failure = %v, %v, want false", frozen, err)
+	}
+}
+
+func TestAperture(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.SetAperture(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.ApertureDown(); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := camera.Aperture(); err != nil || v != 9 {
+		t.Errorf("Aperture() = %d, %v, want 9", v, err)
+	}
+	if err := camera.ApertureUp(); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := camera.Aperture(); err != nil || v != 10 {
+		t.Errorf("Aperture() = %d, %v, want 10", v, err)
+	}
+	if err := camera.SetAperture(voip.MaxAperture + 1); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetAperture() out of range = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,109 @@
+package viscaoverip
+
+import (
+	"sync"
+	"time"
+)
+
+// Poller periodically inquires the position of a camera and delivers the
+// changes to its subscribers. It only polls while it has subscribers.
+type Poller struct {
+	camera   *Camera
+	interval time.Duration
+
+	mu     sync.Mutex
+	subs   map[int]chan Position
+	nextID int
+	last   Position
+	known  bool // last holds a polled position
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewPoller(c *Camera, interval time.Duration) *Poller {
+	return &Poller{
+		camera:   c,
+		interval: interval,
+		subs:     make(map[int]chan Position),
+	}
+}
+
+// Subscribe returns a channel receiving the position whenever it changes,
+// starting with the current one, and a function to unsubscribe. A slow
+// subscriber only misses intermediate positions, never the latest.
+func (p *Poller) Subscribe() (<-chan Position, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan Position, 1)
+	id := p.nextID
+	p.nextID++
+	p.subs[id] = ch
+	if p.known {
+		ch <- p.last
+	}
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.run(p.stop, p.done)
+	}
+
+	return ch, func() {
+		p.mu.Lock()
+		if _, ok := p.subs[id]; !ok {
+			p.mu.Unlock()
+			return
+		}
+		delete(p.subs, id)
+		var stop, done chan struct{}
+		if len(p.subs) == 0 {
+			stop, done = p.stop, p.done
+			p.stop, p.done = nil, nil
+			p.known = false
+		}
+		p.mu.Unlock()
+
+		if stop != nil {
+			close(stop)
+			<-done
+		}
+	}
+}
+
+func (p *Poller) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		pos, err := p.camera.Position()
+		if err != nil {
+			p.camera.ReportError("position poll", err)
+		} else {
+			p.publish(pos)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) publish(pos Position) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.known && pos == p.last {
+		return
+	}
+	p.last, p.known = pos, true
+	for _, ch := range p.subs {
+		// Replace a position the subscriber has not received yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- pos
+	}
+}
@@ -0,0 +1,91 @@
+package viscaoverip
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	PanTiltPositionInquiry = "06 12"
+	ZoomPositionInquiry    = "04 47"
+)
+
+// Position is the pan-tilt and zoom position of the camera, in VISCA
+// position units.
+type Position struct {
+	Pan  int `json:"pan"`
+	Tilt int `json:"tilt"`
+	Zoom int `json:"zoom"`
+}
+
+// decodeNibbles decodes a value spread over bytes of one nibble each
+// (0p 0q 0r 0s), as a two's complement of 4*len(b) bits if signed.
+func decodeNibbles(b []byte, signed bool) int {
+	v := 0
+	for _, n := range b {
+		v = v<<4 | int(n&0x0F)
+	}
+	bits := 4 * len(b)
+	if signed && v >= 1<<(bits-1) {
+		v -= 1 << bits
+	}
+	return v
+}
+
+// encodeNibbles returns the hex payload of v spread over n bytes of one
+// nibble each, in two's complement if negative.
+func encodeNibbles(v, n int) string {
+	b := make([]byte, 0, 3*n)
+	for i := n - 1; i >= 0; i-- {
+		b = fmt.Appendf(b, "0%X ", v>>(4*i)&0x0F)
+	}
+	return string(b[:len(b)-1])
+}
+
+// PanTiltPosition inquires the pan and tilt position. Both the 4 and 5 nibble
+// pan encodings are supported. The position is never taken from the inquiry
+// cache.
+func (c *Camera) PanTiltPosition() (pan, tilt int, err error) {
+	data, err := c.SendInquiry(PanTiltPositionInquiry, WithNoCache())
+	if err != nil {
+		return 0, 0, err
+	}
+	switch len(data) {
+	case 8:
+		return decodeNibbles(data[:4], true), decodeNibbles(data[4:], true), nil
+	case 9:
+		return decodeNibbles(data[:5], true), decodeNibbles(data[5:], true), nil
+	default:
+		return 0, 0, fmt.Errorf("unexpected pan-tilt position reply data: %x", data)
+	}
+}
+
+// ZoomPosition inquires the zoom position, never taken from the inquiry
+// cache.
+func (c *Camera) ZoomPosition() (int, error) {
+	data, err := c.SendInquiry(ZoomPositionInquiry, WithNoCache())
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("unexpected zoom position reply data: %x", data)
+	}
+	zoom := decodeNibbles(data, false)
+	c.zoomMu.Lock()
+	c.zoomPos, c.zoomAt = zoom, time.Now()
+	c.zoomMu.Unlock()
+	return zoom, nil
+}
+
+// Position inquires the pan-tilt and zoom position.
+func (c *Camera) Position() (Position, error) {
+	pan, tilt, err := c.PanTiltPosition()
+	if err != nil {
+		return Position{}, err
+	}
+	zoom, err := c.ZoomPosition()
+	if err != nil {
+		return Position{}, err
+	}
+	return Position{Pan: pan, Tilt: tilt, Zoom: zoom}, nil
+}
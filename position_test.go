@@ -0,0 +1,83 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func newEmulatedCamera(t *testing.T) (*voip.Camera, *viscatest.Emulator) {
+	t.Helper()
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { emulator.Close() })
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { camera.Close() })
+	return camera, emulator
+}
+
+func TestPosition(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Pan: -300, Tilt: 100, Zoom: 0x2000})
+
+	pos, err := camera.Position()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := voip.Position{Pan: -300, Tilt: 100, Zoom: 0x2000}
+	if pos != want {
+		t.Errorf("Position() = %+v, want %+v", pos, want)
+	}
+}
+
+func TestPoller(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Pan: 10})
+
+	poller := voip.NewPoller(camera, 10*time.Millisecond)
+	updates, unsubscribe := poller.Subscribe()
+	defer unsubscribe()
+
+	next := func() voip.Position {
+		t.Helper()
+		select {
+		case pos := <-updates:
+			return pos
+		case <-time.After(time.Second):
+			t.Fatal("no position update")
+			return voip.Position{}
+		}
+	}
+
+	if pos := next(); pos.Pan != 10 {
+		t.Errorf("first update = %+v, want pan 10", pos)
+	}
+	emulator.SetState(viscatest.State{Power: true, Pan: 20})
+	if pos := next(); pos.Pan != 20 {
+		t.Errorf("second update = %+v, want pan 20", pos)
+	}
+
+	// No update without a change
+	select {
+	case pos := <-updates:
+		t.Errorf("unexpected update %+v", pos)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unsubscribe()
+	before := len(emulator.Requests())
+	time.Sleep(50 * time.Millisecond)
+	if after := len(emulator.Requests()); after != before {
+		t.Errorf("poller kept polling after the last unsubscribe: %d inquiries", after-before)
+	}
+}
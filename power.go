@@ -0,0 +1,95 @@
+package viscaoverip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// WarmupPollInterval is how often the power state is inquired while
+// commands wait for the camera to warm up (see Config.PowerOnWarmup).
+const WarmupPollInterval = 500 * time.Millisecond
+
+var powerOnPayload = []byte{0x81, 0x01, 0x04, 0x00, 0x02, 0xFF}
+
+// errCameraWarmingUp is reported while a command waits for the warm-up.
+var errCameraWarmingUp = errors.New("camera warming up, delaying command")
+
+// SetPower turns the camera on or puts it in standby. With
+// Config.PowerOnWarmup set, the commands sent next wait until the camera
+// reports it is powered on.
+func (c *Camera) SetPower(on bool) error {
+	return c.setOnOff("04 00", on)
+}
+
+// Power inquires whether the camera is powered on, as opposed to standby.
+func (c *Camera) Power() (bool, error) {
+	return c.inquireOnOff(PowerInquiry)
+}
+
+// isPowerCommand reports whether message is a power on or standby command.
+func isPowerCommand(message []byte) bool {
+	p := message[HeaderSize:]
+	return len(p) == 6 && bytes.Equal(p[:4], powerOnPayload[:4])
+}
+
+// startWarmup holds the commands sent after a power on command until the
+// camera warms up, if Config.PowerOnWarmup is set, except in a dry run.
+// c.mu must be held.
+func (c *Camera) startWarmup(message []byte) {
+	if c.Config.PowerOnWarmup <= 0 || c.Config.DryRun || binary.BigEndian.Uint16(message) != PayloadTypeVISCACommand {
+		return
+	}
+	if bytes.Equal(message[HeaderSize:], powerOnPayload) {
+		c.warmupUntil = time.Now().Add(c.Config.PowerOnWarmup)
+	} else if isPowerCommand(message) {
+		c.warmupUntil = time.Time{}
+	}
+}
+
+// awaitWarmup delays a command while the camera warms up, polling the
+// power state until the camera reports it is on or Config.PowerOnWarmup
+// elapses, whichever comes first. Inquiries and power commands are not
+// delayed. c.mu must be held; it is released between the polls, so that
+// inquiries, the heartbeat and Close run meanwhile.
+func (c *Camera) awaitWarmup(ctx context.Context, message []byte) error {
+	if c.warmupUntil.IsZero() || binary.BigEndian.Uint16(message) != PayloadTypeVISCACommand || isPowerCommand(message) {
+		return nil
+	}
+	for time.Now().Before(c.warmupUntil) {
+		seqNum := c.incSeqNum()
+		inquiry, err := MakeInquiry(PowerInquiry, seqNum)
+		if err != nil {
+			return err
+		}
+		opts := c.inquiryOptions(nil)
+		opts.maxRetries = 1
+		res, _, err := c.exchange(ctx, inquiry, seqNum, opts)
+		if err == nil && bytes.Equal(res.data, []byte{0x02}) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.ReportError("warmup", errCameraWarmingUp)
+		timer := time.NewTimer(min(WarmupPollInterval, time.Until(c.warmupUntil)))
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+		c.mu.Lock()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if c.closing.Load() {
+			return net.ErrClosed
+		}
+	}
+	c.warmupUntil = time.Time{}
+	return nil
+}
@@ -0,0 +1,133 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestPowerOnWarmup(t *testing.T) {
+	for _, warmup := range []time.Duration{0, 2 * time.Second} {
+		camera, emulator := newEmulatedCamera(t)
+		camera.Config.PowerOnWarmup = warmup
+		emulator.SetWarmup(700 * time.Millisecond)
+
+		if err := camera.SetPower(false); err != nil {
+			t.Fatal(err)
+		}
+		if err := camera.SetPower(true); err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		err := camera.SendCommand("06 04")
+		if warmup == 0 {
+			if err == nil {
+				t.Error("command sent while warming up succeeded without PowerOnWarmup")
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("command after power on = %v, want it delayed until warm", err)
+		}
+		if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+			t.Errorf("command delayed %v, want until the camera is warm", elapsed)
+		}
+		if on, err := camera.Power(); err != nil || !on {
+			t.Errorf("Power() = %v, %v, want true", on, err)
+		}
+	}
+}
+
+func TestPowerOnWarmupInquiry(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	camera.Config.PowerOnWarmup = 2 * time.Second
+	emulator.SetWarmup(700 * time.Millisecond)
+
+	if err := camera.SetPower(true); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- camera.SendCommand("06 04") }()
+	time.Sleep(100 * time.Millisecond)
+
+	// Inquiries are not held by the command waiting for the warm-up
+	start := time.Now()
+	if _, err := camera.SendInquiry(voip.PowerInquiry, voip.WithNoCache()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("inquiry took %v while a command waited for the warm-up", elapsed)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("command done before the warm-up: %v", err)
+	default:
+	}
+	if err := <-done; err != nil {
+		t.Errorf("command after warm-up = %v", err)
+	}
+}
+
+func TestPowerOnWarmupTimeout(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	camera.Config.PowerOnWarmup = 200 * time.Millisecond
+	emulator.SetWarmup(time.Hour)
+
+	if err := camera.SetPower(true); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err := camera.SendCommand("06 04")
+	var camErr *voip.CameraError
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("command delayed %v past PowerOnWarmup", elapsed)
+	}
+	if err == nil || !errors.As(err, &camErr) {
+		t.Errorf("SendCommand() = %v, want the camera error once the warm-up is over", err)
+	}
+}
+
+// TestPowerOnWarmupNumbering checks that a command held by the warm-up is
+// numbered after the power inquiries polled meanwhile, as it goes out after
+// them.
+func TestPowerOnWarmupNumbering(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	camera.Config.PowerOnWarmup = 2 * time.Second
+	emulator.SetWarmup(700 * time.Millisecond)
+
+	if err := camera.SetPower(true); err != nil {
+		t.Fatal(err)
+	}
+	var recording bytes.Buffer
+	camera.SetRecord(&recording)
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+	camera.SetRecord(nil)
+
+	session, err := viscatest.ReadSession(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent [][]byte
+	for _, d := range session.Datagrams {
+		if d.Sent {
+			sent = append(sent, d.Data)
+		}
+	}
+	if len(sent) < 2 {
+		t.Fatalf("sent %d datagrams, want power inquiries before the command", len(sent))
+	}
+	for i := 1; i < len(sent); i++ {
+		if prev, seq := viscatest.SeqNum(sent[i-1]), viscatest.SeqNum(sent[i]); seq != prev+1 {
+			t.Errorf("datagram %d numbered %d after %d", i, seq, prev)
+		}
+	}
+	if last := sent[len(sent)-1]; !bytes.Equal(last[voip.HeaderSize:], []byte{0x81, 0x01, 0x06, 0x04, 0xFF}) {
+		t.Errorf("last datagram % X, want the command", last)
+	}
+}
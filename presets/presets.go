@@ -0,0 +1,200 @@
+// Package presets keeps labels and positions of camera presets in a file,
+// so that UIs can show "Pulpit Wide" instead of "Preset 4", and presets can
+// be rebuilt on a replacement camera.
+package presets
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// Preset is the metadata of a camera preset.
+type Preset struct {
+	Number      int    `json:"number"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Position is the position stored in the preset, if known.
+	Position *voip.Position `json:"position,omitempty"`
+}
+
+// Store holds the presets of one camera, persisted to a JSON file on every
+// change. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	presets map[int]Preset
+}
+
+// Open loads the store at path. A missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, presets: make(map[int]Preset)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Preset
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, p := range list {
+		s.presets[p.Number] = p
+	}
+	return s, nil
+}
+
+// Get returns the preset with the given number.
+func (s *Store) Get(number int) (Preset, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.presets[number]
+	return p, ok
+}
+
+// Lookup returns the preset with the given name.
+func (s *Store) Lookup(name string) (Preset, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// List returns the presets, sorted by number.
+func (s *Store) List() []Preset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+func (s *Store) list() []Preset {
+	list := make([]Preset, 0, len(s.presets))
+	for _, p := range s.presets {
+		list = append(list, p)
+	}
+	slices.SortFunc(list, func(a, b Preset) int { return cmp.Compare(a.Number, b.Number) })
+	return list
+}
+
+// Set adds or replaces the metadata of a preset, without touching the
+// camera. Names must be unique.
+func (s *Store) Set(p Preset) error {
+	if p.Number < 0 || p.Number > voip.MaxPreset {
+		return fmt.Errorf("%w: preset must be between 0 and %d: %d", voip.ErrInvalidArgument, voip.MaxPreset, p.Number)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.presets {
+		if other.Name == p.Name && other.Number != p.Number {
+			return fmt.Errorf("preset name already used by preset %d: %s", other.Number, p.Name)
+		}
+	}
+	old, existed := s.presets[p.Number]
+	s.presets[p.Number] = p
+	if err := s.save(); err != nil {
+		if existed {
+			s.presets[p.Number] = old
+		} else {
+			delete(s.presets, p.Number)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the metadata of a preset.
+func (s *Store) Delete(number int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.presets[number]
+	if !ok {
+		return nil
+	}
+	delete(s.presets, number)
+	if err := s.save(); err != nil {
+		s.presets[number] = p
+		return err
+	}
+	return nil
+}
+
+// save writes the store to a temporary file renamed over the store, so that
+// a crash never leaves a truncated file. s.mu must be held.
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Store stores the current position of the camera as preset number, and
+// records it with its label.
+func (s *Store) Store(c *voip.Camera, number int, name, description string) error {
+	pos, err := c.Position()
+	if err != nil {
+		return err
+	}
+	if err := c.SetPreset(number); err != nil {
+		return err
+	}
+	return s.Set(Preset{Number: number, Name: name, Description: description, Position: &pos})
+}
+
+// Recall recalls the preset with the given name.
+func (s *Store) Recall(c *voip.Camera, name string) error {
+	p, ok := s.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown preset: %s", name)
+	}
+	return c.RecallPreset(p.Number)
+}
+
+// Rebuild stores every preset with a known position on the camera, by
+// moving to the position and storing the preset. Presets without a position
+// are skipped.
+func (s *Store) Rebuild(c *voip.Camera) error {
+	for _, p := range s.List() {
+		if p.Position == nil {
+			continue
+		}
+		err := c.MoveTo(p.Position.Pan, p.Position.Tilt, voip.MaxPanSpeed, voip.MaxTiltSpeed)
+		if err == nil {
+			err = c.ZoomTo(p.Position.Zoom)
+		}
+		if err == nil {
+			err = c.SetPreset(p.Number)
+		}
+		if err != nil {
+			return fmt.Errorf("preset %d (%s): %w", p.Number, p.Name, err)
+		}
+	}
+	return nil
+}
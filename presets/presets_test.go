@@ -0,0 +1,88 @@
+package presets_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/presets"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func newEmulatedCamera(t *testing.T) (*voip.Camera, *viscatest.Emulator) {
+	t.Helper()
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { emulator.Close() })
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { camera.Close() })
+	return camera, emulator
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+	s, err := presets.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetState(viscatest.State{Power: true, Pan: 200, Tilt: 10, Zoom: 0x0800})
+	if err := s.Store(camera, 4, "Pulpit Wide", "Whole pulpit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(presets.Preset{Number: 5, Name: "Choir"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(presets.Preset{Number: 6, Name: "Choir"}); err == nil {
+		t.Error("Set() with duplicate name: expected error")
+	}
+	if err := s.Set(presets.Preset{Number: 300, Name: "Far"}); err == nil {
+		t.Error("Set() with preset 300: expected error")
+	}
+
+	// Reopen to check persistence
+	s, err = presets.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(); len(list) != 2 || list[0].Number != 4 || list[1].Number != 5 {
+		t.Fatalf("List() = %+v, want presets 4 and 5", list)
+	}
+	p, ok := s.Lookup("Pulpit Wide")
+	if !ok || p.Description != "Whole pulpit" || p.Position == nil || p.Position.Pan != 200 {
+		t.Errorf("Lookup(Pulpit Wide) = %+v, %v", p, ok)
+	}
+
+	replacement, replacementEmulator := newEmulatedCamera(t)
+	if err := s.Rebuild(replacement); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := replacementEmulator.State(), (viscatest.State{Power: true, Pan: 200, Tilt: 10, Zoom: 0x0800}); got != want {
+		t.Errorf("state after Rebuild() = %+v, want %+v", got, want)
+	}
+	requests := replacementEmulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x04, 0x3F, 0x01, 0x04, 0xFF}) {
+		t.Errorf("last request = % X, want preset 4 set", last)
+	}
+
+	if err := s.Recall(replacement, "Choir"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get(5); ok {
+		t.Error("Get(5) after Delete(5): preset still present")
+	}
+}
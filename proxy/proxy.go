@@ -0,0 +1,254 @@
+// Package proxy lets several VISCA over IP controllers share one camera.
+//
+// Cameras handle a single controller well: each controller resets the
+// sequence number and expects its own numbering to be followed. The proxy
+// answers the controllers' control commands itself, serializes their
+// requests to the camera with its own sequence numbers, and maps the camera
+// replies back to each controller's sequence numbers.
+package proxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReplyTimeout      = 2 * time.Second
+	DefaultClientIdleTimeout = time.Minute
+
+	payloadTypeCommand      = 0x0100
+	payloadTypeInquiry      = 0x0110
+	payloadTypeReply        = 0x0111
+	payloadTypeControl      = 0x0200
+	payloadTypeControlReply = 0x0201
+
+	headerSize = 8
+)
+
+type Config struct {
+	// ReplyTimeout is how long to wait for each reply of the camera.
+	// Defaults to DefaultReplyTimeout.
+	ReplyTimeout time.Duration
+	// ClientIdleTimeout is how long the last request of a controller is
+	// remembered after it stops sending. Defaults to
+	// DefaultClientIdleTimeout.
+	ClientIdleTimeout time.Duration
+	// ErrorHandler, if set, is called with the errors the proxy handles by
+	// itself, e.g. to log them.
+	ErrorHandler func(error)
+}
+
+// Proxy forwards the requests of several controllers to one camera.
+type Proxy struct {
+	camera  net.Conn
+	cfg     Config
+	seqNum  uint32
+	clients map[string]*client
+	swept   time.Time // Last eviction of idle clients
+}
+
+// client remembers the last request of a controller, so that a
+// retransmission is answered again without moving the camera twice.
+type client struct {
+	seqNum   uint32
+	request  []byte
+	replies  [][]byte
+	complete bool // The final reply was received
+	lastSeen time.Time
+}
+
+type request struct {
+	msg  []byte
+	addr net.Addr
+}
+
+// New returns a Proxy to the camera, a UDP connection dialed to it.
+// No network I/O happens until Serve.
+func New(camera net.Conn, cfg Config) *Proxy {
+	if cfg.ReplyTimeout == 0 {
+		cfg.ReplyTimeout = DefaultReplyTimeout
+	}
+	if cfg.ClientIdleTimeout == 0 {
+		cfg.ClientIdleTimeout = DefaultClientIdleTimeout
+	}
+	return &Proxy{
+		camera:  camera,
+		cfg:     cfg,
+		clients: make(map[string]*client),
+	}
+}
+
+// Serve resets the sequence number of the camera, then forwards the
+// requests received on conn until reading from it fails, e.g. after it is
+// closed. Requests are forwarded one at a time, in the order received.
+func (p *Proxy) Serve(conn net.PacketConn) error {
+	if err := p.reset(); err != nil {
+		return err
+	}
+
+	requests := make(chan request, 64)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for req := range requests {
+			for _, reply := range p.handle(req.msg, req.addr.String()) {
+				_, _ = conn.WriteTo(reply, req.addr)
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer close(requests)
+
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		requests <- request{msg: msg, addr: addr}
+	}
+}
+
+// reset sends RESET to the camera and waits for its acknowledgement.
+func (p *Proxy) reset() error {
+	p.seqNum = 0
+	msg := makeMessage(payloadTypeControl, 0, []byte{0x01})
+	if _, err := p.camera.Write(msg); err != nil {
+		return fmt.Errorf("failed to send reset command: %w", err)
+	}
+	buf := make([]byte, 1024)
+	for {
+		err := p.camera.SetReadDeadline(time.Now().Add(p.cfg.ReplyTimeout))
+		if err != nil {
+			return fmt.Errorf("failed to set read deadline: %w", err)
+		}
+		n, err := p.camera.Read(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read reset response: %w", err)
+		}
+		if n >= headerSize+1 && binary.BigEndian.Uint16(buf[0:2]) == payloadTypeControlReply {
+			return nil
+		}
+	}
+}
+
+// handle processes one request of the controller at addr and returns the
+// messages to send back to it.
+func (p *Proxy) handle(msg []byte, addr string) [][]byte {
+	if len(msg) < headerSize+1 || int(binary.BigEndian.Uint16(msg[2:4])) != len(msg)-headerSize {
+		return nil
+	}
+	payloadType := binary.BigEndian.Uint16(msg[0:2])
+	seqNum := binary.BigEndian.Uint32(msg[4:8])
+
+	now := time.Now()
+	p.evictIdle(now)
+	c := p.clients[addr]
+	if c == nil {
+		c = &client{}
+		p.clients[addr] = c
+	}
+	c.lastSeen = now
+
+	switch payloadType {
+	case payloadTypeControl:
+		// Each controller numbers its own requests: acknowledge its RESET
+		// without disturbing the camera.
+		if msg[headerSize] == 0x01 {
+			*c = client{lastSeen: now}
+			return [][]byte{makeMessage(payloadTypeControlReply, seqNum, []byte{0x01})}
+		}
+		return nil
+	case payloadTypeCommand, payloadTypeInquiry:
+	default:
+		return nil
+	}
+
+	if c.complete && c.seqNum == seqNum && bytes.Equal(c.request, msg) {
+		return c.replies
+	}
+
+	replies, complete := p.forward(msg)
+	for _, reply := range replies {
+		binary.BigEndian.PutUint32(reply[4:8], seqNum)
+	}
+	*c = client{seqNum: seqNum, request: msg, replies: replies, complete: complete, lastSeen: now}
+	return replies
+}
+
+// evictIdle forgets the controllers that sent nothing for ClientIdleTimeout,
+// looking for them at most once per timeout.
+func (p *Proxy) evictIdle(now time.Time) {
+	if now.Sub(p.swept) < p.cfg.ClientIdleTimeout {
+		return
+	}
+	p.swept = now
+	for addr, c := range p.clients {
+		if now.Sub(c.lastSeen) >= p.cfg.ClientIdleTimeout {
+			delete(p.clients, addr)
+		}
+	}
+}
+
+// forward sends the request to the camera with the next sequence number of
+// the proxy, and collects the replies until the final one. It reports
+// whether the final reply was received.
+func (p *Proxy) forward(msg []byte) ([][]byte, bool) {
+	p.seqNum++
+	out := make([]byte, len(msg))
+	copy(out, msg)
+	binary.BigEndian.PutUint32(out[4:8], p.seqNum)
+	if _, err := p.camera.Write(out); err != nil {
+		p.report(fmt.Errorf("forward to camera: %w", err))
+		return nil, false
+	}
+
+	var replies [][]byte
+	buf := make([]byte, 1024)
+	for {
+		err := p.camera.SetReadDeadline(time.Now().Add(p.cfg.ReplyTimeout))
+		if err != nil {
+			return replies, false
+		}
+		n, err := p.camera.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				p.report(fmt.Errorf("no reply from camera for sequence %d", p.seqNum))
+			}
+			return replies, false
+		}
+		reply := buf[:n]
+		if n < headerSize+3 || binary.BigEndian.Uint16(reply[0:2]) != payloadTypeReply ||
+			binary.BigEndian.Uint32(reply[4:8]) != p.seqNum {
+			continue // Late or unrelated
+		}
+		replies = append(replies, bytes.Clone(reply))
+		// Only ACKs are followed by another reply
+		if reply[headerSize+1]&0xF0 != 0x40 {
+			return replies, true
+		}
+	}
+}
+
+func (p *Proxy) report(err error) {
+	if p.cfg.ErrorHandler != nil {
+		p.cfg.ErrorHandler(err)
+	}
+}
+
+func makeMessage(payloadType uint16, seqNum uint32, payload []byte) []byte {
+	msg := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint16(msg[0:2], payloadType)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(payload)))
+	binary.BigEndian.PutUint32(msg[4:8], seqNum)
+	return append(msg, payload...)
+}
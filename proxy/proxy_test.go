@@ -0,0 +1,166 @@
+package proxy_test
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/proxy"
+)
+
+// fakeCamera answers like a camera, and records the sequence numbers of the
+// commands it receives.
+type fakeCamera struct {
+	conn *net.UDPConn
+	mu   sync.Mutex
+	seqs []uint32
+}
+
+func (f *fakeCamera) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := f.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		msg := buf[:n]
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		if msg[0] == 0x02 {
+			f.conn.WriteToUDP([]byte{0x02, 0x01, 0x00, 0x01, 0, 0, 0, 0, 0x01}, addr)
+			continue
+		}
+		f.mu.Lock()
+		f.seqs = append(f.seqs, seqNum)
+		f.mu.Unlock()
+		reply := func(status byte) []byte {
+			r := []byte{0x01, 0x11, 0x00, 0x03, 0, 0, 0, 0, 0x90, status, 0xFF}
+			binary.BigEndian.PutUint32(r[4:8], seqNum)
+			return r
+		}
+		f.conn.WriteToUDP(reply(0x41), addr)
+		f.conn.WriteToUDP(reply(0x51), addr)
+	}
+}
+
+func TestProxyMultipleControllers(t *testing.T) {
+	cameraConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cameraConn.Close()
+	camera := &fakeCamera{conn: cameraConn}
+	go camera.serve()
+
+	upstream, err := net.DialUDP("udp", nil, cameraConn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := proxy.New(upstream, proxy.Config{ReplyTimeout: 200 * time.Millisecond})
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go p.Serve(listener)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := net.DialUDP("udp", nil, listener.LocalAddr().(*net.UDPAddr))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			cfg := voip.Config{MaxRetries: 3, Timeout: time.Second}
+			c, err := voip.NewCameraWithConfig(conn, cfg)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Close()
+			for range 5 {
+				if err := c.SendCommand("06 04"); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	camera.mu.Lock()
+	defer camera.mu.Unlock()
+	// 3 controllers, each with IF_Clear and 5 commands
+	if len(camera.seqs) != 18 {
+		t.Fatalf("camera received %d commands, want 18", len(camera.seqs))
+	}
+	for i, seq := range camera.seqs {
+		if seq != uint32(i+1) {
+			t.Fatalf("camera sequence numbers = %v, want 1 to 18", camera.seqs)
+		}
+	}
+}
+
+func TestProxyForgetsIdleControllers(t *testing.T) {
+	cameraConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cameraConn.Close()
+	camera := &fakeCamera{conn: cameraConn}
+	go camera.serve()
+
+	upstream, err := net.DialUDP("udp", nil, cameraConn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := proxy.New(upstream, proxy.Config{ReplyTimeout: 200 * time.Millisecond, ClientIdleTimeout: 50 * time.Millisecond})
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go p.Serve(listener)
+
+	conn, err := net.DialUDP("udp", nil, listener.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// send sends the same Home command, and waits for its ACK and completion
+	send := func() {
+		t.Helper()
+		if _, err := conn.Write([]byte{0x01, 0x00, 0x00, 0x05, 0, 0, 0, 7, 0x81, 0x01, 0x06, 0x04, 0xFF}); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1024)
+		for range 2 {
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			if _, err := conn.Read(buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	received := func() int {
+		camera.mu.Lock()
+		defer camera.mu.Unlock()
+		return len(camera.seqs)
+	}
+
+	send()
+	send() // A retransmission, answered by the proxy
+	if n := received(); n != 1 {
+		t.Fatalf("camera received %d commands, want 1", n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	send() // A new request of a forgotten controller
+	if n := received(); n != 2 {
+		t.Errorf("camera received %d commands after the idle timeout, want 2", n)
+	}
+}
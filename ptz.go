@@ -0,0 +1,230 @@
+package viscaoverip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	MaxPanSpeed  = 0x18
+	MaxTiltSpeed = 0x14
+	MaxZoomSpeed = 7
+	MaxPreset    = 0xFE
+)
+
+// ErrInvalidArgument is returned when a parameter is out of range, before
+// anything is sent to the peripheral device.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// RecallPreset moves the camera to a stored preset (CAM_Memory Recall).
+func (c *Camera) RecallPreset(preset int) error {
+	if preset < 0 || preset > MaxPreset {
+		return fmt.Errorf("%w: preset must be between 0 and %d: %d", ErrInvalidArgument, MaxPreset, preset)
+	}
+	return c.SendCommand(fmt.Sprintf("04 3F 02 %02X", preset))
+}
+
+// SetPreset stores the current position as a preset (CAM_Memory Set).
+func (c *Camera) SetPreset(preset int) error {
+	if preset < 0 || preset > MaxPreset {
+		return fmt.Errorf("%w: preset must be between 0 and %d: %d", ErrInvalidArgument, MaxPreset, preset)
+	}
+	return c.SendCommand(fmt.Sprintf("04 3F 01 %02X", preset))
+}
+
+// PanTilt drives the camera continuously. Positive pan moves right and
+// positive tilt moves up; the magnitude is the speed, up to MaxPanSpeed and
+// MaxTiltSpeed. An axis with zero speed stops. Speeds are scaled down
+// with the zoom position if Config.ZoomCompensation is set.
+func (c *Camera) PanTilt(pan, tilt int) error {
+	if pan < -MaxPanSpeed || pan > MaxPanSpeed {
+		return fmt.Errorf("%w: pan speed must be between -%d and %d: %d", ErrInvalidArgument, MaxPanSpeed, MaxPanSpeed, pan)
+	}
+	if tilt < -MaxTiltSpeed || tilt > MaxTiltSpeed {
+		return fmt.Errorf("%w: tilt speed must be between -%d and %d: %d", ErrInvalidArgument, MaxTiltSpeed, MaxTiltSpeed, tilt)
+	}
+	if c.Config.ZoomCompensation > 0 {
+		var err error
+		if pan, tilt, err = c.compensateZoom(pan, tilt); err != nil {
+			return err
+		}
+	}
+	panDir, panSpeed := direction(pan, 0x02, 0x01)
+	tiltDir, tiltSpeed := direction(tilt, 0x01, 0x02)
+	return c.SendCommand(fmt.Sprintf("06 01 %02X %02X %02X %02X", panSpeed, tiltSpeed, panDir, tiltDir))
+}
+
+// direction returns the drive direction byte of a signed speed, and its
+// magnitude (at least 1, as speed 0 is not valid even when stopping).
+func direction(speed int, positive, negative byte) (byte, int) {
+	switch {
+	case speed > 0:
+		return positive, speed
+	case speed < 0:
+		return negative, -speed
+	default:
+		return 0x03, 1
+	}
+}
+
+// PanTiltStop stops pan and tilt movement.
+func (c *Camera) PanTiltStop() error {
+	return c.PanTilt(0, 0)
+}
+
+// Home moves the camera to its home position.
+func (c *Camera) Home() error {
+	return c.SendCommand("06 04")
+}
+
+// Zoom drives the zoom continuously. Positive speed zooms in (tele) and
+// negative speed zooms out (wide), up to MaxZoomSpeed. Zero stops.
+func (c *Camera) Zoom(speed int) error {
+	switch {
+	case speed < -MaxZoomSpeed || speed > MaxZoomSpeed:
+		return fmt.Errorf("%w: zoom speed must be between -%d and %d: %d", ErrInvalidArgument, MaxZoomSpeed, MaxZoomSpeed, speed)
+	case speed > 0:
+		return c.SendCommand(fmt.Sprintf("04 07 2%X", speed-1))
+	case speed < 0:
+		return c.SendCommand(fmt.Sprintf("04 07 3%X", -speed-1))
+	default:
+		return c.ZoomStop()
+	}
+}
+
+// MoveTo moves pan and tilt to an absolute position, in the units of
+// PanTiltPosition, at the given speeds (1 to MaxPanSpeed and MaxTiltSpeed).
+// A pan beyond the 4 nibble range is sent with 5 nibbles, as read from
+// cameras with a wide pan range.
+func (c *Camera) MoveTo(pan, tilt, panSpeed, tiltSpeed int) error {
+	cmd, err := moveToCommand(pan, tilt, panSpeed, tiltSpeed)
+	if err != nil {
+		return err
+	}
+	return c.SendCommand(cmd)
+}
+
+func moveToCommand(pan, tilt, panSpeed, tiltSpeed int) (string, error) {
+	switch {
+	case panSpeed < 1 || panSpeed > MaxPanSpeed:
+		return "", fmt.Errorf("%w: pan speed must be between 1 and %d: %d", ErrInvalidArgument, MaxPanSpeed, panSpeed)
+	case tiltSpeed < 1 || tiltSpeed > MaxTiltSpeed:
+		return "", fmt.Errorf("%w: tilt speed must be between 1 and %d: %d", ErrInvalidArgument, MaxTiltSpeed, tiltSpeed)
+	case pan < -1<<19 || pan >= 1<<19:
+		return "", fmt.Errorf("%w: pan position out of range: %d", ErrInvalidArgument, pan)
+	case tilt < math.MinInt16 || tilt > math.MaxInt16:
+		return "", fmt.Errorf("%w: tilt position out of range: %d", ErrInvalidArgument, tilt)
+	}
+	panNibbles := 4
+	if pan < math.MinInt16 || pan > math.MaxInt16 {
+		panNibbles = 5
+	}
+	return fmt.Sprintf("06 02 %02X %02X %s %s", panSpeed, tiltSpeed, encodeNibbles(pan, panNibbles), encodeNibbles(tilt, 4)), nil
+}
+
+// ZoomTo moves the zoom to an absolute position, in the units of
+// ZoomPosition (CAM_Zoom Direct).
+func (c *Camera) ZoomTo(zoom int) error {
+	cmd, err := zoomToCommand(zoom)
+	if err != nil {
+		return err
+	}
+	return c.SendCommand(cmd)
+}
+
+func zoomToCommand(zoom int) (string, error) {
+	if zoom < 0 || zoom > math.MaxUint16 {
+		return "", fmt.Errorf("%w: zoom position out of range: %d", ErrInvalidArgument, zoom)
+	}
+	return "04 47 " + encodeNibbles(zoom, 4), nil
+}
+
+// MoveToPosition moves pan, tilt and zoom to an absolute position at once,
+// pan and tilt at the given speeds, and returns when every axis has
+// arrived or ctx is done. The two moves run on different sockets of the
+// camera, so neither waits for the other to start.
+func (c *Camera) MoveToPosition(ctx context.Context, pos Position, panSpeed, tiltSpeed int) error {
+	moveCmd, err := moveToCommand(pos.Pan, pos.Tilt, panSpeed, tiltSpeed)
+	if err != nil {
+		return err
+	}
+	zoomCmd, err := zoomToCommand(pos.Zoom)
+	if err != nil {
+		return err
+	}
+
+	move, err := c.SendCommandAsync(moveCmd)
+	if err != nil {
+		return err
+	}
+	zoom, err := c.SendCommandAsync(zoomCmd)
+	if err != nil {
+		return err
+	}
+	moveErr := move.Wait(ctx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.Join(moveErr, zoom.Wait(ctx))
+}
+
+// ZoomStop stops zoom movement.
+func (c *Camera) ZoomStop() error {
+	return c.SendCommand("04 07 00")
+}
+
+// LimitCorner is a corner of the pan-tilt limits.
+type LimitCorner byte
+
+const (
+	LimitDownLeft LimitCorner = 0x00
+	LimitUpRight  LimitCorner = 0x01
+)
+
+// SetPanTiltLimit sets a corner of the pan-tilt limits to a position, in the
+// units of PanTiltPosition (Pan-tiltLimitSet), so that the camera stays
+// clear of walls and lights.
+func (c *Camera) SetPanTiltLimit(corner LimitCorner, pan, tilt int) error {
+	switch {
+	case corner != LimitDownLeft && corner != LimitUpRight:
+		return fmt.Errorf("%w: unknown limit corner: %d", ErrInvalidArgument, corner)
+	case pan < math.MinInt16 || pan > math.MaxInt16:
+		return fmt.Errorf("%w: pan position out of range: %d", ErrInvalidArgument, pan)
+	case tilt < math.MinInt16 || tilt > math.MaxInt16:
+		return fmt.Errorf("%w: tilt position out of range: %d", ErrInvalidArgument, tilt)
+	}
+	return c.SendCommand(fmt.Sprintf("06 07 00 %02X %s %s", byte(corner), encodeNibbles(pan, 4), encodeNibbles(tilt, 4)))
+}
+
+// SetPanTiltLimitDegrees is like SetPanTiltLimit, with the position in
+// degrees, converted with the UnitsPerDegree of the vendor profile.
+func (c *Camera) SetPanTiltLimitDegrees(corner LimitCorner, pan, tilt float64) error {
+	scale := c.Config.Vendor.UnitsPerDegree()
+	if scale == 0 {
+		return fmt.Errorf("%w: no degree scale for %s", ErrUnsupported, c.Config.Vendor)
+	}
+	return c.SetPanTiltLimit(corner, int(math.Round(pan*scale)), int(math.Round(tilt*scale)))
+}
+
+// ClearPanTiltLimit removes a corner of the pan-tilt limits
+// (Pan-tiltLimitClear).
+func (c *Camera) ClearPanTiltLimit(corner LimitCorner) error {
+	if corner != LimitDownLeft && corner != LimitUpRight {
+		return fmt.Errorf("%w: unknown limit corner: %d", ErrInvalidArgument, corner)
+	}
+	return c.SendCommand(fmt.Sprintf("06 07 01 %02X 07 0F 0F 0F 07 0F 0F 0F", byte(corner)))
+}
+
+// SetIRReceive turns the receiver of the IR remote control on or off
+// (IR_Receive), e.g. so that remotes in the audience cannot move the camera
+// during a show.
+func (c *Camera) SetIRReceive(on bool) error {
+	return c.setOnOff("06 08", on)
+}
+
+// IRReceive reports whether the IR remote control receiver is on.
+func (c *Camera) IRReceive() (bool, error) {
+	return c.inquireOnOff("06 08")
+}
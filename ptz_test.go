@@ -0,0 +1,76 @@
+package viscaoverip_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestPTZCommands(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		want string
+	}{
+		{"RecallPreset", func() error { return camera.RecallPreset(3) }, "81 01 04 3F 02 03 FF"},
+		{"SetPreset", func() error { return camera.SetPreset(0x7F) }, "81 01 04 3F 01 7F FF"},
+		{"PanTilt Up Right", func() error { return camera.PanTilt(12, 5) }, "81 01 06 01 0C 05 02 01 FF"},
+		{"PanTilt Down Left", func() error { return camera.PanTilt(-24, -20) }, "81 01 06 01 18 14 01 02 FF"},
+		{"PanTilt Pan Only", func() error { return camera.PanTilt(-1, 0) }, "81 01 06 01 01 01 01 03 FF"},
+		{"PanTiltStop", camera.PanTiltStop, "81 01 06 01 01 01 03 03 FF"},
+		{"Home", camera.Home, "81 01 06 04 FF"},
+		{"Zoom Tele", func() error { return camera.Zoom(7) }, "81 01 04 07 26 FF"},
+		{"Zoom Wide", func() error { return camera.Zoom(-1) }, "81 01 04 07 30 FF"},
+		{"ZoomStop", camera.ZoomStop, "81 01 04 07 00 FF"},
+		{"SetPanTiltLimit", func() error {
+			return camera.SetPanTiltLimit(voip.LimitUpRight, 0x0800, 0x0400)
+		}, "81 01 06 07 00 01 00 08 00 00 00 04 00 00 FF"},
+		{"SetPanTiltLimitDegrees", func() error {
+			camera.Config.Vendor = voip.VendorSony
+			defer func() { camera.Config.Vendor = voip.VendorGeneric }()
+			return camera.SetPanTiltLimitDegrees(voip.LimitDownLeft, -90, -10)
+		}, "81 01 06 07 00 00 0F 0A 0F 00 0F 0F 07 00 FF"},
+		{"SetIRReceive", func() error { return camera.SetIRReceive(false) }, "81 01 06 08 03 FF"},
+		{"ClearPanTiltLimit", func() error {
+			return camera.ClearPanTiltLimit(voip.LimitUpRight)
+		}, "81 01 06 07 01 01 07 0F 0F 0F 07 0F 0F 0F FF"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.fn(); err != nil {
+				t.Fatal(err)
+			}
+			requests := emulator.Requests()
+			got := requests[len(requests)-1]
+			want, _ := hex.DecodeString(strings.ReplaceAll(tc.want, " ", ""))
+			if string(got) != string(want) {
+				t.Errorf("sent % X, want %s", got, tc.want)
+			}
+		})
+	}
+
+	invalid := []func() error{
+		func() error { return camera.RecallPreset(-1) },
+		func() error { return camera.SetPreset(255) },
+		func() error { return camera.PanTilt(25, 0) },
+		func() error { return camera.PanTilt(0, -21) },
+		func() error { return camera.Zoom(8) },
+	}
+	for i, fn := range invalid {
+		if err := fn(); err == nil {
+			t.Errorf("invalid call %d: expected error", i)
+		}
+	}
+}
+
+func TestPanTiltLimitDegreesUnsupported(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+	if err := camera.SetPanTiltLimitDegrees(voip.LimitUpRight, 90, 45); !errors.Is(err, voip.ErrUnsupported) {
+		t.Errorf("SetPanTiltLimitDegrees() with the generic profile = %v, want ErrUnsupported", err)
+	}
+}
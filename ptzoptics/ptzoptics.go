@@ -0,0 +1,129 @@
+// Package ptzoptics provides PTZOptics-family commands that are not part of
+// the core VISCA spec. Every function requires the camera to be configured
+// with the viscaoverip.VendorPTZOptics profile.
+package ptzoptics
+
+import (
+	"fmt"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func onOff(on bool) string {
+	if on {
+		return "02"
+	}
+	return "03"
+}
+
+// SetTally turns the tally light on or off. It is the red tally of
+// voip.Camera.SetTally.
+func SetTally(c *voip.Camera, on bool) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	return c.SetTally(voip.TallyRed, on)
+}
+
+// SetMotionSync turns MotionSync on or off. With MotionSync on, pan, tilt
+// and zoom of a preset recall finish at the same time.
+func SetMotionSync(c *voip.Camera, on bool) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	return c.SendPacket("81 0A 11 13" + onOff(on) + "FF")
+}
+
+// MaxMotionSyncSpeed is the highest MotionSync speed limit.
+const MaxMotionSyncSpeed = 0x0F
+
+// SetMotionSyncMaxSpeed limits the speed of the fastest axis of MotionSync
+// moves, from 1 to MaxMotionSyncSpeed; the other axes slow down to arrive
+// with it.
+func SetMotionSyncMaxSpeed(c *voip.Camera, speed int) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	if speed < 1 || speed > MaxMotionSyncSpeed {
+		return fmt.Errorf("%w: MotionSync speed must be between 1 and %d: %d", voip.ErrInvalidArgument, MaxMotionSyncSpeed, speed)
+	}
+	return c.SendPacket(fmt.Sprintf("81 0A 11 14 %02X FF", speed))
+}
+
+// ToggleOSDMenu opens the on-screen menu, or closes it if it is open.
+func ToggleOSDMenu(c *voip.Camera) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	return c.SendCommand("06 06 10")
+}
+
+// OSDEnter selects the highlighted item of the on-screen menu.
+func OSDEnter(c *voip.Camera) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	return c.SendPacket("81 01 7E 01 02 00 01 FF")
+}
+
+// SetPresetSpeed sets the pan and tilt speeds of preset recalls, from 1 to
+// voip.MaxPanSpeed and voip.MaxTiltSpeed: slow for smooth on-air moves, fast
+// to reposition while off air.
+func SetPresetSpeed(c *voip.Camera, panSpeed, tiltSpeed int) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	if panSpeed < 1 || panSpeed > voip.MaxPanSpeed {
+		return fmt.Errorf("%w: pan speed must be between 1 and %d: %d", voip.ErrInvalidArgument, voip.MaxPanSpeed, panSpeed)
+	}
+	if tiltSpeed < 1 || tiltSpeed > voip.MaxTiltSpeed {
+		return fmt.Errorf("%w: tilt speed must be between 1 and %d: %d", voip.ErrInvalidArgument, voip.MaxTiltSpeed, tiltSpeed)
+	}
+	return c.SendCommand(fmt.Sprintf("06 01 %02X %02X", panSpeed, tiltSpeed))
+}
+
+// videoFormats are the codes of the video system setting.
+var videoFormats = map[voip.VideoFormat]byte{
+	voip.Format1080p5994: 0x00,
+	voip.Format1080p50:   0x01,
+	voip.Format1080i5994: 0x02,
+	voip.Format1080i50:   0x03,
+	voip.Format720p5994:  0x04,
+	voip.Format720p50:    0x05,
+	voip.Format1080p2997: 0x06,
+	voip.Format1080p25:   0x07,
+	voip.Format720p2997:  0x08,
+	voip.Format720p25:    0x09,
+}
+
+// SetVideoFormat sets the resolution and frame rate of the HDMI and SDI
+// outputs.
+func SetVideoFormat(c *voip.Camera, format voip.VideoFormat) error {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return err
+	}
+	code, ok := videoFormats[format]
+	if !ok {
+		return fmt.Errorf("%w: unsupported video format: %s", voip.ErrInvalidArgument, format)
+	}
+	return c.SendCommand(fmt.Sprintf("06 35 00 %02X", code))
+}
+
+// VideoFormat returns the active video format.
+func VideoFormat(c *voip.Camera) (voip.VideoFormat, error) {
+	if err := c.RequireVendor(voip.VendorPTZOptics); err != nil {
+		return "", err
+	}
+	data, err := c.SendInquiry("06 23")
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 1 {
+		for format, code := range videoFormats {
+			if code == data[0] {
+				return format, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unexpected inquiry reply data: %x", data)
+}
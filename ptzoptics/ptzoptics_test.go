@@ -0,0 +1,60 @@
+package ptzoptics_test
+
+import (
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/ptzoptics"
+)
+
+func TestVendorGating(t *testing.T) {
+	// No connection is needed: gated commands must fail before any I/O.
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorGeneric}}
+
+	tests := []struct {
+		name string
+		fn   func(*voip.Camera) error
+	}{
+		{"SetTally", func(c *voip.Camera) error { return ptzoptics.SetTally(c, true) }},
+		{"SetMotionSync", func(c *voip.Camera) error { return ptzoptics.SetMotionSync(c, true) }},
+		{"SetMotionSyncMaxSpeed", func(c *voip.Camera) error { return ptzoptics.SetMotionSyncMaxSpeed(c, 1) }},
+		{"ToggleOSDMenu", ptzoptics.ToggleOSDMenu},
+		{"OSDEnter", ptzoptics.OSDEnter},
+		{"SetPresetSpeed", func(c *voip.Camera) error { return ptzoptics.SetPresetSpeed(c, 1, 1) }},
+		{"SetVideoFormat", func(c *voip.Camera) error { return ptzoptics.SetVideoFormat(c, voip.Format1080p5994) }},
+		{"VideoFormat", func(c *voip.Camera) error { _, err := ptzoptics.VideoFormat(c); return err }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.fn(camera); !errors.Is(err, voip.ErrUnsupported) {
+				t.Errorf("%s() = %v, want ErrUnsupported", tc.name, err)
+			}
+		})
+	}
+}
+
+func TestSetPresetSpeedRange(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorPTZOptics}}
+	for _, speeds := range [][2]int{{0, 1}, {1, 0}, {voip.MaxPanSpeed + 1, 1}, {1, voip.MaxTiltSpeed + 1}} {
+		if err := ptzoptics.SetPresetSpeed(camera, speeds[0], speeds[1]); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPresetSpeed(%d, %d) = %v, want ErrInvalidArgument", speeds[0], speeds[1], err)
+		}
+	}
+}
+
+func TestSetVideoFormatUnsupported(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorPTZOptics}}
+	if err := ptzoptics.SetVideoFormat(camera, voip.Format2160p2997); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetVideoFormat(2160p29.97) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestSetMotionSyncMaxSpeedRange(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorPTZOptics}}
+	for _, speed := range []int{0, ptzoptics.MaxMotionSyncSpeed + 1} {
+		if err := ptzoptics.SetMotionSyncMaxSpeed(camera, speed); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetMotionSyncMaxSpeed(%d) = %v, want ErrInvalidArgument", speed, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package viscaoverip
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrCanceled is the error of a queued command canceled before it was sent.
+var ErrCanceled = errors.New("command canceled before it was sent")
+
+// Queue sends commands to a camera one at a time, in the order enqueued,
+// so that callers can inspect and cancel the commands not sent yet, e.g.
+// when an operator aborts a tour. It is safe for concurrent use.
+type Queue struct {
+	camera *Camera
+
+	mu      sync.Mutex
+	pending []*Job
+	wake    chan struct{}
+	closed  bool
+	done    chan struct{}
+}
+
+// Job is a command in a Queue.
+type Job struct {
+	Command string
+
+	queue *Queue
+	opts  []SendOption
+	done  chan struct{}
+	err   error
+}
+
+// NewQueue returns a Queue sending to c, and starts its worker.
+func NewQueue(c *Camera) *Queue {
+	q := &Queue{
+		camera: c,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Enqueue adds a command, as accepted by SendCommand, to the end of the
+// queue. After Close, the job is canceled immediately.
+func (q *Queue) Enqueue(commandHex string, opts ...SendOption) *Job {
+	j := &Job{Command: commandHex, queue: q, opts: opts, done: make(chan struct{})}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		j.finish(ErrCanceled)
+		return j
+	}
+	q.pending = append(q.pending, j)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return j
+}
+
+// Len returns the number of commands not sent yet.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Pending returns the commands not sent yet, in order.
+func (q *Queue) Pending() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*Job(nil), q.pending...)
+}
+
+// Clear cancels every command not sent yet and returns how many there were.
+func (q *Queue) Clear() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.pending)
+	for _, j := range q.pending {
+		j.finish(ErrCanceled)
+	}
+	q.pending = nil
+	return n
+}
+
+// Close cancels the commands not sent yet and stops the worker once the
+// command being sent, if any, is done. It does not close the camera.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.wake)
+	}
+	q.mu.Unlock()
+	q.Clear()
+	<-q.done
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+	for range q.wake {
+		for {
+			q.mu.Lock()
+			if len(q.pending) == 0 || q.closed {
+				q.mu.Unlock()
+				break
+			}
+			j := q.pending[0]
+			q.pending = q.pending[1:]
+			q.mu.Unlock()
+
+			j.finish(q.camera.SendCommand(j.Command, j.opts...))
+		}
+	}
+}
+
+// Cancel removes the job from its queue if it was not sent yet, and
+// reports whether it did.
+func (j *Job) Cancel() bool {
+	q := j.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, p := range q.pending {
+		if p == j {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			j.finish(ErrCanceled)
+			return true
+		}
+	}
+	return false
+}
+
+// Done returns a channel closed when the command is sent or canceled.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Err returns the result of the command once Done is closed: nil on
+// completion, the error of SendCommand, or ErrCanceled.
+func (j *Job) Err() error {
+	select {
+	case <-j.done:
+		return j.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the command is sent or canceled, or ctx is done, and
+// returns its result.
+func (j *Job) Wait(ctx context.Context) error {
+	select {
+	case <-j.done:
+		return j.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (j *Job) finish(err error) {
+	j.err = err
+	close(j.done)
+}
@@ -0,0 +1,77 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestQueue(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	q := voip.NewQueue(camera)
+	defer q.Close()
+
+	// The first move takes about 100ms, holding the rest in the queue
+	move := q.Enqueue("06 02 10 10 00 01 04 00 00 00 00 00", voip.WithCompletionTimeout(time.Second))
+	home := q.Enqueue("06 04")
+	zoom := q.Enqueue("04 07 00")
+	time.Sleep(20 * time.Millisecond)
+
+	if n := q.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+	if !home.Cancel() {
+		t.Error("Cancel() = false for a command not sent yet")
+	}
+	if move.Cancel() {
+		t.Error("Cancel() = true for a command being sent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := move.Wait(ctx); err != nil {
+		t.Errorf("move: %v", err)
+	}
+	if err := home.Wait(ctx); !errors.Is(err, voip.ErrCanceled) {
+		t.Errorf("home: err = %v, want ErrCanceled", err)
+	}
+	if err := zoom.Wait(ctx); err != nil {
+		t.Errorf("zoom: %v", err)
+	}
+
+	for _, r := range emulator.Requests() {
+		if bytes.Equal(r, []byte{0x81, 0x01, 0x06, 0x04, 0xFF}) {
+			t.Errorf("canceled command was sent: % X", r)
+		}
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	q := voip.NewQueue(camera)
+	move := q.Enqueue("06 02 10 10 00 01 04 00 00 00 00 00", voip.WithCompletionTimeout(time.Second))
+	rest := []*voip.Job{q.Enqueue("06 04"), q.Enqueue("04 07 00")}
+	time.Sleep(20 * time.Millisecond)
+
+	q.Close()
+	if err := move.Err(); err != nil {
+		t.Errorf("move: %v", err)
+	}
+	for _, j := range rest {
+		if err := j.Err(); !errors.Is(err, voip.ErrCanceled) {
+			t.Errorf("%s: err = %v, want ErrCanceled", j.Command, err)
+		}
+	}
+	if err := q.Enqueue("06 04").Err(); !errors.Is(err, voip.ErrCanceled) {
+		t.Errorf("Enqueue after Close: err = %v, want ErrCanceled", err)
+	}
+}
@@ -0,0 +1,68 @@
+package viscaoverip
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+// Payload types of VISCA over IP messages, for SendRaw.
+const (
+	PayloadTypeVISCACommand       uint16 = 0x0100
+	PayloadTypeVISCAInquiry       uint16 = 0x0110
+	PayloadTypeVISCADeviceSetting uint16 = 0x0120
+	PayloadTypeControlCommand     uint16 = 0x0200
+	PayloadTypeVISCAReply         uint16 = 0x0111
+	PayloadTypeControlReply       uint16 = 0x0201
+)
+
+// SendRaw sends a payload of any payload type, numbered and framed like the
+// other messages, for the device setting commands and vendor specific
+// payload types that the rest of the API does not model.
+//
+// A VISCA payload (command, inquiry or device setting) is validated like a
+// packet of SendPacket, and SendRaw returns the data of its Completion, as
+// SendInquiry does. For any other payload type, SendRaw returns the whole
+// payload of the first reply with the sequence number of the message.
+//
+// A RESET control command sent with SendRaw does not reset the sequence
+// number of the Camera; use ResetSequenceNumber.
+func (c *Camera) SendRaw(payloadType uint16, payload []byte, opts ...SendOption) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetDrive()
+	if err := c.connect(context.Background()); err != nil {
+		return nil, err
+	}
+
+	o := c.sendOptions(opts)
+	if payloadType == PayloadTypeVISCAInquiry {
+		o = c.inquiryOptions(opts)
+	}
+	switch payloadType {
+	case PayloadTypeVISCACommand, PayloadTypeVISCAInquiry, PayloadTypeVISCADeviceSetting:
+		if err := validatePayload(payload); err != nil {
+			return nil, fmt.Errorf("invalid payload % X: %w", payload, err)
+		}
+	default:
+		if len(payload) == 0 || len(payload) > 0xFFFF {
+			return nil, fmt.Errorf("%w: %d bytes", ErrInvalidPayload, len(payload))
+		}
+		o.anyReply = true
+	}
+
+	message := make([]byte, HeaderSize, HeaderSize+len(payload))
+	binary.BigEndian.PutUint16(message[0:2], payloadType)
+	binary.BigEndian.PutUint16(message[2:4], uint16(len(payload)))
+	message = append(message, payload...)
+	seqNum, err := c.number(context.Background(), message)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.send(context.Background(), message, seqNum, o)
+	if err != nil {
+		return nil, err
+	}
+	return res.data, nil
+}
@@ -0,0 +1,42 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestSendRaw(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	data, err := camera.SendRaw(voip.PayloadTypeVISCAInquiry, []byte{0x81, 0x09, 0x04, 0x00, 0xFF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x02}) {
+		t.Errorf("power inquiry = % X, want 02", data)
+	}
+
+	if _, err := camera.SendRaw(voip.PayloadTypeVISCACommand, []byte{0x81, 0x01, 0x06, 0x04, 0xFF}); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x06, 0x04, 0xFF}) {
+		t.Errorf("last request = % X, want home", last)
+	}
+
+	// Control commands are answered with a bare payload
+	data, err = camera.SendRaw(voip.PayloadTypeControlCommand, []byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x01}) {
+		t.Errorf("control reply = % X, want 01", data)
+	}
+
+	if _, err := camera.SendRaw(voip.PayloadTypeVISCACommand, []byte{0x81, 0x01, 0x06, 0x04}); !errors.Is(err, voip.ErrInvalidPayload) {
+		t.Errorf("SendRaw() without terminator: err = %v, want ErrInvalidPayload", err)
+	}
+}
@@ -0,0 +1,50 @@
+package viscaoverip
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type recorder struct {
+	mu    sync.Mutex // Serializes writes
+	w     io.Writer
+	start time.Time
+}
+
+// SetRecord records the session with the camera to w, every datagram in
+// both directions with its timing, for the viscatest package to replay it
+// (see viscatest.Session). Each datagram is a line with the time since
+// recording started, its direction (> sent, < received) and its bytes in
+// hex:
+//
+//	1.203ms > 01 00 00 05 00 00 00 02 81 01 06 04 FF
+//	3.118ms < 01 11 00 03 00 00 00 02 90 41 FF
+//
+// A nil w stops recording. It may be called at any time, but a session
+// recorded from before Dial or Initialize replays deterministically, as it
+// starts with the RESET of the sequence number.
+func (c *Camera) SetRecord(w io.Writer) {
+	if w == nil {
+		c.recorder.Store(nil)
+		return
+	}
+	c.recorder.Store(&recorder{w: w, start: time.Now()})
+}
+
+// record writes a datagram to the recording, if any.
+func (c *Camera) record(direction string, b []byte) {
+	r := c.recorder.Load()
+	if r == nil {
+		return
+	}
+	arrow := ">"
+	if direction == traceRecv {
+		arrow = "<"
+	}
+	line := fmt.Sprintf("%v %s % X\n", time.Since(r.start).Round(time.Microsecond), arrow, b)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = io.WriteString(r.w, line)
+}
@@ -0,0 +1,103 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: 100})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	run := func(camera *voip.Camera, home bool) {
+		t.Helper()
+		if home {
+			camera.Home()
+		} else {
+			camera.PanTiltStop()
+		}
+		camera.Position()
+	}
+
+	// Record a session with the emulator
+	var recording bytes.Buffer
+	camera := voip.DialLazy(emulator.Addr(), cfg)
+	camera.SetRecord(&recording)
+	if err := camera.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	run(camera, true)
+	camera.Close()
+
+	session, err := viscatest.ReadSession(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RESET, IF_Clear, Home and the two position inquiries
+	if sent := countSent(session); sent != 5 {
+		t.Fatalf("recorded %d datagrams sent, want 5:\n%s", sent, recording.String())
+	}
+
+	// The same client replays identically
+	replay := func(home bool) error {
+		t.Helper()
+		r, err := session.Replay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		camera, err := voip.Dial(context.Background(), r.Addr(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		run(camera, home)
+		camera.Close()
+		return r.Err()
+	}
+	if err := replay(true); err != nil {
+		t.Errorf("replay: %v", err)
+	}
+	if err := replay(false); err == nil {
+		t.Error("replay of a different client succeeded")
+	}
+
+	// Fed back to the emulator, the camera answers as recorded
+	fed, err := session.Feed(emulator.Addr(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := received(fed), received(session); !slices.EqualFunc(got, want, bytes.Equal) {
+		t.Errorf("fed session received % X, want % X", got, want)
+	}
+}
+
+func countSent(s *viscatest.Session) int {
+	n := 0
+	for _, d := range s.Datagrams {
+		if d.Sent {
+			n++
+		}
+	}
+	return n
+}
+
+func received(s *viscatest.Session) [][]byte {
+	var datagrams [][]byte
+	for _, d := range s.Datagrams {
+		if !d.Sent {
+			datagrams = append(datagrams, d.Data)
+		}
+	}
+	return datagrams
+}
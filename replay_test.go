@@ -0,0 +1,80 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+// TestReplayCaptures drives the client through the exchanges recorded with
+// real cameras in testdata/captures.
+func TestReplayCaptures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "captures", "*.cap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no capture files")
+	}
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".cap"), func(t *testing.T) {
+			capture, err := viscatest.ReadCaptureFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			replayCapture(t, capture)
+		})
+	}
+}
+
+func replayCapture(t *testing.T, capture *viscatest.Capture) {
+	script, err := capture.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	for _, e := range capture.Exchanges {
+		if e.Want == "" {
+			continue
+		}
+		payload, _ := hex.DecodeString(strings.ReplaceAll(e.Request, " ", ""))
+		payloadType := voip.PayloadTypeVISCACommand
+		if payload[1] == 0x09 {
+			payloadType = voip.PayloadTypeVISCAInquiry
+		}
+		data, err := camera.SendRaw(payloadType, payload)
+		switch e.Want {
+		case "ok":
+			if err != nil {
+				t.Errorf("%s: %v, want ok", e.Request, err)
+			}
+		case "error":
+			if err == nil {
+				t.Errorf("%s: no error, want an error", e.Request)
+			}
+		default:
+			want, _ := hex.DecodeString(strings.ReplaceAll(e.Want, " ", ""))
+			if err != nil || !bytes.Equal(data, want) {
+				t.Errorf("%s: % X, %v, want % X", e.Request, data, err, want)
+			}
+		}
+	}
+	if err := script.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,107 @@
+package viscaoverip
+
+import "fmt"
+
+// ExposureMode is the automatic exposure mode (CAM_AE).
+type ExposureMode byte
+
+const (
+	ExposureFullAuto        ExposureMode = 0x00
+	ExposureManual          ExposureMode = 0x03
+	ExposureShutterPriority ExposureMode = 0x0A
+	ExposureIrisPriority    ExposureMode = 0x0B
+	ExposureBright          ExposureMode = 0x0D
+)
+
+// WhiteBalanceMode is the white balance mode (CAM_WB).
+type WhiteBalanceMode byte
+
+const (
+	WhiteBalanceAuto    WhiteBalanceMode = 0x00
+	WhiteBalanceIndoor  WhiteBalanceMode = 0x01
+	WhiteBalanceOutdoor WhiteBalanceMode = 0x02
+	WhiteBalanceOnePush WhiteBalanceMode = 0x03
+	WhiteBalanceATW     WhiteBalanceMode = 0x04
+	WhiteBalanceManual  WhiteBalanceMode = 0x05
+)
+
+const MaxNoiseReduction = 5
+
+// Settings is a bundle of image settings. Nil fields are left unchanged.
+type Settings struct {
+	Exposure     *ExposureMode     `json:"exposure,omitempty"`
+	WhiteBalance *WhiteBalanceMode `json:"white_balance,omitempty"`
+	// Flip turns the picture upside down (CAM_PictureFlip).
+	Flip *bool `json:"flip,omitempty"`
+	// Mirror reverses the picture left and right (CAM_LR_Reverse).
+	Mirror *bool `json:"mirror,omitempty"`
+	// NoiseReduction is the level from 0 (off) to MaxNoiseReduction.
+	NoiseReduction *int `json:"noise_reduction,omitempty"`
+	// Commands are sent as is after the settings above, for vendor specific
+	// settings such as the output format. Each is a command payload as
+	// accepted by SendCommand.
+	Commands []string `json:"commands,omitempty"`
+}
+
+func onOff(on bool) string {
+	if on {
+		return "02"
+	}
+	return "03"
+}
+
+// commands returns the command payloads applying s.
+func (s Settings) commands() ([]string, error) {
+	var cmds []string
+	if s.Exposure != nil {
+		switch *s.Exposure {
+		case ExposureFullAuto, ExposureManual, ExposureShutterPriority, ExposureIrisPriority, ExposureBright:
+		default:
+			return nil, fmt.Errorf("%w: unknown exposure mode: %#x", ErrInvalidArgument, byte(*s.Exposure))
+		}
+		cmds = append(cmds, fmt.Sprintf("04 39 %02X", byte(*s.Exposure)))
+	}
+	if s.WhiteBalance != nil {
+		if *s.WhiteBalance > WhiteBalanceManual {
+			return nil, fmt.Errorf("%w: unknown white balance mode: %#x", ErrInvalidArgument, byte(*s.WhiteBalance))
+		}
+		cmds = append(cmds, fmt.Sprintf("04 35 %02X", byte(*s.WhiteBalance)))
+	}
+	if s.Flip != nil {
+		cmds = append(cmds, "04 66 "+onOff(*s.Flip))
+	}
+	if s.Mirror != nil {
+		cmds = append(cmds, "04 61 "+onOff(*s.Mirror))
+	}
+	if s.NoiseReduction != nil {
+		if *s.NoiseReduction < 0 || *s.NoiseReduction > MaxNoiseReduction {
+			return nil, fmt.Errorf("%w: noise reduction must be between 0 and %d: %d", ErrInvalidArgument, MaxNoiseReduction, *s.NoiseReduction)
+		}
+		cmds = append(cmds, fmt.Sprintf("04 53 %02X", *s.NoiseReduction))
+	}
+	return append(cmds, s.Commands...), nil
+}
+
+// ApplySettings sends the settings of s to the camera. It stops at the
+// first failure; the settings before it remain applied.
+func (c *Camera) ApplySettings(s Settings) error {
+	cmds, err := s.commands()
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if err := c.SendCommand(cmd); err != nil {
+			return fmt.Errorf("command %s: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+// ApplySettings applies s to the named cameras concurrently, or to every
+// camera when no name is given. It returns the result of each camera: nil
+// on success, or its error, including for unknown names.
+func (m *Manager) ApplySettings(s Settings, names ...string) map[string]error {
+	return m.each(names, func(c *Camera) error {
+		return c.ApplySettings(s)
+	})
+}
@@ -0,0 +1,58 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestApplySettings(t *testing.T) {
+	m := voip.NewManager()
+	on, onEmulator := newEmulatedCamera(t)
+	onEmulator.SetState(viscatest.State{Power: true})
+	off, offEmulator := newEmulatedCamera(t)
+	offEmulator.SetState(viscatest.State{Power: false})
+	m.Add("on", on)
+	m.Add("off", off)
+
+	exposure, flip, nr := voip.ExposureManual, true, 2
+	s := voip.Settings{
+		Exposure:       &exposure,
+		Flip:           &flip,
+		NoiseReduction: &nr,
+		Commands:       []string{"7E 01 1E 00 01"},
+	}
+	results := m.ApplySettings(s)
+	if len(results) != 2 || results["on"] != nil || results["off"] == nil {
+		t.Errorf("ApplySettings() = %v, want on to succeed and off to fail", results)
+	}
+
+	want := [][]byte{
+		{0x81, 0x01, 0x04, 0x39, 0x03, 0xFF},
+		{0x81, 0x01, 0x04, 0x66, 0x02, 0xFF},
+		{0x81, 0x01, 0x04, 0x53, 0x02, 0xFF},
+		{0x81, 0x01, 0x7E, 0x01, 0x1E, 0x00, 0x01, 0xFF},
+	}
+	got := onEmulator.Requests()[1:] // After IF_Clear
+	if len(got) != len(want) {
+		t.Fatalf("got requests % X, want % X", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+
+	results = m.ApplySettings(s, "on", "missing")
+	if results["on"] != nil || results["missing"] == nil {
+		t.Errorf("ApplySettings(on, missing) = %v", results)
+	}
+
+	nr = 9
+	if err := on.ApplySettings(s); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("ApplySettings() with noise reduction 9 = %v, want ErrInvalidArgument", err)
+	}
+}
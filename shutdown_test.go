@@ -0,0 +1,82 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestShutdown(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	// Absolute pan moves of 240 units at full speed take 100ms
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	p, err := camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := camera.Shutdown(ctx, voip.ShutdownOptions{StopMotion: true}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-p.Done():
+		if p.Err() != nil {
+			t.Errorf("completion: %v", p.Err())
+		}
+	default:
+		t.Fatal("Shutdown() returned before the pending completion")
+	}
+	requests := emulator.Requests()
+	if last := requests[len(requests)-1]; !bytes.Equal(last, []byte{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}) {
+		t.Errorf("last request = % X, want pan-tilt stop", last)
+	}
+	if state := camera.State(); state != voip.StateClosed {
+		t.Errorf("State() = %v, want StateClosed", state)
+	}
+}
+
+func TestShutdownAbort(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	p, err := camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := len(emulator.Requests())
+	if err := camera.Shutdown(context.Background(), voip.ShutdownOptions{Abort: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Err(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("completion: err = %v, want net.ErrClosed", err)
+	}
+	if after := len(emulator.Requests()); after != before {
+		t.Errorf("%d requests sent without StopMotion", after-before)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 100, TiltRate: 100})
+
+	p, err := camera.SendCommandAsync("06 02 18 14 00 00 0F 00 00 00 00 00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := camera.Shutdown(ctx, voip.ShutdownOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want context.DeadlineExceeded", err)
+	}
+	if err := p.Err(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("completion: err = %v, want net.ErrClosed", err)
+	}
+}
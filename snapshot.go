@@ -0,0 +1,105 @@
+package viscaoverip
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Snapshot is the readable state of a camera, which Restore replays as
+// commands.
+type Snapshot struct {
+	Settings Settings `json:"settings"`
+	Position Position `json:"position"`
+}
+
+// Snapshot reads the settings and position of the camera. Settings the
+// camera does not answer an inquiry for are left nil, so that Restore
+// leaves them unchanged; an unresponsive camera is an error.
+func (c *Camera) Snapshot() (Snapshot, error) {
+	var s Snapshot
+	inquire := func(inquiry string) (byte, bool, error) {
+		data, err := c.SendInquiry(inquiry)
+		switch {
+		case errors.Is(err, ErrNotResponsive):
+			return 0, false, err
+		case err != nil || len(data) != 1:
+			return 0, false, nil
+		}
+		return data[0], true, nil
+	}
+	onOff := func(inquiry string) (*bool, error) {
+		v, ok, err := inquire(inquiry)
+		if !ok || (v != 0x02 && v != 0x03) {
+			return nil, err
+		}
+		on := v == 0x02
+		return &on, nil
+	}
+
+	if v, ok, err := inquire("04 39"); err != nil {
+		return s, err
+	} else if ok {
+		exposure := ExposureMode(v)
+		s.Settings.Exposure = &exposure
+	}
+	if v, ok, err := inquire("04 35"); err != nil {
+		return s, err
+	} else if ok {
+		wb := WhiteBalanceMode(v)
+		s.Settings.WhiteBalance = &wb
+	}
+	var err error
+	if s.Settings.Flip, err = onOff("04 66"); err != nil {
+		return s, err
+	}
+	if s.Settings.Mirror, err = onOff("04 61"); err != nil {
+		return s, err
+	}
+	if v, ok, err := inquire("04 53"); err != nil {
+		return s, err
+	} else if ok {
+		nr := int(v)
+		s.Settings.NoiseReduction = &nr
+	}
+
+	s.Position, err = c.Position()
+	return s, err
+}
+
+// Restore applies the settings of a snapshot, then moves to its position.
+func (c *Camera) Restore(s Snapshot) error {
+	if err := c.ApplySettings(s.Settings); err != nil {
+		return err
+	}
+	if err := c.MoveTo(s.Position.Pan, s.Position.Tilt, MaxPanSpeed, MaxTiltSpeed); err != nil {
+		return fmt.Errorf("pan-tilt position: %w", err)
+	}
+	if err := c.ZoomTo(s.Position.Zoom); err != nil {
+		return fmt.Errorf("zoom position: %w", err)
+	}
+	return nil
+}
+
+// SaveSnapshot writes a snapshot to the file at path, as JSON.
+func SaveSnapshot(path string, s Snapshot) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot.
+func LoadSnapshot(path string) (Snapshot, error) {
+	var s Snapshot
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
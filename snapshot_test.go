@@ -0,0 +1,140 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	source, sourceEmulator := newEmulatedCamera(t)
+	sourceEmulator.SetState(viscatest.State{Power: true, Pan: 100, Tilt: -50, Zoom: 0x1000})
+	exposure, flip := voip.ExposureIrisPriority, true
+	if err := source.ApplySettings(voip.Settings{Exposure: &exposure, Flip: &flip}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := source.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Settings.Exposure == nil || *snapshot.Settings.Exposure != exposure {
+		t.Errorf("exposure = %v, want %v", snapshot.Settings.Exposure, exposure)
+	}
+	if snapshot.Settings.Mirror == nil || *snapshot.Settings.Mirror {
+		t.Errorf("mirror = %v, want false", snapshot.Settings.Mirror)
+	}
+	if want := (voip.Position{Pan: 100, Tilt: -50, Zoom: 0x1000}); snapshot.Position != want {
+		t.Errorf("position = %+v, want %+v", snapshot.Position, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := voip.SaveSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := voip.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("LoadSnapshot() = %+v, want %+v", loaded, snapshot)
+	}
+
+	replacement, _ := newEmulatedCamera(t)
+	if err := replacement.Restore(loaded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := replacement.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored, snapshot) {
+		t.Errorf("restored snapshot = %+v, want %+v", restored, snapshot)
+	}
+}
+
+func TestSnapshotRestoreWidePan(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	// A camera with a wide pan range, answering positions with a 5 nibble
+	// pan, and nothing else
+	var mu sync.Mutex
+	var commands [][]byte
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		payload := msg[8:]
+		var data []byte
+		switch {
+		case bytes.Equal(payload, []byte{0x81, 0x09, 0x06, 0x12, 0xFF}):
+			data = []byte{0x00, 0x0A, 0x00, 0x00, 0x00, 0x0F, 0x0F, 0x0C, 0x0E} // 40960, -50
+		case bytes.Equal(payload, []byte{0x81, 0x09, 0x04, 0x47, 0xFF}):
+			data = []byte{0x01, 0x00, 0x00, 0x00}
+		case msg[0] == 0x01 && msg[1] == 0x10:
+			return [][]byte{makeResponse(seqNum, 0x60)}
+		default:
+			mu.Lock()
+			commands = append(commands, bytes.Clone(payload))
+			mu.Unlock()
+			return [][]byte{makeResponse(seqNum, 0x41), makeResponse(seqNum, 0x51)}
+		}
+		reply := binary.BigEndian.AppendUint16([]byte{0x01, 0x11}, uint16(len(data)+3))
+		reply = binary.BigEndian.AppendUint32(reply, seqNum)
+		reply = append(append(append(reply, 0x90, 0x50), data...), 0xFF)
+		return [][]byte{reply}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera, err := voip.NewCameraWithConfig(conn, voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	snapshot, err := camera.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (voip.Position{Pan: 40960, Tilt: -50, Zoom: 0x1000}); snapshot.Position != want {
+		t.Errorf("position = %+v, want %+v", snapshot.Position, want)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := voip.SaveSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := voip.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	commands = nil
+	mu.Unlock()
+	if err := camera.Restore(loaded); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []byte{0x81, 0x01, 0x06, 0x02, 0x18, 0x14, 0x00, 0x0A, 0x00, 0x00, 0x00, 0x0F, 0x0F, 0x0C, 0x0E, 0xFF}
+	if len(commands) == 0 || !bytes.Equal(commands[0], want) {
+		t.Errorf("commands = % X, want % X first", commands, want)
+	}
+}
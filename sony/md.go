@@ -0,0 +1,189 @@
+package sony
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// Limits of the motion detection function. Windows are numbered from 0 and
+// their areas are given in cells of a 16 by 16 grid over the picture.
+const (
+	MaxMotionDetectWindow = 3
+	MaxMotionDetectCell   = 0x0F
+)
+
+// MotionDetectArea is the rectangle of a motion detection window, in grid
+// cells, edges included.
+type MotionDetectArea struct {
+	Left, Top     int
+	Right, Bottom int
+}
+
+// SetMotionDetection turns the motion detection function on or off
+// (CAM_MD).
+func SetMotionDetection(c *voip.Camera, on bool) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	return c.SendCommand("04 1B" + onOff(on))
+}
+
+// MotionDetection reports whether the motion detection function is on.
+func MotionDetection(c *voip.Camera) (bool, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return false, err
+	}
+	data, err := c.SendInquiry("04 1B")
+	if err != nil {
+		return false, err
+	}
+	return parseOnOff(data)
+}
+
+// SetMotionDetectArea sets the area watched by a motion detection window.
+func SetMotionDetectArea(c *voip.Camera, window int, area MotionDetectArea) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if window < 0 || window > MaxMotionDetectWindow {
+		return fmt.Errorf("%w: motion detection window must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxMotionDetectWindow, window)
+	}
+	for _, v := range []int{area.Left, area.Top, area.Right, area.Bottom} {
+		if v < 0 || v > MaxMotionDetectCell {
+			return fmt.Errorf("%w: motion detection area must be within cells 0 to %d: %+v", voip.ErrInvalidArgument, MaxMotionDetectCell, area)
+		}
+	}
+	if area.Left > area.Right || area.Top > area.Bottom {
+		return fmt.Errorf("%w: motion detection area is empty: %+v", voip.ErrInvalidArgument, area)
+	}
+	return c.SendCommand(fmt.Sprintf("04 1C %02X %02X %02X %02X %02X",
+		window, area.Left, area.Top, area.Right, area.Bottom))
+}
+
+// MotionDetected returns the windows in which motion is detected, bit 0
+// being window 0.
+func MotionDetected(c *voip.Camera) (uint8, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("04 1E", voip.WithNoCache())
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 || data[0]>>(MaxMotionDetectWindow+1) != 0 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return data[0], nil
+}
+
+// MotionEvent is a change of the motion detection status.
+type MotionEvent struct {
+	// Windows are the windows in which motion is detected, bit 0 being
+	// window 0; 0 when the motion stopped.
+	Windows uint8
+	Time    time.Time
+}
+
+// MotionWatcher periodically inquires the motion detection status of a
+// camera and delivers the changes to its subscribers. It only polls while
+// it has subscribers.
+type MotionWatcher struct {
+	camera   *voip.Camera
+	interval time.Duration
+
+	mu     sync.Mutex
+	subs   map[int]chan MotionEvent
+	nextID int
+	last   uint8
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewMotionWatcher(c *voip.Camera, interval time.Duration) *MotionWatcher {
+	return &MotionWatcher{
+		camera:   c,
+		interval: interval,
+		subs:     make(map[int]chan MotionEvent),
+	}
+}
+
+// Subscribe returns a channel receiving an event whenever motion is
+// detected or stops, and a function to unsubscribe. A slow subscriber only
+// misses intermediate events, never the latest.
+func (w *MotionWatcher) Subscribe() (<-chan MotionEvent, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan MotionEvent, 1)
+	id := w.nextID
+	w.nextID++
+	w.subs[id] = ch
+	if w.stop == nil {
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.run(w.stop, w.done)
+	}
+
+	return ch, func() {
+		w.mu.Lock()
+		if _, ok := w.subs[id]; !ok {
+			w.mu.Unlock()
+			return
+		}
+		delete(w.subs, id)
+		var stop, done chan struct{}
+		if len(w.subs) == 0 {
+			stop, done = w.stop, w.done
+			w.stop, w.done = nil, nil
+			w.last = 0
+		}
+		w.mu.Unlock()
+
+		if stop != nil {
+			close(stop)
+			<-done
+		}
+	}
+}
+
+func (w *MotionWatcher) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		windows, err := MotionDetected(w.camera)
+		if err != nil {
+			w.camera.ReportError("motion detection poll", err)
+		} else {
+			w.publish(windows)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *MotionWatcher) publish(windows uint8) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if windows == w.last {
+		return
+	}
+	w.last = windows
+	ev := MotionEvent{Windows: windows, Time: time.Now()}
+	for _, ch := range w.subs {
+		// Replace an event the subscriber has not received yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- ev
+	}
+}
@@ -0,0 +1,72 @@
+package sony_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/sony"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestMotionDetectAreaRange(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorSony}}
+	full := sony.MotionDetectArea{Right: sony.MaxMotionDetectCell, Bottom: sony.MaxMotionDetectCell}
+	if err := sony.SetMotionDetectArea(camera, sony.MaxMotionDetectWindow+1, full); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetMotionDetectArea(window 4) = %v, want ErrInvalidArgument", err)
+	}
+	for _, area := range []sony.MotionDetectArea{
+		{Left: -1, Right: 1, Bottom: 1},
+		{Right: sony.MaxMotionDetectCell + 1, Bottom: 1},
+		{Left: 2, Right: 1, Bottom: 1},
+	} {
+		if err := sony.SetMotionDetectArea(camera, 0, area); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetMotionDetectArea(%+v) = %v, want ErrInvalidArgument", area, err)
+		}
+	}
+}
+
+func TestMotionWatcher(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{Vendor: voip.VendorSony, MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := sony.SetMotionDetection(camera, true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := sony.MotionDetection(camera); err != nil || !on {
+		t.Fatalf("MotionDetection() = %v, %v, want true", on, err)
+	}
+
+	events, unsubscribe := sony.NewMotionWatcher(camera, 10*time.Millisecond).Subscribe()
+	defer unsubscribe()
+	next := func() sony.MotionEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("no motion event")
+			return sony.MotionEvent{}
+		}
+	}
+
+	emulator.SetMotionDetected(0x05)
+	if ev := next(); ev.Windows != 0x05 {
+		t.Errorf("first event windows = %#x, want 0x5", ev.Windows)
+	}
+	emulator.SetMotionDetected(0)
+	if ev := next(); ev.Windows != 0 {
+		t.Errorf("second event windows = %#x, want 0", ev.Windows)
+	}
+}
@@ -0,0 +1,133 @@
+package sony
+
+import (
+	"fmt"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// MaxPrivacyMask is the highest privacy mask ID; masks are numbered from 0.
+const MaxPrivacyMask = 23
+
+// Limits of PrivacyMask, in the native units of the mask commands, where
+// the picture spans -MaxPrivacyMaskX to MaxPrivacyMaskX horizontally and
+// -MaxPrivacyMaskY to MaxPrivacyMaskY vertically.
+const (
+	MaxPrivacyMaskX = 0x50
+	MaxPrivacyMaskY = 0x2D
+)
+
+// PrivacyMask is a rectangular zone of the picture hidden by the camera.
+// X and Y are the center of the zone from the center of the picture, and
+// Width and Height its half width and half height, all in native units.
+type PrivacyMask struct {
+	X, Y          int
+	Width, Height int
+}
+
+func (m PrivacyMask) validate() error {
+	switch {
+	case m.X < -MaxPrivacyMaskX || m.X > MaxPrivacyMaskX:
+		return fmt.Errorf("%w: privacy mask X must be between %d and %d: %d", voip.ErrInvalidArgument, -MaxPrivacyMaskX, MaxPrivacyMaskX, m.X)
+	case m.Y < -MaxPrivacyMaskY || m.Y > MaxPrivacyMaskY:
+		return fmt.Errorf("%w: privacy mask Y must be between %d and %d: %d", voip.ErrInvalidArgument, -MaxPrivacyMaskY, MaxPrivacyMaskY, m.Y)
+	case m.Width < 0 || m.Width > MaxPrivacyMaskX:
+		return fmt.Errorf("%w: privacy mask width must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxPrivacyMaskX, m.Width)
+	case m.Height < 0 || m.Height > MaxPrivacyMaskY:
+		return fmt.Errorf("%w: privacy mask height must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxPrivacyMaskY, m.Height)
+	}
+	return nil
+}
+
+// byteNibbles spreads the low byte of v, two's complement for negative
+// values, over two bytes (0p 0q).
+func byteNibbles(v int) string {
+	return fmt.Sprintf("0%X 0%X", v>>4&0x0F, v&0x0F)
+}
+
+func checkPrivacyMask(id int) error {
+	if id < 0 || id > MaxPrivacyMask {
+		return fmt.Errorf("%w: privacy mask must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxPrivacyMask, id)
+	}
+	return nil
+}
+
+// SetPrivacyMask defines privacy mask id at a fixed zone of the picture
+// (CAM_PrivacyNonInterlockMask). The mask is hidden until displayed with
+// SetPrivacyMaskDisplay.
+func SetPrivacyMask(c *voip.Camera, id int, m PrivacyMask) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if err := checkPrivacyMask(id); err != nil {
+		return err
+	}
+	if err := m.validate(); err != nil {
+		return err
+	}
+	return c.SendCommand(fmt.Sprintf("04 6F %02X %s %s %s %s", id,
+		byteNibbles(m.Width), byteNibbles(m.Height), byteNibbles(m.X), byteNibbles(m.Y)))
+}
+
+// ClearPrivacyMask hides privacy mask id and shrinks its zone to nothing.
+func ClearPrivacyMask(c *voip.Camera, id int) error {
+	if err := SetPrivacyMaskDisplay(c, id, false); err != nil {
+		return err
+	}
+	return SetPrivacyMask(c, id, PrivacyMask{})
+}
+
+// SetPrivacyMasksDisplayed shows the privacy masks whose bit is set in
+// masks, bit 0 being mask 0, and hides the others (CAM_PrivacyDisplay).
+func SetPrivacyMasksDisplayed(c *voip.Camera, masks uint32) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if masks>>(MaxPrivacyMask+1) != 0 {
+		return fmt.Errorf("%w: privacy masks above %d: %#x", voip.ErrInvalidArgument, MaxPrivacyMask, masks)
+	}
+	// Six masks per byte, the last byte holding masks 0 to 5
+	return c.SendCommand(fmt.Sprintf("04 77 %02X %02X %02X %02X",
+		masks>>18&0x3F, masks>>12&0x3F, masks>>6&0x3F, masks&0x3F))
+}
+
+// PrivacyMasksDisplayed returns the displayed privacy masks, bit 0 being
+// mask 0.
+func PrivacyMasksDisplayed(c *voip.Camera) (uint32, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("04 77")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	var masks uint32
+	for _, b := range data {
+		if b > 0x3F {
+			return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+		}
+		masks = masks<<6 | uint32(b)
+	}
+	return masks, nil
+}
+
+// SetPrivacyMaskDisplay shows or hides privacy mask id, leaving the other
+// masks as they are.
+func SetPrivacyMaskDisplay(c *voip.Camera, id int, on bool) error {
+	if err := checkPrivacyMask(id); err != nil {
+		return err
+	}
+	masks, err := PrivacyMasksDisplayed(c)
+	if err != nil {
+		return err
+	}
+	if on {
+		masks |= 1 << id
+	} else {
+		masks &^= 1 << id
+	}
+	return SetPrivacyMasksDisplayed(c, masks)
+}
@@ -0,0 +1,68 @@
+package sony_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/sony"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestPrivacyMaskRanges(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorSony}}
+	for _, id := range []int{-1, sony.MaxPrivacyMask + 1} {
+		if err := sony.SetPrivacyMask(camera, id, sony.PrivacyMask{}); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPrivacyMask(%d) = %v, want ErrInvalidArgument", id, err)
+		}
+	}
+	for _, m := range []sony.PrivacyMask{
+		{X: sony.MaxPrivacyMaskX + 1},
+		{Y: -sony.MaxPrivacyMaskY - 1},
+		{Width: -1},
+		{Height: sony.MaxPrivacyMaskY + 1},
+	} {
+		if err := sony.SetPrivacyMask(camera, 0, m); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPrivacyMask(%+v) = %v, want ErrInvalidArgument", m, err)
+		}
+	}
+	if err := sony.SetPrivacyMasksDisplayed(camera, 1<<(sony.MaxPrivacyMask+1)); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetPrivacyMasksDisplayed(mask 24) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestSetPrivacyMask(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{Vendor: voip.VendorSony, MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	m := sony.PrivacyMask{X: -0x10, Y: 0x08, Width: 0x20, Height: 0x12}
+	if err := sony.SetPrivacyMask(camera, 3, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := sony.SetPrivacyMasksDisplayed(camera, 1<<23|0xFF); err != nil {
+		t.Fatal(err)
+	}
+	requests := emulator.Requests()
+	want := []string{
+		"8101046f03020001020f000008ff",
+		"810104772000033fff",
+	}
+	got := requests[len(requests)-2:]
+	for i := range want {
+		if s := hex.EncodeToString(got[i]); s != want[i] {
+			t.Errorf("request %d = %s, want %s", i, s, want[i])
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package sony provides commands and inquiries specific to Sony SRG and BRC
+// series cameras. Every function requires the camera to be configured with
+// the viscaoverip.VendorSony profile.
+package sony
+
+import (
+	"fmt"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func onOff(on bool) string {
+	if on {
+		return "02"
+	}
+	return "03"
+}
+
+// parseOnOff decodes the single 0p byte of an on/off inquiry reply.
+func parseOnOff(data []byte) (bool, error) {
+	if len(data) != 1 {
+		return false, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	switch data[0] {
+	case 0x02:
+		return true, nil
+	case 0x03:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+}
+
+// SetTally turns the tally lamp on or off. It is the red tally of
+// voip.Camera.SetTally.
+func SetTally(c *voip.Camera, on bool) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	return c.SetTally(voip.TallyRed, on)
+}
+
+// Tally reports whether the tally lamp is on.
+func Tally(c *voip.Camera) (bool, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return false, err
+	}
+	data, err := c.SendInquiry("7E 01 0A")
+	if err != nil {
+		return false, err
+	}
+	return parseOnOff(data)
+}
+
+// SetPictureProfile selects picture profile PP1 to PP6.
+func SetPictureProfile(c *voip.Camera, profile int) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if profile < 1 || profile > 6 {
+		return fmt.Errorf("%w: picture profile must be between 1 and 6: %d", voip.ErrInvalidArgument, profile)
+	}
+	return c.SendCommand(fmt.Sprintf("7E 04 5F %02x", profile-1))
+}
+
+// PictureProfile returns the selected picture profile, from 1 to 6.
+func PictureProfile(c *voip.Camera) (int, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("7E 04 5F")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 || data[0] > 0x05 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return int(data[0]) + 1, nil
+}
+
+// SetPTZSlowMode turns pan-tilt slow mode on or off. In slow mode the
+// camera moves at reduced speed for smoother on-air movement.
+func SetPTZSlowMode(c *voip.Camera, on bool) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	return c.SendCommand("06 44" + onOff(on))
+}
+
+// PTZSlowMode reports whether pan-tilt slow mode is on.
+func PTZSlowMode(c *voip.Camera) (bool, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return false, err
+	}
+	data, err := c.SendInquiry("06 44")
+	if err != nil {
+		return false, err
+	}
+	return parseOnOff(data)
+}
+
+// Defog levels; DefogOff turns defog off.
+const (
+	DefogOff = iota
+	DefogLow
+	DefogMid
+	DefogHigh
+)
+
+// SetDefog sets the defog level, from DefogOff to DefogHigh (CAM_Defog).
+func SetDefog(c *voip.Camera, level int) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if level < DefogOff || level > DefogHigh {
+		return fmt.Errorf("%w: defog level must be between %d and %d: %d", voip.ErrInvalidArgument, DefogOff, DefogHigh, level)
+	}
+	if level == DefogOff {
+		return c.SendCommand("04 37 03 00")
+	}
+	return c.SendCommand(fmt.Sprintf("04 37 02 %02X", level))
+}
+
+// Defog returns the defog level.
+func Defog(c *voip.Camera) (int, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("04 37")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 2 || data[1] > DefogHigh {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	on, err := parseOnOff(data[:1])
+	if err != nil || !on {
+		return DefogOff, err
+	}
+	return int(data[1]), nil
+}
+
+// HLC levels; HLCOff turns highlight compensation off.
+const (
+	HLCOff = iota
+	HLCLow
+	HLCHigh
+)
+
+// SetHLC sets the highlight compensation level, from HLCOff to HLCHigh
+// (CAM_HLC), which masks bright spots such as headlights.
+func SetHLC(c *voip.Camera, level int) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if level < HLCOff || level > HLCHigh {
+		return fmt.Errorf("%w: HLC level must be between %d and %d: %d", voip.ErrInvalidArgument, HLCOff, HLCHigh, level)
+	}
+	return c.SendCommand(fmt.Sprintf("04 14 %02X 00", level))
+}
+
+// HLC returns the highlight compensation level.
+func HLC(c *voip.Camera) (int, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("04 14")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 2 || data[0] > HLCHigh {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return int(data[0]), nil
+}
+
+// MaxPresetSpeed is the fastest preset recall speed.
+const MaxPresetSpeed = 0x18
+
+// SetPresetSpeed sets the speed of preset recalls, from 1 to MaxPresetSpeed:
+// slow for smooth on-air moves, fast to reposition while off air.
+func SetPresetSpeed(c *voip.Camera, speed int) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if speed < 1 || speed > MaxPresetSpeed {
+		return fmt.Errorf("%w: preset speed must be between 1 and %d: %d", voip.ErrInvalidArgument, MaxPresetSpeed, speed)
+	}
+	return c.SendCommand(fmt.Sprintf("7E 01 0B %02X", speed))
+}
+
+// PresetSpeed returns the speed of preset recalls.
+func PresetSpeed(c *voip.Camera) (int, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("7E 01 0B")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 || data[0] < 1 || data[0] > MaxPresetSpeed {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return int(data[0]), nil
+}
+
+// RampCurve is the acceleration profile of pan-tilt movements.
+type RampCurve byte
+
+const (
+	RampSharp    RampCurve = 0x01
+	RampStandard RampCurve = 0x02
+	RampGentle   RampCurve = 0x03
+)
+
+// SetPanTiltRampCurve sets the acceleration profile of pan-tilt movements; gentle
+// curves ease in and out for on-air moves.
+func SetPanTiltRampCurve(c *voip.Camera, curve RampCurve) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if curve < RampSharp || curve > RampGentle {
+		return fmt.Errorf("%w: unknown ramp curve: %d", voip.ErrInvalidArgument, curve)
+	}
+	return c.SendCommand(fmt.Sprintf("06 31 %02X", byte(curve)))
+}
+
+// PanTiltRampCurve returns the acceleration profile of pan-tilt movements.
+func PanTiltRampCurve(c *voip.Camera) (RampCurve, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return 0, err
+	}
+	data, err := c.SendInquiry("06 31")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 || RampCurve(data[0]) < RampSharp || RampCurve(data[0]) > RampGentle {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return RampCurve(data[0]), nil
+}
+
+// videoFormats are the values of the video format register (72) of the
+// SRG-X and BRC-X series.
+var videoFormats = map[voip.VideoFormat]int{
+	voip.Format1080i5994: 0x01,
+	voip.Format720p5994:  0x04,
+	voip.Format1080p2997: 0x06,
+	voip.Format1080i50:   0x08,
+	voip.Format720p50:    0x09,
+	voip.Format1080p25:   0x0B,
+	voip.Format1080p5994: 0x13,
+	voip.Format1080p50:   0x14,
+	voip.Format2160p2997: 0x1B,
+	voip.Format2160p25:   0x1C,
+}
+
+// SetVideoFormat sets the resolution and frame rate of the outputs. The
+// camera applies the new format after it restarts.
+func SetVideoFormat(c *voip.Camera, format voip.VideoFormat) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	v, ok := videoFormats[format]
+	if !ok {
+		return fmt.Errorf("%w: unsupported video format: %s", voip.ErrInvalidArgument, format)
+	}
+	return c.SendCommand(fmt.Sprintf("04 24 72 0%X 0%X", v>>4, v&0x0F))
+}
+
+// VideoFormat returns the video format register, which is the active
+// format unless it was changed since the camera started.
+func VideoFormat(c *voip.Camera) (voip.VideoFormat, error) {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return "", err
+	}
+	data, err := c.SendInquiry("04 24 72")
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 2 {
+		v := int(data[0]&0x0F)<<4 | int(data[1]&0x0F)
+		for format, code := range videoFormats {
+			if code == v {
+				return format, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unexpected inquiry reply data: %x", data)
+}
@@ -0,0 +1,92 @@
+package sony_test
+
+import (
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/sony"
+)
+
+func TestVendorGating(t *testing.T) {
+	// No connection is needed: gated commands must fail before any I/O.
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorPTZOptics}}
+
+	tests := []struct {
+		name string
+		fn   func(*voip.Camera) error
+	}{
+		{"SetTally", func(c *voip.Camera) error { return sony.SetTally(c, true) }},
+		{"Tally", func(c *voip.Camera) error { _, err := sony.Tally(c); return err }},
+		{"SetPictureProfile", func(c *voip.Camera) error { return sony.SetPictureProfile(c, 1) }},
+		{"PictureProfile", func(c *voip.Camera) error { _, err := sony.PictureProfile(c); return err }},
+		{"SetPTZSlowMode", func(c *voip.Camera) error { return sony.SetPTZSlowMode(c, true) }},
+		{"PTZSlowMode", func(c *voip.Camera) error { _, err := sony.PTZSlowMode(c); return err }},
+		{"SetDefog", func(c *voip.Camera) error { return sony.SetDefog(c, sony.DefogLow) }},
+		{"Defog", func(c *voip.Camera) error { _, err := sony.Defog(c); return err }},
+		{"SetHLC", func(c *voip.Camera) error { return sony.SetHLC(c, sony.HLCLow) }},
+		{"HLC", func(c *voip.Camera) error { _, err := sony.HLC(c); return err }},
+		{"SetPresetSpeed", func(c *voip.Camera) error { return sony.SetPresetSpeed(c, 1) }},
+		{"PresetSpeed", func(c *voip.Camera) error { _, err := sony.PresetSpeed(c); return err }},
+		{"SetPanTiltRampCurve", func(c *voip.Camera) error { return sony.SetPanTiltRampCurve(c, sony.RampGentle) }},
+		{"PanTiltRampCurve", func(c *voip.Camera) error { _, err := sony.PanTiltRampCurve(c); return err }},
+		{"SetVideoFormat", func(c *voip.Camera) error { return sony.SetVideoFormat(c, voip.Format1080p5994) }},
+		{"VideoFormat", func(c *voip.Camera) error { _, err := sony.VideoFormat(c); return err }},
+		{"SetPrivacyMask", func(c *voip.Camera) error { return sony.SetPrivacyMask(c, 0, sony.PrivacyMask{}) }},
+		{"SetPrivacyMasksDisplayed", func(c *voip.Camera) error { return sony.SetPrivacyMasksDisplayed(c, 1) }},
+		{"PrivacyMasksDisplayed", func(c *voip.Camera) error { _, err := sony.PrivacyMasksDisplayed(c); return err }},
+		{"SetPrivacyMaskDisplay", func(c *voip.Camera) error { return sony.SetPrivacyMaskDisplay(c, 0, true) }},
+		{"ClearPrivacyMask", func(c *voip.Camera) error { return sony.ClearPrivacyMask(c, 0) }},
+		{"SetTitle", func(c *voip.Camera) error { return sony.SetTitle(c, 0, 0, "CAM") }},
+		{"ClearTitle", func(c *voip.Camera) error { return sony.ClearTitle(c, 0) }},
+		{"ClearTitles", sony.ClearTitles},
+		{"SetMotionDetection", func(c *voip.Camera) error { return sony.SetMotionDetection(c, true) }},
+		{"MotionDetection", func(c *voip.Camera) error { _, err := sony.MotionDetection(c); return err }},
+		{"SetMotionDetectArea", func(c *voip.Camera) error { return sony.SetMotionDetectArea(c, 0, sony.MotionDetectArea{}) }},
+		{"MotionDetected", func(c *voip.Camera) error { _, err := sony.MotionDetected(c); return err }},
+		{"SetTitleDisplay", func(c *voip.Camera) error { return sony.SetTitleDisplay(c, 0, true) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.fn(camera); !errors.Is(err, voip.ErrUnsupported) {
+				t.Errorf("%s() = %v, want ErrUnsupported", tc.name, err)
+			}
+		})
+	}
+}
+
+func TestSetPictureProfileRange(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorSony}}
+	for _, profile := range []int{0, 7} {
+		if err := sony.SetPictureProfile(camera, profile); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPictureProfile(%d) = %v, want ErrInvalidArgument", profile, err)
+		}
+	}
+}
+
+func TestLevelRanges(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorSony}}
+	for _, level := range []int{-1, sony.DefogHigh + 1} {
+		if err := sony.SetDefog(camera, level); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetDefog(%d) = %v, want ErrInvalidArgument", level, err)
+		}
+	}
+	for _, speed := range []int{0, sony.MaxPresetSpeed + 1} {
+		if err := sony.SetPresetSpeed(camera, speed); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPresetSpeed(%d) = %v, want ErrInvalidArgument", speed, err)
+		}
+	}
+	for _, curve := range []sony.RampCurve{0, sony.RampGentle + 1} {
+		if err := sony.SetPanTiltRampCurve(camera, curve); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetPanTiltRampCurve(%d) = %v, want ErrInvalidArgument", curve, err)
+		}
+	}
+	if err := sony.SetVideoFormat(camera, voip.Format720p2997); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("SetVideoFormat(720p29.97) = %v, want ErrInvalidArgument", err)
+	}
+	for _, level := range []int{-1, sony.HLCHigh + 1} {
+		if err := sony.SetHLC(camera, level); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetHLC(%d) = %v, want ErrInvalidArgument", level, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package sony
+
+import (
+	"fmt"
+	"strings"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// Limits of the on-screen titles.
+const (
+	MaxTitleLine     = 0x0A // Lines are numbered from 0
+	MaxTitlePosition = 0x17 // Horizontal position, in characters
+	MaxTitleLength   = 20
+)
+
+// titleChars are the codes of the supported title characters; the title
+// character set has no lower case letters.
+var titleChars = func() map[rune]byte {
+	m := map[rune]byte{'&': 0x1A, ' ': 0x1B, '?': 0x1C, '!': 0x1D, '0': 0x27}
+	for r := 'A'; r <= 'Z'; r++ {
+		m[r] = byte(r - 'A')
+	}
+	for r := '1'; r <= '9'; r++ {
+		m[r] = byte(0x1E + r - '1')
+	}
+	return m
+}()
+
+// encodeTitle returns the character codes of text, padded with spaces to
+// MaxTitleLength.
+func encodeTitle(text string) ([]byte, error) {
+	codes := make([]byte, 0, MaxTitleLength)
+	for _, r := range strings.ToUpper(text) {
+		code, ok := titleChars[r]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported title character: %q", voip.ErrInvalidArgument, r)
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) > MaxTitleLength {
+		return nil, fmt.Errorf("%w: title must be at most %d characters: %q", voip.ErrInvalidArgument, MaxTitleLength, text)
+	}
+	for len(codes) < MaxTitleLength {
+		codes = append(codes, titleChars[' '])
+	}
+	return codes, nil
+}
+
+func checkTitleLine(line int) error {
+	if line < 0 || line > MaxTitleLine {
+		return fmt.Errorf("%w: title line must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxTitleLine, line)
+	}
+	return nil
+}
+
+// SetTitle sets the text of a title line, starting position characters
+// from the left edge. Letters, digits, spaces and & ? ! are supported. The
+// title is shown with SetTitleDisplay.
+func SetTitle(c *voip.Camera, line, position int, text string) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if err := checkTitleLine(line); err != nil {
+		return err
+	}
+	if position < 0 || position > MaxTitlePosition {
+		return fmt.Errorf("%w: title position must be between 0 and %d: %d", voip.ErrInvalidArgument, MaxTitlePosition, position)
+	}
+	codes, err := encodeTitle(text)
+	if err != nil {
+		return err
+	}
+
+	// Title Set1 places the line (white, not blinking), Set2 and Set3 carry
+	// ten characters each
+	if err := c.SendCommand(fmt.Sprintf("04 73 1%X 00 %02X 00 00 00 00 00 00 00 00", line, position)); err != nil {
+		return err
+	}
+	for i, chunk := range [][]byte{codes[:10], codes[10:]} {
+		if err := c.SendCommand(fmt.Sprintf("04 73 %X%X % X", i+2, line, chunk)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearTitle erases a title line.
+func ClearTitle(c *voip.Camera, line int) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if err := checkTitleLine(line); err != nil {
+		return err
+	}
+	return c.SendCommand(fmt.Sprintf("04 74 1%X", line))
+}
+
+// ClearTitles erases every title line.
+func ClearTitles(c *voip.Camera) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	return c.SendCommand("04 74 1F")
+}
+
+// SetTitleDisplay shows or hides a title line.
+func SetTitleDisplay(c *voip.Camera, line int, on bool) error {
+	if err := c.RequireVendor(voip.VendorSony); err != nil {
+		return err
+	}
+	if err := checkTitleLine(line); err != nil {
+		return err
+	}
+	if on {
+		return c.SendCommand(fmt.Sprintf("04 74 2%X", line))
+	}
+	return c.SendCommand(fmt.Sprintf("04 74 3%X", line))
+}
@@ -0,0 +1,64 @@
+package sony_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/sony"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestTitleRanges(t *testing.T) {
+	camera := &voip.Camera{Config: voip.Config{Vendor: voip.VendorSony}}
+	tests := []struct {
+		line, position int
+		text           string
+	}{
+		{-1, 0, "CAM"},
+		{sony.MaxTitleLine + 1, 0, "CAM"},
+		{0, sony.MaxTitlePosition + 1, "CAM"},
+		{0, 0, "CAMERA NUMBER ONE ON STAGE"},
+		{0, 0, "CAM #1"},
+	}
+	for _, tc := range tests {
+		if err := sony.SetTitle(camera, tc.line, tc.position, tc.text); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetTitle(%d, %d, %q) = %v, want ErrInvalidArgument", tc.line, tc.position, tc.text, err)
+		}
+	}
+}
+
+func TestSetTitle(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{Vendor: voip.VendorSony, MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := sony.SetTitle(camera, 2, 4, "Cam 10!"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"810104731200040000000000000000ff",
+		"810104732202000c1b1e271d1b1b1bff",
+		"81010473321b1b1b1b1b1b1b1b1b1bff",
+	}
+	requests := emulator.Requests()[1:]
+	if len(requests) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(requests), len(want))
+	}
+	for i := range want {
+		if s := hex.EncodeToString(requests[i]); s != want[i] {
+			t.Errorf("request %d = %s, want %s", i, s, want[i])
+		}
+	}
+}
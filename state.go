@@ -0,0 +1,118 @@
+package viscaoverip
+
+import (
+	"errors"
+	"fmt"
+)
+
+const DefaultOfflineAfterMisses = 3
+
+var ErrNotResponsive = errors.New("peripheral device is not responsive")
+
+// State describes the health of the connection to the peripheral device,
+// derived from the replies (or lack thereof) to the actual traffic.
+type State int
+
+const (
+	// StateInitializing means the camera is not initialized yet, or is being
+	// initialized again by Reconnect.
+	StateInitializing State = iota
+	// StateConnected means the peripheral device answers as expected.
+	StateConnected
+	// StateDegraded means recent exchanges went unanswered.
+	StateDegraded
+	// StateOffline means the peripheral device stopped answering.
+	StateOffline
+	// StateClosed means Close has been called.
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateInitializing:
+		return "initializing"
+	case StateConnected:
+		return "connected"
+	case StateDegraded:
+		return "degraded"
+	case StateOffline:
+		return "offline"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// State returns the current connection state.
+func (c *Camera) State() State {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.state
+}
+
+// OnStateChange registers fn to be called on every state transition, and
+// returns a function that unregisters it.
+//
+// fn is called synchronously from the goroutine whose exchange caused the
+// transition, while the camera is busy. It must return quickly and must not
+// call methods of the Camera other than State.
+func (c *Camera) OnStateChange(fn func(from, to State)) (unsubscribe func()) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.stateListeners == nil {
+		c.stateListeners = make(map[int]func(from, to State))
+	}
+	id := c.nextListenerID
+	c.nextListenerID++
+	c.stateListeners[id] = fn
+	return func() {
+		c.stateMu.Lock()
+		defer c.stateMu.Unlock()
+		delete(c.stateListeners, id)
+	}
+}
+
+func (c *Camera) setState(to State) {
+	c.stateMu.Lock()
+	from := c.state
+	// Only a new initialization leaves the closed state
+	if from == to || (from == StateClosed && to != StateInitializing) {
+		c.stateMu.Unlock()
+		return
+	}
+	c.state = to
+	listeners := make([]func(from, to State), 0, len(c.stateListeners))
+	for _, fn := range c.stateListeners {
+		listeners = append(listeners, fn)
+	}
+	c.stateMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(from, to)
+	}
+}
+
+// recordReply marks that the peripheral device answered an exchange.
+// c.mu must be held.
+func (c *Camera) recordReply() {
+	c.misses = 0
+	c.setState(StateConnected)
+}
+
+// recordMiss marks that an exchange went unanswered. c.mu must be held.
+func (c *Camera) recordMiss() {
+	c.misses++
+	maxMisses := c.Config.OfflineAfterMisses
+	if maxMisses <= 0 {
+		maxMisses = DefaultOfflineAfterMisses
+	}
+	if c.misses >= maxMisses {
+		c.setState(StateOffline)
+	} else if c.State() != StateInitializing {
+		c.setState(StateDegraded)
+	}
+	if w := c.Config.WatchdogMisses; w > 0 && c.misses%w == 0 {
+		c.triggerWatchdog()
+	}
+}
@@ -0,0 +1,127 @@
+package viscaoverip
+
+import "fmt"
+
+// changed reports whether want is set and differs from cur, which is
+// unknown if nil.
+func changed[T comparable](cur, want *T) bool {
+	return want != nil && (cur == nil || *cur != *want)
+}
+
+// Diff returns the command payloads that bring a camera in state s to the
+// state want, in the order they must be sent: power first, position last.
+// Fields of want that are nil or already equal in s send nothing.
+func (s CameraState) Diff(want CameraState) ([]string, error) {
+	var cmds []string
+	if changed(s.Power, want.Power) {
+		cmds = append(cmds, "04 00 "+onOff(*want.Power))
+	}
+
+	settings := Settings{}
+	if changed(s.Exposure, want.Exposure) {
+		settings.Exposure = want.Exposure
+	}
+	if changed(s.WhiteBalance, want.WhiteBalance) {
+		settings.WhiteBalance = want.WhiteBalance
+	}
+	if changed(s.Flip, want.Flip) {
+		settings.Flip = want.Flip
+	}
+	if changed(s.Mirror, want.Mirror) {
+		settings.Mirror = want.Mirror
+	}
+	if changed(s.NoiseReduction, want.NoiseReduction) {
+		settings.NoiseReduction = want.NoiseReduction
+	}
+	settingCmds, err := settings.commands()
+	if err != nil {
+		return nil, err
+	}
+	cmds = append(cmds, settingCmds...)
+
+	onOffs := []struct {
+		cmd       string
+		cur, want *bool
+	}{
+		{"04 3E", s.ExposureCompensationOn, want.ExposureCompensationOn},
+		{"04 33", s.Backlight, want.Backlight},
+		{"04 01", s.NightMode, want.NightMode},
+		{"04 06", s.DigitalZoom, want.DigitalZoom},
+		{"04 62", s.Freeze, want.Freeze},
+	}
+	for _, o := range onOffs {
+		if changed(o.cur, o.want) {
+			cmds = append(cmds, o.cmd+" "+onOff(*o.want))
+		}
+	}
+
+	directs := []struct {
+		name      string
+		register  byte
+		min, max  int
+		cur, want *int
+	}{
+		{"exposure compensation", 0x4E, MinExposureCompensation, MaxExposureCompensation, s.ExposureCompensation, want.ExposureCompensation},
+		{"iris", 0x4B, 0, 0xFF, s.Iris, want.Iris},
+		{"gain", 0x4C, 0, 0xFF, s.Gain, want.Gain},
+		{"shutter", 0x4A, 0, 0xFF, s.Shutter, want.Shutter},
+		{"color gain", 0x49, 0, MaxColorGain, s.ColorGain, want.ColorGain},
+		{"hue", 0x4F, MinHue, MaxHue, s.Hue, want.Hue},
+		{"aperture", 0x42, 0, MaxAperture, s.Aperture, want.Aperture},
+	}
+	for _, d := range directs {
+		if !changed(d.cur, d.want) {
+			continue
+		}
+		if err := checkRange(d.name, *d.want, d.min, d.max); err != nil {
+			return nil, err
+		}
+		// Signed registers are offset to start from zero
+		cmds = append(cmds, directCommand(d.register, *d.want-d.min))
+	}
+	if changed(s.Gamma, want.Gamma) {
+		if err := checkRange("gamma", *want.Gamma, 0, MaxGamma); err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, fmt.Sprintf("04 5B %02X", *want.Gamma))
+	}
+
+	if want.Position != nil {
+		var cur Position
+		if s.Position != nil {
+			cur = *s.Position
+		}
+		if s.Position == nil || cur.Pan != want.Position.Pan || cur.Tilt != want.Position.Tilt {
+			cmd, err := moveToCommand(want.Position.Pan, want.Position.Tilt, MaxPanSpeed, MaxTiltSpeed)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, cmd)
+		}
+		if s.Position == nil || cur.Zoom != want.Position.Zoom {
+			cmd, err := zoomToCommand(want.Position.Zoom)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds, nil
+}
+
+// ApplyState sends the commands of current.Diff(want), so that applying a
+// look to a camera only sends what differs. current is usually the result
+// of ReadFullState. It stops at the first failure; the commands before it
+// remain applied.
+func (c *Camera) ApplyState(current, want CameraState) error {
+	cmds, err := current.Diff(want)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if err := c.SendCommand(cmd); err != nil {
+			return fmt.Errorf("command %s: %w", cmd, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package viscaoverip_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestCameraStateDiff(t *testing.T) {
+	current := voip.CameraState{
+		Power:    ptr(true),
+		Hue:      ptr(0),
+		Flip:     ptr(false),
+		Gamma:    ptr(0),
+		Position: &voip.Position{},
+	}
+	want := voip.CameraState{
+		Power:    ptr(true),
+		Hue:      ptr(-2),
+		Flip:     ptr(true),
+		Gamma:    ptr(0),
+		Iris:     ptr(5), // Unknown in current
+		Position: &voip.Position{Zoom: 0x100},
+	}
+	got, err := current.Diff(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCmds := []string{"04 66 02", "04 4B 00 00 00 05", "04 4F 00 00 00 05", "04 47 00 01 00 00"}
+	if !reflect.DeepEqual(got, wantCmds) {
+		t.Errorf("Diff() = %q, want %q", got, wantCmds)
+	}
+
+	if cmds, err := want.Diff(want); err != nil || len(cmds) != 0 {
+		t.Errorf("Diff() of equal states = %q, %v, want none", cmds, err)
+	}
+	if _, err := current.Diff(voip.CameraState{Hue: ptr(voip.MaxHue + 1)}); !errors.Is(err, voip.ErrInvalidArgument) {
+		t.Errorf("Diff() out of range = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestApplyState(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	current, err := camera.ReadFullState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := voip.CameraState{
+		ColorGain: ptr(9),
+		Mirror:    ptr(true),
+		Position:  &voip.Position{Pan: 50, Tilt: 10, Zoom: current.Position.Zoom + 0x100},
+	}
+	before := len(emulator.Requests())
+	if err := camera.ApplyState(current, want); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(emulator.Requests()) - before; n != 4 {
+		t.Errorf("ApplyState() sent %d commands, want 4", n)
+	}
+
+	after, err := camera.ReadFullState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmds, err := after.Diff(want); err != nil || len(cmds) != 0 {
+		t.Errorf("Diff() after ApplyState() = %q, %v, want none", cmds, err)
+	}
+}
@@ -0,0 +1,90 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestStateTransitions(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var silent atomic.Bool
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			return [][]byte{makeResetResponse()}
+		}
+		if silent.Load() {
+			return nil
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{
+		MaxRetries:         1,
+		Timeout:            20 * time.Millisecond,
+		OfflineAfterMisses: 2,
+	}
+	camera := voip.New(conn, cfg)
+	if state := camera.State(); state != voip.StateInitializing {
+		t.Errorf("State() before Initialize = %v, want %v", state, voip.StateInitializing)
+	}
+
+	var mu sync.Mutex
+	var got []voip.State
+	unsubscribe := camera.OnStateChange(func(from, to voip.State) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, to)
+	})
+
+	if err := camera.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	silent.Store(true)
+	_ = camera.SendCommand("06 04")
+	_ = camera.SendCommand("06 04")
+	silent.Store(false)
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Fatal(err)
+	}
+	camera.Close()
+
+	unsubscribe()
+	_ = camera.Reconnect(context.Background())
+	camera.Close()
+
+	want := []voip.State{
+		voip.StateConnected,
+		voip.StateDegraded,
+		voip.StateOffline,
+		voip.StateConnected,
+		voip.StateClosed,
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package viscaoverip
+
+import "fmt"
+
+// TallyColor is the color of a tally lamp.
+type TallyColor int
+
+const (
+	// TallyRed is the program (on-air) tally.
+	TallyRed TallyColor = iota
+	// TallyGreen is the preview tally, on Sony cameras only.
+	TallyGreen
+)
+
+func (t TallyColor) String() string {
+	switch t {
+	case TallyRed:
+		return "red"
+	case TallyGreen:
+		return "green"
+	default:
+		return fmt.Sprintf("TallyColor(%d)", int(t))
+	}
+}
+
+// SetTally turns a tally lamp on or off, through the same connection as the
+// PTZ commands, so that a switcher integration needs no other link. The red
+// tally requires the Sony or PTZOptics profile, the green one the Sony
+// profile.
+func (c *Camera) SetTally(color TallyColor, on bool) error {
+	switch color {
+	case TallyRed:
+		if err := c.RequireVendor(VendorSony, VendorPTZOptics); err != nil {
+			return err
+		}
+		return c.SendCommand("7E 01 0A 00 " + onOff(on))
+	case TallyGreen:
+		if err := c.RequireVendor(VendorSony); err != nil {
+			return err
+		}
+		return c.SendCommand("7E 01 0A 01 " + onOff(on))
+	default:
+		return fmt.Errorf("%w: unknown tally color: %v", ErrInvalidArgument, color)
+	}
+}
@@ -0,0 +1,42 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestSetTally(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	if err := camera.SetTally(voip.TallyRed, true); !errors.Is(err, voip.ErrUnsupported) {
+		t.Errorf("SetTally() with the generic profile = %v, want ErrUnsupported", err)
+	}
+	camera.Config.Vendor = voip.VendorPTZOptics
+	if err := camera.SetTally(voip.TallyGreen, true); !errors.Is(err, voip.ErrUnsupported) {
+		t.Errorf("SetTally(green) with the PTZOptics profile = %v, want ErrUnsupported", err)
+	}
+
+	camera.Config.Vendor = voip.VendorSony
+	if err := camera.SetTally(voip.TallyRed, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.SetTally(voip.TallyGreen, false); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]byte{
+		{0x81, 0x01, 0x7E, 0x01, 0x0A, 0x00, 0x02, 0xFF},
+		{0x81, 0x01, 0x7E, 0x01, 0x0A, 0x01, 0x03, 0xFF},
+	}
+	got := emulator.Requests()[1:] // After IF_Clear
+	if len(got) != len(want) {
+		t.Fatalf("requests = % X, want % X", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package textcontrol serves a line based text protocol over TCP, for stream
+// decks, Bitfocus Companion buttons and netcat scripts. Each line names a
+// camera of a Manager and an action, as in a cue list (see package cues):
+//
+//	cam1 preset 3
+//	cam2 ptz 12 -5
+//	cam2 stop
+//	cam1 command 04 07 02
+//
+// Each line is answered with "OK", or "ERR" followed by the error, once the
+// action is done. Empty lines and lines starting with # are ignored.
+package textcontrol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/cues"
+)
+
+type Config struct {
+	// ErrorHandler, if set, is called with the error of each line that
+	// fails, besides answering it with "ERR".
+	ErrorHandler func(error)
+}
+
+type Server struct {
+	manager *voip.Manager
+	cfg     Config
+}
+
+func New(m *voip.Manager, cfg Config) *Server {
+	return &Server{manager: m, cfg: cfg}
+}
+
+// Serve accepts connections on l and runs the commands they send until
+// accepting fails, e.g. after l is closed. The lines of a connection are
+// handled in order.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		reply := "OK\n"
+		if err := s.Handle(line); err != nil {
+			if s.cfg.ErrorHandler != nil {
+				s.cfg.ErrorHandler(fmt.Errorf("%s: %w", line, err))
+			}
+			reply = fmt.Sprintf("ERR %v\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle runs the command of a line, e.g. "cam1 preset 3".
+func (s *Server) Handle(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return errors.New("want camera and action")
+	}
+	cue := cues.Cue{Camera: fields[0], Action: fields[1], Params: strings.Join(fields[2:], " ")}
+	if err := cue.Validate(); err != nil {
+		return err
+	}
+	return cue.Run(s.manager)
+}
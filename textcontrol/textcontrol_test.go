@@ -0,0 +1,88 @@
+package textcontrol_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/textcontrol"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestServer(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	m.Add("cam1", camera)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	errs := make(chan error, 10)
+	handler := func(err error) { errs <- err }
+	go textcontrol.New(m, textcontrol.Config{ErrorHandler: handler}).Serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	before := len(emulator.Requests())
+	for _, tc := range []struct {
+		line, reply string
+		sent        []byte
+	}{
+		{"cam1 ptz 12 -5", "OK", []byte{0x81, 0x01, 0x06, 0x01, 0x0C, 0x05, 0x02, 0x02, 0xFF}},
+		{"cam1 home", "OK", []byte{0x81, 0x01, 0x06, 0x04, 0xFF}},
+		{"cam2 home", "ERR unknown camera: cam2", nil},
+		{"cam1 preset", "ERR preset takes 1 parameters, got 0", nil},
+		{"cam1", "ERR want camera and action", nil},
+	} {
+		fmt.Fprintf(conn, "\n# comment\n%s\n", tc.line)
+		reply, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply = strings.TrimSpace(reply); reply != tc.reply {
+			t.Errorf("%s: reply %q, want %q", tc.line, reply, tc.reply)
+		}
+		requests := emulator.Requests()[before:]
+		before += len(requests)
+		if tc.sent != nil && (len(requests) == 0 || !bytes.Equal(requests[0], tc.sent)) {
+			t.Errorf("%s: sent % X, want % X first", tc.line, requests, tc.sent)
+		}
+		if tc.sent == nil && len(requests) != 0 {
+			t.Errorf("%s: sent % X, want nothing", tc.line, requests)
+		}
+		select {
+		case err := <-errs:
+			if want := tc.line + ": " + strings.TrimPrefix(tc.reply, "ERR "); err.Error() != want {
+				t.Errorf("%s: handled error %q, want %q", tc.line, err, want)
+			}
+		default:
+			if tc.reply != "OK" {
+				t.Errorf("%s: error not handled", tc.line)
+			}
+		}
+	}
+}
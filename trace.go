@@ -0,0 +1,49 @@
+package viscaoverip
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	traceSend = "send"
+	traceRecv = "recv"
+)
+
+type tracer struct {
+	w io.Writer
+}
+
+// SetTrace dumps every datagram sent to and received from the camera to w,
+// with a timestamp and its direction, for field troubleshooting. Each
+// datagram is written with a single Write call. A nil w stops tracing. It
+// may be called at any time.
+func (c *Camera) SetTrace(w io.Writer) {
+	if w == nil {
+		c.tracer.Store(nil)
+		return
+	}
+	c.tracer.Store(&tracer{w: w})
+}
+
+// trace dumps a datagram if tracing is enabled, and records it if recording
+// is. Failed I/O is not traced.
+func (c *Camera) trace(direction string, addr net.Addr, b []byte, err error) {
+	if err != nil {
+		return
+	}
+	c.record(direction, b)
+	t := c.tracer.Load()
+	if t == nil {
+		return
+	}
+	peer := "?"
+	if addr != nil {
+		peer = addr.String()
+	}
+	dump := fmt.Sprintf("%s %s %s %d bytes\n%s", time.Now().Format("15:04:05.000000"), direction, peer, len(b), hex.Dump(b))
+	_, _ = io.WriteString(t.w, dump)
+}
@@ -0,0 +1,35 @@
+package viscaoverip_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+
+	var buf bytes.Buffer
+	camera.SetTrace(&buf)
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+	camera.SetTrace(nil)
+	if err := camera.Home(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("trace has %d lines, want a header and a dump line for each of 3 datagrams:\n%s", len(lines), buf.String())
+	}
+	for i, direction := range []string{"send", "recv", "recv"} {
+		header := strings.Fields(lines[2*i])
+		if len(header) != 5 || header[1] != direction || header[2] != emulator.Addr() {
+			t.Errorf("header %d = %q, want %s from/to %s", i, lines[2*i], direction, emulator.Addr())
+		}
+	}
+	if !strings.Contains(lines[1], "81 01 06 04 ff") {
+		t.Errorf("dump of sent message = %q", lines[1])
+	}
+}
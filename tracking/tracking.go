@@ -0,0 +1,238 @@
+// Package tracking lets face tracking and AI framing systems drive a camera.
+//
+// A TargetSource streams the position of the subject in the picture, and a
+// Follower converts it into pan, tilt and zoom drive commands that bring the
+// subject back to the center of the frame: proportional to the offset, with
+// optional integral and derivative terms for pan and tilt.
+package tracking
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+// Target is the subject as seen in the picture.
+type Target struct {
+	// X and Y are the offset of the subject from the center of the frame,
+	// from -1 to 1: right and up are positive.
+	X, Y float64
+	// Size is the height of the subject as a fraction of the frame height,
+	// or zero if unknown.
+	Size float64
+	// Lost reports that no subject is in view. The camera stops.
+	Lost bool
+	// Time is when the subject was seen, for the integral and derivative
+	// terms. Zero means when the target is received.
+	Time time.Time
+}
+
+// TargetSource is a stream of targets, such as the output of a face
+// detector.
+type TargetSource interface {
+	// Targets returns a channel delivering the targets until ctx is done.
+	// The source closes the channel when it ends.
+	Targets(ctx context.Context) (<-chan Target, error)
+}
+
+const (
+	DefaultGain     = 1.0
+	DefaultDeadZone = 0.05
+	DefaultTimeout  = 500 * time.Millisecond
+
+	DefaultIntegralLimit = 1.0
+)
+
+type FollowerConfig struct {
+	// PanGain and TiltGain scale the speed to the offset: with gain 1, an
+	// offset of 1 drives at the maximum speed. Default to DefaultGain.
+	PanGain  float64
+	TiltGain float64
+	// ZoomGain scales the zoom speed to the difference between TargetSize
+	// and the size of the subject.
+	ZoomGain float64
+	// TargetSize is the desired size of the subject. Zero disables zoom.
+	TargetSize float64
+	// DeadZone is the offset under which an axis stays still, so that the
+	// camera does not hunt around the center. Defaults to DefaultDeadZone.
+	DeadZone float64
+	// Timeout stops the camera when the source sends no target for this
+	// long. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// IntegralGain adds to the pan and tilt speeds the offset integrated
+	// over time, in seconds, so that a subject slightly off center is
+	// eventually centered. Zero disables the integral term.
+	IntegralGain float64
+	// IntegralLimit bounds the integrated offset, so that a long stall
+	// does not wind up into an overshoot. Defaults to
+	// DefaultIntegralLimit.
+	IntegralLimit float64
+	// DerivativeGain adds to the pan and tilt speeds the rate of change
+	// of the offset, per second, which damps the approach of the center.
+	// Zero disables the derivative term.
+	DerivativeGain float64
+}
+
+// Follower drives a camera towards the targets of a TargetSource.
+type Follower struct {
+	camera *voip.Camera
+	cfg    FollowerConfig
+
+	pan, tilt, zoom int // Last speeds sent
+	panPID, tiltPID pid
+}
+
+// pid is the state of the integral and derivative terms of an axis.
+type pid struct {
+	integral float64
+	offset   float64   // Last offset
+	at       time.Time // Time of the last offset, zero after a reset
+}
+
+func (p *pid) reset() {
+	*p = pid{}
+}
+
+func NewFollower(c *voip.Camera, cfg FollowerConfig) *Follower {
+	if cfg.PanGain == 0 {
+		cfg.PanGain = DefaultGain
+	}
+	if cfg.TiltGain == 0 {
+		cfg.TiltGain = DefaultGain
+	}
+	if cfg.ZoomGain == 0 {
+		cfg.ZoomGain = DefaultGain
+	}
+	if cfg.DeadZone == 0 {
+		cfg.DeadZone = DefaultDeadZone
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.IntegralLimit == 0 {
+		cfg.IntegralLimit = DefaultIntegralLimit
+	}
+	return &Follower{camera: c, cfg: cfg}
+}
+
+// Run follows the targets of src until ctx is done or the source ends,
+// then stops the camera. Drive commands are only sent when a speed changes.
+func (f *Follower) Run(ctx context.Context, src TargetSource) error {
+	targets, err := src.Targets(ctx)
+	if err != nil {
+		return err
+	}
+	defer f.stop()
+
+	timer := time.NewTimer(f.cfg.Timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			// The source stalled: do not keep moving on stale data
+			f.panPID.reset()
+			f.tiltPID.reset()
+			if err := f.drive(0, 0, 0); err != nil {
+				return err
+			}
+		case t, ok := <-targets:
+			if !ok {
+				return nil
+			}
+			timer.Reset(f.cfg.Timeout)
+			if err := f.drive(f.speeds(t)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// speeds returns the pan, tilt and zoom speeds correcting a target.
+func (f *Follower) speeds(t Target) (pan, tilt, zoom int) {
+	if t.Lost {
+		f.panPID.reset()
+		f.tiltPID.reset()
+		return 0, 0, 0
+	}
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	pan = f.pidSpeed(&f.panPID, t.X, t.Time, f.cfg.PanGain, voip.MaxPanSpeed)
+	tilt = f.pidSpeed(&f.tiltPID, t.Y, t.Time, f.cfg.TiltGain, voip.MaxTiltSpeed)
+	if f.cfg.TargetSize > 0 && t.Size > 0 {
+		zoom = f.speed(f.cfg.TargetSize-t.Size, f.cfg.ZoomGain, voip.MaxZoomSpeed)
+	}
+	return pan, tilt, zoom
+}
+
+func (f *Follower) speed(offset, gain float64, maxSpeed int) int {
+	if math.Abs(offset) < f.cfg.DeadZone {
+		return 0
+	}
+	v := int(math.Round(offset * gain * float64(maxSpeed)))
+	return max(-maxSpeed, min(maxSpeed, v))
+}
+
+// pidSpeed returns the speed correcting the offset of an axis with the
+// proportional, integral and derivative terms. Within the dead zone the axis
+// stays still and its integral is cleared.
+func (f *Follower) pidSpeed(p *pid, offset float64, at time.Time, gain float64, maxSpeed int) int {
+	if math.Abs(offset) < f.cfg.DeadZone {
+		p.reset()
+		return 0
+	}
+	u := offset * gain
+	if !p.at.IsZero() {
+		if dt := at.Sub(p.at).Seconds(); dt > 0 {
+			limit := f.cfg.IntegralLimit
+			p.integral = max(-limit, min(limit, p.integral+offset*dt))
+			u += f.cfg.DerivativeGain * (offset - p.offset) / dt
+		}
+	}
+	u += f.cfg.IntegralGain * p.integral
+	p.offset, p.at = offset, at
+
+	v := int(math.Round(u * float64(maxSpeed)))
+	return max(-maxSpeed, min(maxSpeed, v))
+}
+
+func (f *Follower) drive(pan, tilt, zoom int) error {
+	var errs []error
+	if pan != f.pan || tilt != f.tilt {
+		if err := f.camera.PanTilt(pan, tilt); err != nil {
+			errs = append(errs, err)
+		} else {
+			f.pan, f.tilt = pan, tilt
+		}
+	}
+	if zoom != f.zoom {
+		if err := f.camera.Zoom(zoom); err != nil {
+			errs = append(errs, err)
+		} else {
+			f.zoom = zoom
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Follower) stop() {
+	_ = f.camera.PanTiltStop()
+	if f.cfg.TargetSize > 0 {
+		_ = f.camera.ZoomStop()
+	}
+	f.pan, f.tilt, f.zoom = 0, 0, 0
+	f.panPID.reset()
+	f.tiltPID.reset()
+}
+
+// Chan is a TargetSource delivering the targets sent on a channel.
+type Chan <-chan Target
+
+func (c Chan) Targets(ctx context.Context) (<-chan Target, error) {
+	return c, nil
+}
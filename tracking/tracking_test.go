@@ -0,0 +1,136 @@
+package tracking_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/tracking"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestFollower(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	targets := make(chan tracking.Target, 8)
+	targets <- tracking.Target{X: 0.5}
+	targets <- tracking.Target{X: 0.5, Y: 0.01} // Unchanged, within the dead zone
+	targets <- tracking.Target{X: 0.02, Y: -1}
+	targets <- tracking.Target{X: 0.02, Y: 0, Size: 0.1}
+	targets <- tracking.Target{Lost: true}
+	close(targets)
+
+	f := tracking.NewFollower(camera, tracking.FollowerConfig{TargetSize: 0.3, ZoomGain: 2})
+	if err := f.Run(context.Background(), tracking.Chan(targets)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]byte{
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x01, 0x02, 0x03, 0xFF}, // Right at half speed
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x14, 0x03, 0x02, 0xFF}, // Down at full speed
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}, // Centered
+		{0x81, 0x01, 0x04, 0x07, 0x22, 0xFF},                   // Too small: zoom in at 3
+		{0x81, 0x01, 0x04, 0x07, 0x00, 0xFF},                   // Lost
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}, // Stop at the end
+		{0x81, 0x01, 0x04, 0x07, 0x00, 0xFF},
+	}
+	got := emulator.Requests()[1:] // After IF_Clear
+	if len(got) != len(want) {
+		t.Fatalf("got requests % X, want % X", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFollowerTimeout(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	targets := make(chan tracking.Target, 1)
+	targets <- tracking.Target{X: -1}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	f := tracking.NewFollower(camera, tracking.FollowerConfig{Timeout: 20 * time.Millisecond})
+	f.Run(ctx, tracking.Chan(targets))
+
+	got := emulator.Requests()
+	if len(got) != 4 {
+		t.Fatalf("got requests % X, want IF_Clear, left, stop on timeout, stop on exit", got)
+	}
+	if !bytes.Equal(got[2], []byte{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}) {
+		t.Errorf("request after timeout = % X, want stop", got[2])
+	}
+}
+
+func TestFollowerPID(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	targets := make(chan tracking.Target, 8)
+	targets <- tracking.Target{X: 0.5, Time: at(0)}
+	targets <- tracking.Target{X: 0.25, Time: at(500)}  // Integral 0.125, derivative -0.5/s
+	targets <- tracking.Target{X: 0.25, Time: at(1000)} // Integral 0.25
+	targets <- tracking.Target{X: 0.25, Time: at(1500)} // Integral capped at 0.3
+	close(targets)
+
+	f := tracking.NewFollower(camera, tracking.FollowerConfig{
+		IntegralGain:   1,
+		IntegralLimit:  0.3,
+		DerivativeGain: 0.1,
+	})
+	if err := f.Run(context.Background(), tracking.Chan(targets)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]byte{
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x01, 0x02, 0x03, 0xFF}, // 0.5
+		{0x81, 0x01, 0x06, 0x01, 0x08, 0x01, 0x02, 0x03, 0xFF}, // 0.25 + 0.125 - 0.05
+		{0x81, 0x01, 0x06, 0x01, 0x0C, 0x01, 0x02, 0x03, 0xFF}, // 0.25 + 0.25
+		{0x81, 0x01, 0x06, 0x01, 0x0D, 0x01, 0x02, 0x03, 0xFF}, // 0.25 + 0.3
+		{0x81, 0x01, 0x06, 0x01, 0x01, 0x01, 0x03, 0x03, 0xFF}, // Stop at the end
+	}
+	got := emulator.Requests()[1:] // After IF_Clear
+	if len(got) != len(want) {
+		t.Fatalf("got requests % X, want % X", got, want)
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("request %d = % X, want % X", i, got[i], want[i])
+		}
+	}
+}
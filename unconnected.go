@@ -0,0 +1,62 @@
+package viscaoverip
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// unconnectedConn is a UDP socket bound to no remote address, which
+// receives datagrams from any host and port, and writes to remote.
+type unconnectedConn struct {
+	*net.UDPConn
+	remote *net.UDPAddr
+}
+
+// NewUnconnectedConn returns a UDPConn writing to remote over conn, an
+// unconnected socket such as one returned by net.ListenUDP, for New. Unlike
+// a connected socket, it receives the replies of cameras that answer from
+// another source port than the one they listen on; set Config.Unconnected
+// to take them as replies.
+func NewUnconnectedConn(conn *net.UDPConn, remote *net.UDPAddr) UDPConn {
+	return &unconnectedConn{UDPConn: conn, remote: remote}
+}
+
+func (c *unconnectedConn) Write(b []byte) (int, error) {
+	return c.WriteTo(b, c.remote)
+}
+
+func (c *unconnectedConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+func (c *unconnectedConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// listenUDP returns an unconnected socket writing to address (host:port),
+// on a random local port.
+func listenUDP(ctx context.Context, address string) (UDPConn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", address, err)
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", address, err)
+	}
+	remote, err := net.ResolveUDPAddr("udp", net.JoinHostPort(ips[0].IP.String(), port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", address, err)
+	}
+	network := "udp4"
+	if remote.IP.To4() == nil {
+		network = "udp6"
+	}
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for %s: %w", address, err)
+	}
+	return NewUnconnectedConn(conn, remote), nil
+}
@@ -0,0 +1,96 @@
+package viscaoverip_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+// newPortSwitchingCamera starts an emulated camera that receives on one port
+// and replies from another, and returns the address it receives on.
+func newPortSwitchingCamera(t *testing.T) string {
+	t.Helper()
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { emulator.Close() })
+	cameraAddr, err := net.ResolveUDPAddr("udp", emulator.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var socks [2]*net.UDPConn // Receiving and replying sockets
+	for i := range socks {
+		socks[i], err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { socks[i].Close() })
+	}
+	upstream, err := net.DialUDP("udp", nil, cameraAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { upstream.Close() })
+
+	client := make(chan *net.UDPAddr, 1)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := socks[0].ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			select {
+			case <-client:
+			default:
+			}
+			client <- addr
+			_, _ = upstream.Write(buf[:n])
+		}
+	}()
+	go func() {
+		buf := make([]byte, 1024)
+		var addr *net.UDPAddr
+		for {
+			n, err := upstream.Read(buf)
+			if err != nil {
+				return
+			}
+			select {
+			case addr = <-client:
+			default:
+			}
+			_, _ = socks[1].WriteToUDP(buf[:n], addr)
+		}
+	}()
+	return socks[0].LocalAddr().String()
+}
+
+func TestUnconnected(t *testing.T) {
+	addr := newPortSwitchingCamera(t)
+	cfg := voip.Config{MaxRetries: 1, Timeout: 100 * time.Millisecond}
+
+	if camera, err := voip.Dial(context.Background(), addr, cfg); err == nil {
+		camera.Close()
+		t.Error("Dial() on a connected socket received replies from another port")
+	}
+
+	cfg.Unconnected = true
+	camera, err := voip.Dial(context.Background(), addr, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+	if err := camera.SendCommand("06 04"); err != nil {
+		t.Errorf("SendCommand() = %v", err)
+	}
+	if err := camera.Reconnect(context.Background()); err != nil {
+		t.Errorf("Reconnect() = %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package viscaoverip
+
+import (
+	"math"
+	"sort"
+)
+
+// UnitTable maps the steps of a direct value register, e.g. the iris, to
+// real units, e.g. f-numbers, for a vendor profile. The tables are those of
+// the documentation of each vendor for its current models, at 59.94 Hz
+// where it matters; older models may differ.
+type UnitTable map[int]float64
+
+// Value returns the real value of a step.
+func (t UnitTable) Value(step int) (float64, bool) {
+	v, ok := t[step]
+	return v, ok
+}
+
+// Step returns the step whose real value is the closest to v. It reports
+// false if the table is empty.
+func (t UnitTable) Step(v float64) (int, bool) {
+	steps := make([]int, 0, len(t))
+	for s := range t {
+		steps = append(steps, s)
+	}
+	sort.Ints(steps) // Ties go to the lowest step
+	best, bestDiff := 0, math.Inf(1)
+	for _, s := range steps {
+		if t[s] == v {
+			return s, true // Also matches +Inf
+		}
+		if d := math.Abs(t[s] - v); d < bestDiff {
+			best, bestDiff = s, d
+		}
+	}
+	return best, len(steps) > 0
+}
+
+// IrisTable maps iris steps to f-numbers; a closed iris is +Inf. It is nil
+// for vendor profiles without a known table.
+func (v Vendor) IrisTable() UnitTable {
+	switch v {
+	case VendorSony:
+		return UnitTable{
+			0x11: 1.6, 0x10: 2, 0x0F: 2.4, 0x0E: 2.8, 0x0D: 3.4, 0x0C: 4,
+			0x0B: 4.8, 0x0A: 5.6, 0x09: 6.8, 0x08: 8, 0x07: 9.6, 0x06: 11,
+			0x05: 14, 0x00: math.Inf(1),
+		}
+	case VendorPTZOptics:
+		return UnitTable{
+			0x10: 1.8, 0x0F: 2, 0x0E: 2.4, 0x0D: 2.8, 0x0C: 3.4, 0x0B: 4,
+			0x0A: 4.8, 0x09: 5.6, 0x08: 6.8, 0x07: 8, 0x06: 9.6, 0x05: 11,
+			0x00: math.Inf(1),
+		}
+	default:
+		return nil
+	}
+}
+
+// GainTable maps gain steps to decibels. It is nil for vendor profiles
+// without a known table.
+func (v Vendor) GainTable() UnitTable {
+	switch v {
+	case VendorSony:
+		t := UnitTable{}
+		for s := 0x01; s <= 0x11; s++ {
+			t[s] = float64(s-1) * 3 // 0 to 48 dB
+		}
+		return t
+	case VendorPTZOptics:
+		t := UnitTable{}
+		for s := 0x00; s <= 0x0F; s++ {
+			t[s] = float64(s) * 2 // 0 to 30 dB
+		}
+		return t
+	default:
+		return nil
+	}
+}
+
+// ShutterTable maps shutter steps to exposure times in seconds. It is nil
+// for vendor profiles without a known table.
+func (v Vendor) ShutterTable() UnitTable {
+	var denominators []float64
+	switch v {
+	case VendorSony:
+		// From step 0x00
+		denominators = []float64{
+			1, 2, 4, 8, 15, 30, 60, 90, 100, 125, 180, 250, 350, 500, 725,
+			1000, 1500, 2000, 3000, 4000, 6000, 10000,
+		}
+	case VendorPTZOptics:
+		// From step 0x00
+		denominators = []float64{
+			25, 30, 60, 90, 100, 125, 180, 250, 350, 500, 725, 1000, 1500,
+			2000, 3000, 4000, 6000, 10000,
+		}
+	default:
+		return nil
+	}
+	t := UnitTable{}
+	for s, d := range denominators {
+		t[s] = 1 / d
+	}
+	return t
+}
+
+// UnitsPerDegree returns the number of pan and tilt position units per
+// degree, or 0 for vendor profiles without a known scale.
+func (v Vendor) UnitsPerDegree() float64 {
+	switch v {
+	case VendorSony, VendorPTZOptics:
+		return 14.4 // ±170° of pan over ±0x0990
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,53 @@
+package viscaoverip
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Vendor identifies the family of a peripheral device. Vendor extension
+// commands check it before sending, so that they are never sent to cameras
+// that would answer them with a syntax error.
+type Vendor int
+
+const (
+	// VendorGeneric only supports the commands of the core VISCA spec.
+	VendorGeneric Vendor = iota
+	// VendorPTZOptics covers PTZOptics cameras and their OEM siblings.
+	VendorPTZOptics
+	// VendorSony covers Sony SRG and BRC series cameras.
+	VendorSony
+)
+
+var ErrUnsupported = errors.New("command not supported by vendor profile")
+
+func (v Vendor) String() string {
+	switch v {
+	case VendorGeneric:
+		return "generic"
+	case VendorPTZOptics:
+		return "ptzoptics"
+	case VendorSony:
+		return "sony"
+	default:
+		return fmt.Sprintf("Vendor(%d)", int(v))
+	}
+}
+
+// RequireVendor returns ErrUnsupported unless the camera is configured with
+// one of the given vendor profiles.
+func (c *Camera) RequireVendor(vendors ...Vendor) error {
+	for _, v := range vendors {
+		if c.Config.Vendor == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupported, c.Config.Vendor)
+}
+
+// LivenessProbe returns the Probe used for cameras of the vendor profile
+// without Config.LivenessProbe. The power inquiry is answered reliably by
+// every family supported.
+func (v Vendor) LivenessProbe() Probe {
+	return Probe{Inquiry: PowerInquiry}
+}
@@ -0,0 +1,66 @@
+package viscaoverip
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// VersionInquiry (CAM_VersionInq) asks for the vendor, model and firmware
+// version of the peripheral device.
+const VersionInquiry = "00 02"
+
+// Version is the reply to VersionInquiry.
+type Version struct {
+	VendorID   uint16
+	ModelID    uint16
+	ROMVersion uint16
+	MaxSocket  byte
+}
+
+// ParseVersion decodes the data of a VersionInquiry reply
+// (GG GG HH HH JJ JJ KK).
+func ParseVersion(data []byte) (Version, error) {
+	if len(data) != 7 {
+		return Version{}, fmt.Errorf("unexpected version reply data: %x", data)
+	}
+	return Version{
+		VendorID:   binary.BigEndian.Uint16(data[0:2]),
+		ModelID:    binary.BigEndian.Uint16(data[2:4]),
+		ROMVersion: binary.BigEndian.Uint16(data[4:6]),
+		MaxSocket:  data[6],
+	}, nil
+}
+
+// Version inquires the vendor, model and firmware version of the camera.
+func (c *Camera) Version() (Version, error) {
+	data, err := c.SendInquiry(VersionInquiry)
+	if err != nil {
+		return Version{}, err
+	}
+	return ParseVersion(data)
+}
+
+// MaxCameraID is the highest camera ID.
+const MaxCameraID = 0xFFFF
+
+// SetCameraID writes the camera ID register (CAM_IDWrite), an arbitrary
+// number kept by the camera so that fleet tools can tell physical units
+// apart.
+func (c *Camera) SetCameraID(id int) error {
+	if err := checkRange("camera ID", id, 0, MaxCameraID); err != nil {
+		return err
+	}
+	return c.SendCommand("04 22 " + encodeNibbles(id, 4))
+}
+
+// CameraID inquires the camera ID register.
+func (c *Camera) CameraID() (int, error) {
+	data, err := c.SendInquiry("04 22")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("unexpected inquiry reply data: %x", data)
+	}
+	return decodeNibbles(data, false), nil
+}
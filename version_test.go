@@ -0,0 +1,29 @@
+package viscaoverip_test
+
+import (
+	"errors"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestCameraID(t *testing.T) {
+	camera, _ := newEmulatedCamera(t)
+
+	if err := camera.SetCameraID(0xBEEF); err != nil {
+		t.Fatal(err)
+	}
+	id, err := camera.CameraID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 0xBEEF {
+		t.Errorf("CameraID() = %#x, want 0xbeef", id)
+	}
+
+	for _, id := range []int{-1, voip.MaxCameraID + 1} {
+		if err := camera.SetCameraID(id); !errors.Is(err, voip.ErrInvalidArgument) {
+			t.Errorf("SetCameraID(%d) = %v, want ErrInvalidArgument", id, err)
+		}
+	}
+}
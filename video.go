@@ -0,0 +1,21 @@
+package viscaoverip
+
+// VideoFormat is a video output resolution and frame rate, named like
+// "1080p59.94". The vendor packages map formats to the codes of their
+// cameras.
+type VideoFormat string
+
+const (
+	Format2160p2997 VideoFormat = "2160p29.97"
+	Format2160p25   VideoFormat = "2160p25"
+	Format1080p5994 VideoFormat = "1080p59.94"
+	Format1080p50   VideoFormat = "1080p50"
+	Format1080p2997 VideoFormat = "1080p29.97"
+	Format1080p25   VideoFormat = "1080p25"
+	Format1080i5994 VideoFormat = "1080i59.94"
+	Format1080i50   VideoFormat = "1080i50"
+	Format720p5994  VideoFormat = "720p59.94"
+	Format720p50    VideoFormat = "720p50"
+	Format720p2997  VideoFormat = "720p29.97"
+	Format720p25    VideoFormat = "720p25"
+)
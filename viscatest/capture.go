@@ -0,0 +1,154 @@
+package viscatest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Capture is a recorded exchange with a real camera, replayed by a Script
+// so that the behaviors of a firmware stay covered without hardware.
+//
+// A capture file lists the requests of the client and the replies of the
+// camera, one datagram per line, as VISCA payloads in hex:
+//
+//	# Sony SRG-300SE 2.10: ACK, then Completion on arrival
+//	> 81 01 00 01 FF
+//	< 90 41 FF
+//	< 90 51 FF
+//	> 81 01 06 02 18 14 0F 0F 0F 0F 00 00 00 00 FF
+//	< 90 41 FF
+//	< +120ms 90 51 FF
+//	= ok
+//	> 81 09 04 00 FF
+//	< seq-1 90 51 FF
+//	< 90 50 02 FF
+//	= 02
+//
+// A request without replies was dropped by the camera. A reply may start
+// with its delay after the request (+120ms) and with the offset of its
+// sequence number from the request's (seq-1 for a stale reply). A line
+// starting with = states the outcome the client must report for the last
+// request: ok, error, or the data of an inquiry reply. Requests without an
+// outcome, such as the IF_Clear of Dial or the attempts before a retry, are
+// not driven by the replay, only answered. Empty lines and lines starting
+// with # are ignored.
+type Capture struct {
+	Exchanges []CaptureExchange
+}
+
+// CaptureExchange is a request of a Capture, the replies of the camera and
+// the outcome expected from the client.
+type CaptureExchange struct {
+	Step
+	// Want is "ok", "error", the inquiry data in hex, or empty if the
+	// request is not driven by the replay.
+	Want string
+}
+
+// ReadCapture parses a capture file (see Capture).
+func ReadCapture(r io.Reader) (*Capture, error) {
+	var c Capture
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		kind, rest := line[0], strings.TrimSpace(line[1:])
+		if kind == '>' {
+			if _, err := decodeHex(rest); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			c.Exchanges = append(c.Exchanges, CaptureExchange{Step: Step{Request: rest}})
+			continue
+		}
+		if len(c.Exchanges) == 0 {
+			return nil, fmt.Errorf("line %d: %q before the first request", n, kind)
+		}
+		last := &c.Exchanges[len(c.Exchanges)-1]
+		switch kind {
+		case '<':
+			reply, err := parseCaptureReply(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			last.Replies = append(last.Replies, reply)
+		case '=':
+			if rest != "ok" && rest != "error" {
+				if _, err := decodeHex(rest); err != nil {
+					return nil, fmt.Errorf("line %d: %w", n, err)
+				}
+			}
+			last.Want = rest
+		default:
+			return nil, fmt.Errorf("line %d: unknown line kind %q", n, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ReadCaptureFile parses the capture file at path.
+func ReadCaptureFile(path string) (*Capture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := ReadCapture(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// parseCaptureReply parses a reply line, after the <.
+func parseCaptureReply(s string) (ScriptReply, error) {
+	var r ScriptReply
+	fields := strings.Fields(s)
+	for len(fields) > 0 {
+		switch f := fields[0]; {
+		case strings.HasPrefix(f, "+"):
+			d, err := time.ParseDuration(f[1:])
+			if err != nil {
+				return r, err
+			}
+			r.Delay = d
+		case strings.HasPrefix(f, "seq"):
+			delta, err := strconv.Atoi(f[3:])
+			if err != nil {
+				return r, fmt.Errorf("invalid sequence offset %q", f)
+			}
+			r.SeqDelta = delta
+		default:
+			r.Payload = strings.Join(fields, " ")
+			if _, err := decodeHex(r.Payload); err != nil {
+				return r, err
+			}
+			return r, nil
+		}
+		fields = fields[1:]
+	}
+	return r, fmt.Errorf("reply without payload")
+}
+
+// Steps returns the steps of a Script replaying the capture.
+func (c *Capture) Steps() []Step {
+	steps := make([]Step, len(c.Exchanges))
+	for i, e := range c.Exchanges {
+		steps[i] = e.Step
+	}
+	return steps
+}
+
+// Replay starts a Script answering as the camera of the capture did.
+func (c *Capture) Replay() (*Script, error) {
+	return NewScript(c.Steps()...)
+}
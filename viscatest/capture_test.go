@@ -0,0 +1,50 @@
+package viscatest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestReadCapture(t *testing.T) {
+	capture, err := viscatest.ReadCapture(strings.NewReader(`
+# comment
+> 81 01 00 01 FF
+> 81 09 04 00 FF
+< seq-1 90 51 FF
+< +20ms 90 50 02 FF
+= 02
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capture.Exchanges) != 2 {
+		t.Fatalf("got %d exchanges, want 2", len(capture.Exchanges))
+	}
+	if e := capture.Exchanges[0]; len(e.Replies) != 0 || e.Want != "" {
+		t.Errorf("dropped request = %+v", e)
+	}
+	e := capture.Exchanges[1]
+	want := []viscatest.ScriptReply{
+		{Payload: "90 51 FF", SeqDelta: -1},
+		{Payload: "90 50 02 FF", Delay: 20 * time.Millisecond},
+	}
+	if e.Request != "81 09 04 00 FF" || e.Want != "02" || len(e.Replies) != 2 || e.Replies[0] != want[0] || e.Replies[1] != want[1] {
+		t.Errorf("inquiry = %+v", e)
+	}
+
+	for _, bad := range []string{
+		"< 90 41 FF",
+		"> 81 0G FF",
+		"> 81 01 00 01 FF\n< +1x 90 41 FF",
+		"> 81 01 00 01 FF\n< seq-1",
+		"> 81 01 00 01 FF\n= maybe",
+		"? 81 01 00 01 FF",
+	} {
+		if _, err := viscatest.ReadCapture(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadCapture(%q) succeeded", bad)
+		}
+	}
+}
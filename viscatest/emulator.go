@@ -0,0 +1,371 @@
+// Package viscatest provides a virtual VISCA over IP camera, so applications
+// can run integration tests and demos without hardware, and a scripted fake
+// camera to test how they cope with lost, duplicated or late replies.
+package viscatest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	payloadTypeCommand      = 0x0100
+	payloadTypeInquiry      = 0x0110
+	payloadTypeReply        = 0x0111
+	payloadTypeControl      = 0x0200
+	payloadTypeControlReply = 0x0201
+
+	headerSize = 8
+)
+
+// State is the state of the emulated camera, as changed by commands and
+// reported by inquiries.
+type State struct {
+	Power bool
+	Pan   int16
+	Tilt  int16
+	Zoom  uint16
+}
+
+// Emulator is a virtual camera listening on a local UDP port. It answers
+// RESET, sends ACK and Completion for every command, keeps track of power,
+// pan-tilt and zoom commands, of the one byte image settings (exposure
+// mode, white balance mode, picture flip, mirror, noise reduction...) and of
+// the direct value registers (exposure compensation, iris, gain, shutter...), and answers the
+// matching inquiries as well as the version inquiry. Unknown inquiries are
+// answered with a syntax error.
+//
+// Pan, tilt and zoom move over time according to the Kinematics of the
+// emulator, and the Completion of absolute moves is sent on arrival.
+type Emulator struct {
+	// Version is the data of the version inquiry reply (GG GG HH HH JJ JJ KK).
+	Version [7]byte
+
+	conn *net.UDPConn
+	wg   sync.WaitGroup
+
+	mu          sync.Mutex
+	power       bool
+	pan         axis
+	tilt        axis
+	zoom        axis
+	kinematics  Kinematics
+	settings    map[byte]byte   // Image settings by command byte, after 04
+	values      map[byte]uint16 // Direct value registers by command byte, after 04
+	lastAdvance time.Time
+	requests    [][]byte
+	warmup      time.Duration
+	warmEnd     time.Time // End of the warm-up after power on
+}
+
+// reply is a message to send back, after delay.
+type reply struct {
+	msg   []byte
+	delay time.Duration
+}
+
+// NewEmulator starts an emulated camera, powered on, on a random port of
+// the loopback interface.
+func NewEmulator() (*Emulator, error) {
+	return NewEmulatorAt("127.0.0.1:0")
+}
+
+// NewEmulatorAt starts an emulated camera, powered on, listening on address
+// (host:port), e.g. to bring a camera up where a client already expects it.
+func NewEmulatorAt(address string) (*Emulator, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	e := &Emulator{
+		// Sony vendor ID, arbitrary model and ROM version, 2 sockets
+		Version:     [7]byte{0x00, 0x20, 0x05, 0x19, 0x01, 0x00, 0x02},
+		conn:        conn,
+		power:       true,
+		pan:         axis{min: PanMin, max: PanMax},
+		tilt:        axis{min: TiltMin, max: TiltMax},
+		zoom:        axis{min: ZoomMin, max: ZoomMax},
+		settings:    defaultSettings(),
+		values:      defaultValues(),
+		lastAdvance: time.Now(),
+	}
+	e.wg.Add(1)
+	go e.serve()
+	return e, nil
+}
+
+// Addr returns the address (host:port) the emulator listens on.
+func (e *Emulator) Addr() string {
+	return e.conn.LocalAddr().String()
+}
+
+// State returns the current state of the emulated camera.
+func (e *Emulator) State() State {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state()
+}
+
+// state returns the current State. e.mu must be held.
+func (e *Emulator) state() State {
+	e.advance()
+	return State{
+		Power: e.power,
+		Pan:   int16(math.Round(e.pan.pos)),
+		Tilt:  int16(math.Round(e.tilt.pos)),
+		Zoom:  uint16(math.Round(e.zoom.pos)),
+	}
+}
+
+// SetState replaces the state of the emulated camera, stopping any motion.
+func (e *Emulator) SetState(s State) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.advance()
+	e.power = s.Power
+	e.pan.moveTo(float64(s.Pan), 0)
+	e.tilt.moveTo(float64(s.Tilt), 0)
+	e.zoom.moveTo(float64(s.Zoom), 0)
+}
+
+// SetWarmup makes the emulated camera warm up for d after each power on
+// command, reporting standby to power inquiries and rejecting other
+// commands as not executable meanwhile, as real cameras do while booting.
+func (e *Emulator) SetWarmup(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.warmup = d
+}
+
+// SetMotionDetected sets the motion detection status reported to
+// inquiries, bit 0 being window 0.
+func (e *Emulator) SetMotionDetected(windows byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.settings[0x1E] = windows
+}
+
+// defaultSettings returns the one byte image settings at power on.
+func defaultSettings() map[byte]byte {
+	return map[byte]byte{
+		0x39: 0x00, // Exposure mode: full auto
+		0x35: 0x00, // White balance: auto
+		0x66: 0x03, // Picture flip: off
+		0x61: 0x03, // Mirror: off
+		0x53: 0x00, // Noise reduction: off
+		0x3E: 0x03, // Exposure compensation: off
+		0x33: 0x03, // Backlight compensation: off
+		0x59: 0x03, // Spot AE: off
+		0x01: 0x03, // IR cut filter removed (night mode): off
+		0x51: 0x03, // Auto ICR: off
+		0x06: 0x03, // Digital zoom: off
+		0x36: 0x00, // Digital zoom mode: combined
+		0x62: 0x03, // Freeze: off
+		0x63: 0x00, // Picture effect: off
+		0x5B: 0x00, // Gamma: standard
+		0x1B: 0x03, // Motion detection: off
+		0x1E: 0x00, // Motion detection status: no motion
+	}
+}
+
+// defaultValues returns the direct value registers at power on.
+func defaultValues() map[byte]uint16 {
+	return map[byte]uint16{
+		0x4E: 0x07,   // Exposure compensation: 0
+		0x4B: 0x0C,   // Iris
+		0x4C: 0x01,   // Gain
+		0x4A: 0x06,   // Shutter
+		0x29: 0x0808, // Spot AE position: center
+		0x21: 0x0000, // Auto ICR threshold
+		0x46: 0x0000, // Digital zoom position, in separate mode
+		0x49: 0x0004, // Color gain: 100%
+		0x4F: 0x0007, // Hue: 0
+		0x42: 0x0005, // Aperture
+		0x22: 0x0000, // Camera ID
+	}
+}
+
+// Requests returns the VISCA payloads of the commands and inquiries
+// received so far, in order.
+func (e *Emulator) Requests() [][]byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	requests := make([][]byte, len(e.requests))
+	copy(requests, e.requests)
+	return requests
+}
+
+// Close stops the emulator.
+func (e *Emulator) Close() error {
+	err := e.conn.Close()
+	e.wg.Wait()
+	return err
+}
+
+func (e *Emulator) serve() {
+	defer e.wg.Done()
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := e.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		for _, r := range e.handle(buf[:n]) {
+			if r.delay > 0 {
+				msg := r.msg
+				time.AfterFunc(r.delay, func() {
+					_, _ = e.conn.WriteToUDP(msg, addr)
+				})
+				continue
+			}
+			if _, err := e.conn.WriteToUDP(r.msg, addr); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (e *Emulator) handle(msg []byte) []reply {
+	if len(msg) < headerSize+1 || int(binary.BigEndian.Uint16(msg[2:4])) != len(msg)-headerSize {
+		return nil
+	}
+	payloadType := binary.BigEndian.Uint16(msg[0:2])
+	seqNum := binary.BigEndian.Uint32(msg[4:8])
+	payload := msg[headerSize:]
+
+	if payloadType == payloadTypeControl {
+		if payload[0] == 0x01 {
+			return []reply{{msg: makeMessage(payloadTypeControlReply, seqNum, []byte{0x01})}}
+		}
+		return nil
+	}
+	if len(payload) < 3 || payload[len(payload)-1] != 0xFF {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, bytes.Clone(payload))
+
+	r := func(p ...byte) reply {
+		return reply{msg: makeMessage(payloadTypeReply, seqNum, p)}
+	}
+	body := payload[1 : len(payload)-1]
+
+	switch payloadType {
+	case payloadTypeCommand:
+		duration, ok := e.command(body)
+		if !ok {
+			return []reply{r(0x90, 0x61, 0x41, 0xFF)} // Not executable
+		}
+		completion := r(0x90, 0x51, 0xFF)
+		completion.delay = duration
+		return []reply{r(0x90, 0x41, 0xFF), completion}
+	case payloadTypeInquiry:
+		data, ok := e.inquiry(body)
+		if !ok {
+			return []reply{r(0x90, 0x60, 0x02, 0xFF)} // Syntax error
+		}
+		return []reply{r(append(append([]byte{0x90, 0x50}, data...), 0xFF)...)}
+	default:
+		return nil
+	}
+}
+
+// command applies a command (without address and terminator) and returns
+// the time until it completes. It reports false if the command could not be
+// executed. e.mu must be held.
+func (e *Emulator) command(body []byte) (time.Duration, bool) {
+	e.advance()
+	switch {
+	case len(body) == 4 && bytes.Equal(body[:3], []byte{0x01, 0x04, 0x00}):
+		e.power = body[3] == 0x02
+		if e.power && e.warmup > 0 {
+			e.warmEnd = time.Now().Add(e.warmup)
+		}
+		return 0, true
+	case (!e.power || time.Now().Before(e.warmEnd)) && !(len(body) == 3 && bytes.Equal(body, []byte{0x01, 0x00, 0x01})):
+		return 0, false // Only IF_Clear works while powered off or warming up
+	case len(body) == 4 && body[1] == 0x04:
+		if _, ok := e.settings[body[2]]; ok {
+			e.settings[body[2]] = body[3]
+			return 0, true
+		}
+		// Up, down and reset of a direct value register: 01 04 0x 02/03/00
+		if v, ok := e.values[body[2]+0x40]; ok {
+			switch body[3] {
+			case 0x00:
+				e.values[body[2]+0x40] = defaultValues()[body[2]+0x40]
+			case 0x02:
+				e.values[body[2]+0x40] = v + 1
+			case 0x03:
+				e.values[body[2]+0x40] = max(v, 1) - 1
+			}
+			return 0, true
+		}
+	// Direct value: 01 04 xx 00 00 0p 0q
+	case len(body) == 7 && body[1] == 0x04:
+		if _, ok := e.values[body[2]]; ok {
+			e.values[body[2]] = decodeNibbles(body[3:7])
+			return 0, true
+		}
+	}
+	duration, _ := e.move(body)
+	return duration, true
+}
+
+// inquiry returns the reply data of an inquiry (without address and
+// terminator). e.mu must be held.
+func (e *Emulator) inquiry(body []byte) ([]byte, bool) {
+	state := e.state()
+	switch {
+	case bytes.Equal(body, []byte{0x09, 0x00, 0x02}):
+		return e.Version[:], true
+	case bytes.Equal(body, []byte{0x09, 0x04, 0x00}):
+		if state.Power && !time.Now().Before(e.warmEnd) {
+			return []byte{0x02}, true
+		}
+		return []byte{0x03}, true
+	case bytes.Equal(body, []byte{0x09, 0x04, 0x47}):
+		return encodeNibbles(state.Zoom), true
+	case len(body) == 3 && body[0] == 0x09 && body[1] == 0x04:
+		if v, ok := e.values[body[2]]; ok {
+			return encodeNibbles(v), true
+		}
+		v, ok := e.settings[body[2]]
+		return []byte{v}, ok
+	case bytes.Equal(body, []byte{0x09, 0x06, 0x12}):
+		return append(encodeNibbles(uint16(state.Pan)), encodeNibbles(uint16(state.Tilt))...), true
+	default:
+		return nil, false
+	}
+}
+
+// encodeNibbles spreads v over four bytes, one nibble each (0p 0q 0r 0s).
+func encodeNibbles(v uint16) []byte {
+	return []byte{byte(v >> 12 & 0xF), byte(v >> 8 & 0xF), byte(v >> 4 & 0xF), byte(v & 0xF)}
+}
+
+func decodeNibbles(b []byte) uint16 {
+	var v uint16
+	for _, n := range b {
+		v = v<<4 | uint16(n&0xF)
+	}
+	return v
+}
+
+func makeMessage(payloadType uint16, seqNum uint32, payload []byte) []byte {
+	msg := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint16(msg[0:2], payloadType)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(payload)))
+	binary.BigEndian.PutUint32(msg[4:8], seqNum)
+	return append(msg, payload...)
+}
@@ -0,0 +1,117 @@
+package viscatest_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestEmulator(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	version, err := camera.Version()
+	if err != nil || version.VendorID != 0x0020 {
+		t.Errorf("Version() = %+v, %v, want Sony vendor ID", version, err)
+	}
+
+	if err := camera.SendCommand("04 47 01 02 03 04"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := camera.SendInquiry("04 47")
+	if err != nil || !bytes.Equal(data, []byte{0x01, 0x02, 0x03, 0x04}) {
+		t.Errorf("zoom position inquiry = %x, %v, want 01020304", data, err)
+	}
+
+	if err := camera.SendCommand("06 02 18 14 00 01 00 00 0F 0F 0F 0E"); err != nil {
+		t.Fatal(err)
+	}
+	if s := emulator.State(); s.Pan != 0x0100 || s.Tilt != -2 || s.Zoom != 0x1234 {
+		t.Errorf("State() = %+v, want pan 256, tilt -2, zoom 0x1234", s)
+	}
+
+	if err := camera.SendCommand("04 00 03"); err != nil {
+		t.Fatal(err)
+	}
+	data, err = camera.SendInquiry(voip.PowerInquiry)
+	if err != nil || !bytes.Equal(data, []byte{0x03}) {
+		t.Errorf("power inquiry = %x, %v, want 03", data, err)
+	}
+	if err := camera.SendCommand("06 04"); err == nil {
+		t.Error("SendCommand() while powered off: expected error")
+	}
+
+	if _, err := camera.SendInquiry("7E 7E 7E"); err == nil {
+		t.Error("SendInquiry() unknown inquiry: expected error")
+	}
+
+	// IF_Clear, then 4 commands and 4 inquiries
+	if n := len(emulator.Requests()); n != 9 {
+		t.Errorf("len(Requests()) = %d, want 9", n)
+	}
+}
+
+func TestEmulatorMotion(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetKinematics(viscatest.Kinematics{PanRate: 1000, TiltRate: 1000, ZoomRate: 1000})
+
+	cfg := voip.Config{MaxRetries: 1, Timeout: time.Second}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	// Absolute move at speed 1: 256 units at 1000 units/s
+	start := time.Now()
+	if err := camera.SendCommand("06 02 01 01 00 01 00 00 00 00 00 00"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("absolute move completed after %v, want about 256ms", elapsed)
+	}
+	if s := emulator.State(); s.Pan != 0x100 {
+		t.Errorf("pan after absolute move = %d, want 256", s.Pan)
+	}
+
+	// Drive right at speed 2, then stop
+	if err := camera.SendCommand("06 01 02 01 02 03"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	data, err := camera.SendInquiry("06 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := camera.SendCommand("06 01 02 01 03 03"); err != nil {
+		t.Fatal(err)
+	}
+	pan := int(data[0])<<12 | int(data[1])<<8 | int(data[2])<<4 | int(data[3])
+	if pan < 0x100+150 || pan > 0x100+300 {
+		t.Errorf("pan while driving = %d, want about %d", pan, 0x100+200)
+	}
+
+	stopped := emulator.State().Pan
+	time.Sleep(50 * time.Millisecond)
+	if s := emulator.State(); s.Pan != stopped {
+		t.Errorf("pan moved after stop: %d -> %d", stopped, s.Pan)
+	}
+}
@@ -0,0 +1,171 @@
+package viscatest
+
+import (
+	"math"
+	"time"
+)
+
+// Movement limits of the emulated camera, in VISCA position units.
+const (
+	PanMin  = -0x0990
+	PanMax  = 0x0990
+	TiltMin = -0x01B0
+	TiltMax = 0x0510
+	ZoomMin = 0x0000
+	ZoomMax = 0x4000
+
+	maxPanSpeed  = 0x18
+	maxTiltSpeed = 0x14
+	maxZoomSpeed = 0x07
+)
+
+// Kinematics describes how fast the emulated camera moves, in position units
+// per second for each step of the commanded speed. With a zero rate, the
+// axis jumps to absolute positions instantly and ignores continuous drive.
+type Kinematics struct {
+	PanRate  float64
+	TiltRate float64
+	ZoomRate float64
+}
+
+// axis is one degree of freedom of the emulated camera. It either drives
+// continuously at vel, or travels towards target at speed.
+type axis struct {
+	pos      float64
+	vel      float64
+	target   float64
+	speed    float64
+	toTarget bool
+	min, max float64
+}
+
+func (a *axis) advance(dt float64) {
+	switch {
+	case a.toTarget:
+		step := a.speed * dt
+		if math.Abs(a.target-a.pos) <= step {
+			a.pos = a.target
+			a.toTarget = false
+		} else if a.target > a.pos {
+			a.pos += step
+		} else {
+			a.pos -= step
+		}
+	case a.vel != 0:
+		a.pos += a.vel * dt
+	}
+	a.pos = math.Max(a.min, math.Min(a.max, a.pos))
+}
+
+// drive starts moving continuously at vel, or stops if vel is zero.
+func (a *axis) drive(vel float64) {
+	a.toTarget = false
+	a.vel = vel
+}
+
+// moveTo starts traveling to target at speed, and returns the travel time.
+// A zero speed moves instantly.
+func (a *axis) moveTo(target, speed float64) time.Duration {
+	target = math.Max(a.min, math.Min(a.max, target))
+	a.vel = 0
+	if speed <= 0 {
+		a.pos = target
+		a.toTarget = false
+		return 0
+	}
+	a.target, a.speed, a.toTarget = target, speed, true
+	return time.Duration(math.Abs(target-a.pos) / speed * float64(time.Second))
+}
+
+func (a *axis) stop() {
+	a.vel = 0
+	a.toTarget = false
+}
+
+// SetKinematics sets how fast the emulated camera moves. The default zero
+// Kinematics moves instantly.
+func (e *Emulator) SetKinematics(k Kinematics) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.advance()
+	e.kinematics = k
+}
+
+// advance moves the axes up to the current time. e.mu must be held.
+func (e *Emulator) advance() {
+	now := time.Now()
+	dt := now.Sub(e.lastAdvance).Seconds()
+	e.lastAdvance = now
+	e.pan.advance(dt)
+	e.tilt.advance(dt)
+	e.zoom.advance(dt)
+}
+
+// move applies a pan-tilt or zoom command and returns the time until it
+// completes. It reports false if body is not a motion command.
+// e.mu must be held.
+func (e *Emulator) move(body []byte) (time.Duration, bool) {
+	k := e.kinematics
+	switch {
+	// Pan-tilt drive: 01 06 01 VV WW 0X 0Y
+	case len(body) == 7 && body[1] == 0x06 && body[2] == 0x01:
+		panSpeed, tiltSpeed := float64(min(body[3], maxPanSpeed)), float64(min(body[4], maxTiltSpeed))
+		switch body[5] {
+		case 0x01:
+			e.pan.drive(-panSpeed * k.PanRate)
+		case 0x02:
+			e.pan.drive(panSpeed * k.PanRate)
+		default:
+			e.pan.stop()
+		}
+		switch body[6] {
+		case 0x01:
+			e.tilt.drive(tiltSpeed * k.TiltRate)
+		case 0x02:
+			e.tilt.drive(-tiltSpeed * k.TiltRate)
+		default:
+			e.tilt.stop()
+		}
+		return 0, true
+	// Absolute and relative position: 01 06 02/03 VV WW 0Y0Y0Y0Y 0Z0Z0Z0Z
+	case len(body) == 13 && body[1] == 0x06 && (body[2] == 0x02 || body[2] == 0x03):
+		panSpeed, tiltSpeed := float64(min(body[3], maxPanSpeed)), float64(min(body[4], maxTiltSpeed))
+		pan := float64(int16(decodeNibbles(body[5:9])))
+		tilt := float64(int16(decodeNibbles(body[9:13])))
+		if body[2] == 0x03 {
+			pan += e.pan.pos
+			tilt += e.tilt.pos
+		}
+		return max(
+			e.pan.moveTo(pan, panSpeed*k.PanRate),
+			e.tilt.moveTo(tilt, tiltSpeed*k.TiltRate),
+		), true
+	// Home: 01 06 04
+	case len(body) == 3 && body[1] == 0x06 && body[2] == 0x04:
+		return max(
+			e.pan.moveTo(0, maxPanSpeed*k.PanRate),
+			e.tilt.moveTo(0, maxTiltSpeed*k.TiltRate),
+		), true
+	// Zoom drive: 01 04 07 00/02/03/2p/3p
+	case len(body) == 4 && body[1] == 0x04 && body[2] == 0x07:
+		speed := float64(body[3]&0x0F) + 1
+		switch {
+		case body[3] == 0x02:
+			e.zoom.drive(4 * k.ZoomRate)
+		case body[3] == 0x03:
+			e.zoom.drive(-4 * k.ZoomRate)
+		case body[3]&0xF0 == 0x20:
+			e.zoom.drive(speed * k.ZoomRate)
+		case body[3]&0xF0 == 0x30:
+			e.zoom.drive(-speed * k.ZoomRate)
+		default:
+			e.zoom.stop()
+		}
+		return 0, true
+	// Zoom direct: 01 04 47 0p0q0r0s
+	case len(body) == 7 && body[1] == 0x04 && body[2] == 0x47:
+		return e.zoom.moveTo(float64(decodeNibbles(body[3:7])), (maxZoomSpeed+1)*k.ZoomRate), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,121 @@
+package viscatest
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Step is an expected request of a Script and its replies.
+type Step struct {
+	// Request is the expected VISCA payload, e.g. "81 01 06 04 FF". Empty
+	// matches any request.
+	Request string
+	// Replies answer the request in order. A step without replies drops
+	// the request, and listing a reply twice duplicates it.
+	Replies []ScriptReply
+}
+
+// ScriptReply is a reply of a Step.
+type ScriptReply struct {
+	// Payload is the VISCA payload of the reply, e.g. "90 41 FF".
+	Payload string
+	// Raw, if set, is sent as is instead of a reply carrying Payload, to
+	// test garbage datagrams, e.g. "01 11 00".
+	Raw string
+	// SeqDelta numbers the reply relative to the request, e.g. -1 for a
+	// stale reply of the previous message.
+	SeqDelta int
+	// Delay sends the reply late, while later requests are answered.
+	Delay time.Duration
+}
+
+// Script is a fake camera that expects a scripted sequence of requests and
+// answers each with canned replies, including dropped, duplicated, stale
+// and late ones. The RESET control command is answered at any time and is
+// not part of the script.
+type Script struct {
+	*Server
+
+	mu    sync.Mutex
+	steps []Step
+	next  int
+	err   error
+}
+
+// NewScript starts a Script on a random port of the loopback interface.
+// The hex strings of the steps are checked first.
+func NewScript(steps ...Step) (*Script, error) {
+	for i, step := range steps {
+		if _, err := decodeHex(step.Request); err != nil {
+			return nil, fmt.Errorf("step %d: request: %w", i, err)
+		}
+		for _, r := range step.Replies {
+			if _, err := decodeHex(r.Payload + r.Raw); err != nil {
+				return nil, fmt.Errorf("step %d: reply: %w", i, err)
+			}
+		}
+	}
+	s := &Script{steps: steps}
+	server, err := NewServer(s.handle)
+	if err != nil {
+		return nil, err
+	}
+	s.Server = server
+	return s, nil
+}
+
+func (s *Script) handle(msg []byte) []Reply {
+	seqNum := SeqNum(msg)
+	if isReset(msg) {
+		return []Reply{{Msg: MakeResetReply(seqNum)}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next >= len(s.steps) {
+		s.fail(fmt.Errorf("unexpected request % X after the end of the script", msg[min(headerSize, len(msg)):]))
+		return nil
+	}
+	step := s.steps[s.next]
+	s.next++
+	want, _ := decodeHex(step.Request)
+	if len(want) > 0 && (len(msg) < headerSize || !bytes.Equal(msg[headerSize:], want)) {
+		s.fail(fmt.Errorf("step %d: got request % X, want % X", s.next-1, msg[min(headerSize, len(msg)):], want))
+	}
+
+	replies := make([]Reply, 0, len(step.Replies))
+	for _, r := range step.Replies {
+		var b []byte
+		if r.Raw != "" {
+			b, _ = decodeHex(r.Raw)
+		} else {
+			payload, _ := decodeHex(r.Payload)
+			b = MakeReply(uint32(int64(seqNum)+int64(r.SeqDelta)), payload...)
+		}
+		replies = append(replies, Reply{Msg: b, Delay: r.Delay})
+	}
+	return replies
+}
+
+// fail records the first error of the script. s.mu must be held.
+func (s *Script) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the first request that did not match the script, or an
+// error if steps remain. Call it at the end of the test.
+func (s *Script) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if s.next < len(s.steps) {
+		return fmt.Errorf("%d of %d steps not reached", len(s.steps)-s.next, len(s.steps))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package viscatest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+var (
+	ack        = viscatest.ScriptReply{Payload: "90 41 FF"}
+	completion = viscatest.ScriptReply{Payload: "90 51 FF"}
+	ifClear    = viscatest.Step{Request: "81 01 00 01 FF", Replies: []viscatest.ScriptReply{ack, completion}}
+)
+
+func TestScript(t *testing.T) {
+	script, err := viscatest.NewScript(
+		ifClear,
+		// Dropped, then answered on retry with a duplicate completion
+		viscatest.Step{Request: "81 01 04 00 02 FF"},
+		viscatest.Step{Request: "81 01 04 00 02 FF", Replies: []viscatest.ScriptReply{ack, completion, completion}},
+		// Stale reply of the previous message first
+		viscatest.Step{Request: "81 09 04 00 FF", Replies: []viscatest.ScriptReply{
+			{Payload: "90 50 03 FF", SeqDelta: -1},
+			{Payload: "90 50 02 FF"},
+		}},
+		// Late completion
+		viscatest.Step{Request: "81 01 04 00 03 FF", Replies: []viscatest.ScriptReply{
+			ack,
+			{Payload: "90 51 FF", Delay: 20 * time.Millisecond},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 50 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), script.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	if err := camera.SendCommand("04 00 02"); err != nil {
+		t.Errorf("power on: %v", err)
+	}
+	data, err := camera.SendInquiry("04 00")
+	if err != nil || len(data) != 1 || data[0] != 0x02 {
+		t.Errorf("power inquiry = % X, %v, want 02", data, err)
+	}
+	if err := camera.SendCommand("04 00 03"); err != nil {
+		t.Errorf("power off: %v", err)
+	}
+	if err := script.Err(); err != nil {
+		t.Error(err)
+	}
+	if m := camera.Metrics(); m.Retries != 1 {
+		t.Errorf("Retries = %d, want 1", m.Retries)
+	}
+}
+
+func TestScriptMismatch(t *testing.T) {
+	script, err := viscatest.NewScript(ifClear, viscatest.Step{
+		Request: "81 01 04 00 02 FF",
+		Replies: []viscatest.ScriptReply{ack, completion},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+
+	camera, err := voip.Dial(context.Background(), script.Addr(), voip.Config{MaxRetries: 1, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	_ = camera.SendCommand("04 00 03")
+	if err := script.Err(); err == nil {
+		t.Error("Err() = nil after an unexpected request")
+	}
+	_ = camera.SendCommand("04 00 03")
+	if err := script.Err(); err == nil {
+		t.Error("Err() = nil after the end of the script")
+	}
+
+	if _, err := viscatest.NewScript(viscatest.Step{Request: "81 0G FF"}); err == nil {
+		t.Error("NewScript accepted invalid hex")
+	}
+}
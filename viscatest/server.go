@@ -0,0 +1,118 @@
+package viscatest
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reply is a datagram sent back by a Server.
+type Reply struct {
+	Msg []byte
+	// Pause is waited before sending the reply; the server reads no other
+	// message meanwhile.
+	Pause time.Duration
+	// Delay sends the reply in the background after this long, while the
+	// server goes on answering.
+	Delay time.Duration
+}
+
+// Handler returns the replies to a message received by a Server, header
+// included.
+type Handler func(msg []byte) []Reply
+
+// Server is a fake camera listening on a local UDP port, answering every
+// message with the replies of its Handler. It is the building block of
+// Script, and of tests that need replies an Emulator would never send.
+type Server struct {
+	conn    *net.UDPConn
+	handler Handler
+	wg      sync.WaitGroup
+	timers  sync.WaitGroup
+}
+
+// NewServer starts a Server on a random port of the loopback interface.
+func NewServer(handler Handler) (*Server, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{conn: conn, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address (host:port) the server listens on.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Close stops the server. Delayed replies not sent yet are dropped.
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+	s.timers.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		for _, r := range s.handler(msg) {
+			time.Sleep(r.Pause)
+			if r.Delay > 0 {
+				s.timers.Add(1)
+				time.AfterFunc(r.Delay, func() {
+					defer s.timers.Done()
+					_, _ = s.conn.WriteToUDP(r.Msg, addr)
+				})
+				continue
+			}
+			if _, err := s.conn.WriteToUDP(r.Msg, addr); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// MakeReply returns a VISCA reply message (payload type 01 11) numbered
+// seqNum, e.g. MakeReply(seq, 0x90, 0x41, 0xFF) for an ACK.
+func MakeReply(seqNum uint32, payload ...byte) []byte {
+	return makeMessage(payloadTypeReply, seqNum, payload)
+}
+
+// MakeResetReply returns the reply to the RESET control command.
+func MakeResetReply(seqNum uint32) []byte {
+	return makeMessage(payloadTypeControlReply, seqNum, []byte{0x01})
+}
+
+// SeqNum returns the sequence number of a message, or 0 if it has no
+// header.
+func SeqNum(msg []byte) uint32 {
+	if len(msg) < headerSize {
+		return 0
+	}
+	return binary.BigEndian.Uint32(msg[4:8])
+}
+
+// isReset reports whether msg is the RESET control command.
+func isReset(msg []byte) bool {
+	return len(msg) > headerSize && binary.BigEndian.Uint16(msg[0:2]) == payloadTypeControl && msg[headerSize] == 0x01
+}
+
+// decodeHex decodes bytes written as in the tests of a VISCA application,
+// e.g. "81 01 06 04 FF".
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.Join(strings.Fields(s), ""))
+}
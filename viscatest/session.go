@@ -0,0 +1,205 @@
+package viscatest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is a session recorded by Camera.SetRecord: every datagram the
+// client sent and received, with its timing. Unlike a Capture, which is
+// written by hand from the payloads, a session holds whole datagrams,
+// headers and sequence numbers included, so that its replay checks that a
+// client produces the very same traffic.
+type Session struct {
+	Datagrams []SessionDatagram
+}
+
+// SessionDatagram is a datagram of a Session.
+type SessionDatagram struct {
+	// At is the time since the recording started.
+	At time.Duration
+	// Sent is true for the datagrams of the client, false for those of
+	// the camera.
+	Sent bool
+	Data []byte
+}
+
+// ReadSession parses a session recording. Empty lines and lines starting
+// with # are ignored.
+func ReadSession(r io.Reader) (*Session, error) {
+	var s Session
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || (fields[1] != ">" && fields[1] != "<") {
+			return nil, fmt.Errorf("line %d: want time, direction and datagram: %q", n, line)
+		}
+		at, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		data, err := decodeHex(strings.Join(fields[2:], " "))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		s.Datagrams = append(s.Datagrams, SessionDatagram{At: at, Sent: fields[1] == ">", Data: data})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ReadSessionFile parses the session recording at path.
+func ReadSessionFile(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadSession(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SessionReplay is a fake camera replaying the camera side of a Session.
+type SessionReplay struct {
+	*Server
+
+	session *Session
+	mu      sync.Mutex
+	next    int // Index of the next datagram
+	err     error
+}
+
+// Replay starts a SessionReplay on a random port of the loopback
+// interface. Every datagram it receives must be the next one the client
+// sent in the session, byte for byte; it is answered with the datagrams
+// the camera sent after it, until the next datagram of the client, with
+// their recorded delays. Datagrams the camera sent before the first
+// datagram of the client are not replayed.
+func (s *Session) Replay() (*SessionReplay, error) {
+	r := &SessionReplay{session: s}
+	server, err := NewServer(r.handle)
+	if err != nil {
+		return nil, err
+	}
+	r.Server = server
+	return r, nil
+}
+
+func (r *SessionReplay) handle(msg []byte) []Reply {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	datagrams := r.session.Datagrams
+	for r.next < len(datagrams) && !datagrams[r.next].Sent {
+		r.next++
+	}
+	if r.next >= len(datagrams) {
+		r.fail(fmt.Errorf("unexpected datagram % X after the end of the session", msg))
+		return nil
+	}
+	sent := datagrams[r.next]
+	if !bytes.Equal(msg, sent.Data) {
+		r.fail(fmt.Errorf("datagram %d at %v: got % X, want % X", r.next, sent.At, msg, sent.Data))
+	}
+	r.next++
+
+	var replies []Reply
+	for ; r.next < len(datagrams) && !datagrams[r.next].Sent; r.next++ {
+		d := datagrams[r.next]
+		replies = append(replies, Reply{Msg: d.Data, Delay: d.At - sent.At})
+	}
+	return replies
+}
+
+// fail records the first error of the replay. r.mu must be held.
+func (r *SessionReplay) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Err returns the first datagram that differed from the session, or an
+// error if datagrams of the client remain. Call it at the end of the test.
+func (r *SessionReplay) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, d := range r.session.Datagrams[r.next:] {
+		if d.Sent {
+			return fmt.Errorf("datagram % X at %v not received", d.Data, d.At)
+		}
+	}
+	return nil
+}
+
+// Feed sends the datagrams the client sent in the session to the camera at
+// address, such as an Emulator, with their recorded timing, and returns the
+// session that results, with the replies received until wait after the
+// last datagram.
+func (s *Session) Feed(address string, wait time.Duration) (*Session, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var (
+		mu     sync.Mutex
+		result Session
+		done   = make(chan struct{})
+	)
+	start := time.Now()
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				continue // e.g. refused while the camera is not up
+			}
+			mu.Lock()
+			result.Datagrams = append(result.Datagrams, SessionDatagram{At: time.Since(start), Data: bytes.Clone(buf[:n])})
+			mu.Unlock()
+		}
+	}()
+
+	var last time.Duration
+	for _, d := range s.Datagrams {
+		if !d.Sent {
+			continue
+		}
+		time.Sleep(time.Until(start.Add(d.At)))
+		mu.Lock()
+		result.Datagrams = append(result.Datagrams, SessionDatagram{At: time.Since(start), Sent: true, Data: d.Data})
+		mu.Unlock()
+		if _, err := conn.Write(d.Data); err != nil {
+			return nil, err
+		}
+		last = d.At
+	}
+	time.Sleep(time.Until(start.Add(last + wait)))
+	conn.Close()
+	<-done
+	return &result, nil
+}
@@ -0,0 +1,41 @@
+package viscatest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestReadSession(t *testing.T) {
+	session, err := viscatest.ReadSession(strings.NewReader(`
+# comment
+1.2ms > 01 00 00 05 00 00 00 02 81 01 06 04 FF
+3ms < 01 11 00 03 00 00 00 02 90 41 FF
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.Datagrams) != 2 {
+		t.Fatalf("got %d datagrams, want 2", len(session.Datagrams))
+	}
+	sent, received := session.Datagrams[0], session.Datagrams[1]
+	if !sent.Sent || sent.At != 1200*time.Microsecond || len(sent.Data) != 13 {
+		t.Errorf("sent = %+v", sent)
+	}
+	if received.Sent || received.At != 3*time.Millisecond || received.Data[9] != 0x41 {
+		t.Errorf("received = %+v", received)
+	}
+
+	for _, bad := range []string{
+		"1ms > ",
+		"1ms = 01 00",
+		"1x > 01 00",
+		"1ms < 0G",
+	} {
+		if _, err := viscatest.ReadSession(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadSession(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package viscaoverip
+
+import (
+	"context"
+	"fmt"
+)
+
+// WatchdogEvent reports a recovery attempt of the watchdog.
+type WatchdogEvent struct {
+	// Misses is the number of consecutive unanswered exchanges that
+	// triggered the recovery.
+	Misses int
+	// Reconnect is true if the recovery was a full Reconnect.
+	Reconnect bool
+	// Err is the result of the recovery, nil if the camera answered again.
+	Err error
+}
+
+// OnWatchdog registers fn to be called after every recovery attempt of the
+// watchdog, and returns a function that unregisters it. fn is called from
+// the watchdog goroutine.
+func (c *Camera) OnWatchdog(fn func(WatchdogEvent)) (unsubscribe func()) {
+	c.watchdogMu.Lock()
+	defer c.watchdogMu.Unlock()
+	if c.watchdogListeners == nil {
+		c.watchdogListeners = make(map[int]func(WatchdogEvent))
+	}
+	id := c.nextWatchdogID
+	c.nextWatchdogID++
+	c.watchdogListeners[id] = fn
+	return func() {
+		c.watchdogMu.Lock()
+		defer c.watchdogMu.Unlock()
+		delete(c.watchdogListeners, id)
+	}
+}
+
+// triggerWatchdog starts a recovery in the background, unless one is already
+// running or the camera is closing. c.mu must be held.
+func (c *Camera) triggerWatchdog() {
+	c.watchdogMu.Lock()
+	defer c.watchdogMu.Unlock()
+	if c.closing.Load() || !c.watchdogRunning.CompareAndSwap(false, true) {
+		return
+	}
+	event := WatchdogEvent{
+		Misses:    c.misses,
+		Reconnect: c.Config.WatchdogReconnect,
+	}
+	c.watchdogWG.Add(1)
+	go c.recover(event)
+}
+
+func (c *Camera) recover(event WatchdogEvent) {
+	defer c.watchdogWG.Done()
+	defer c.watchdogRunning.Store(false)
+
+	if c.debugging() {
+		c.ReportError("watchdog", fmt.Errorf("%d consecutive misses, re-initializing", event.Misses))
+	}
+
+	c.mu.Lock()
+	if c.closing.Load() {
+		c.mu.Unlock()
+		return
+	}
+	if event.Reconnect {
+		event.Err = c.reconnect(context.Background())
+	} else {
+		event.Err = c.reinitialize(context.Background())
+	}
+	c.mu.Unlock()
+
+	c.watchdogMu.Lock()
+	listeners := make([]func(WatchdogEvent), 0, len(c.watchdogListeners))
+	for _, fn := range c.watchdogListeners {
+		listeners = append(listeners, fn)
+	}
+	c.watchdogMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
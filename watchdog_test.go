@@ -0,0 +1,84 @@
+package viscaoverip_test
+
+import (
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+func TestWatchdog(t *testing.T) {
+	server, addr := newMockServer(t)
+	defer server.close()
+
+	var silent atomic.Bool
+	var resets atomic.Int32
+	server.setHandler(func(msg []byte) [][]byte {
+		if len(msg) >= 2 && msg[0] == 0x02 && msg[1] == 0x00 {
+			resets.Add(1)
+			return [][]byte{makeResetResponse()}
+		}
+		if silent.Load() {
+			return nil
+		}
+		seqNum := binary.BigEndian.Uint32(msg[4:8])
+		return [][]byte{
+			makeResponse(seqNum, 0x41), // ACK
+			makeResponse(seqNum, 0x51), // Completion
+		}
+	})
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := voip.Config{
+		MaxRetries:     1,
+		Timeout:        20 * time.Millisecond,
+		WatchdogMisses: 2,
+	}
+	camera, err := voip.NewCameraWithConfig(conn, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer camera.Close()
+
+	events := make(chan voip.WatchdogEvent, 1)
+	camera.OnWatchdog(func(e voip.WatchdogEvent) { events <- e })
+
+	silent.Store(true)
+	_ = camera.SendCommand("06 04")
+	silent.Store(false)
+	select {
+	case <-events:
+		t.Fatal("watchdog triggered before reaching WatchdogMisses")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	silent.Store(true)
+	_ = camera.SendCommand("06 04")
+	silent.Store(false)
+
+	select {
+	case e := <-events:
+		if e.Err != nil || e.Misses != 2 || e.Reconnect {
+			t.Errorf("WatchdogEvent = %+v, want recovery after 2 misses", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not trigger")
+	}
+	if n := resets.Load(); n != 2 {
+		t.Errorf("RESET sent %d times, want 2", n)
+	}
+	if state := camera.State(); state != voip.StateConnected {
+		t.Errorf("State() = %v, want %v", state, voip.StateConnected)
+	}
+}
@@ -0,0 +1,264 @@
+// Package wsbridge bridges WebSocket connections to the cameras of a
+// Manager, for low latency browser UIs.
+//
+// A client connects with the camera name in the query string
+// (/ws?camera=cam1) and exchanges JSON text messages. Client messages:
+//
+//	{"type": "velocity", "pan": 12, "tilt": -5, "zoom": 0}
+//	{"type": "preset", "preset": 3}
+//	{"type": "home"}
+//	{"type": "stop"}
+//	{"type": "inquiry", "id": "1", "inquiry": "04 00"}
+//
+// Speeds are signed as in Camera.PanTilt and Camera.Zoom. Server messages:
+//
+//	{"type": "position", "pan": 0, "tilt": 0, "zoom": 0}
+//	{"type": "state", "state": "connected"}
+//	{"type": "reply", "id": "1", "data": "02"}
+//	{"type": "ok", "id": "2"}
+//	{"type": "error", "id": "2", "error": "..."}
+//
+// Position messages are pushed whenever the polled position changes, and
+// state messages on every connection state transition. Commands with an id
+// are acknowledged with "ok"; errors are always reported.
+//
+// Handshakes from browser pages of another origin than the bridge are
+// rejected, so that any web page a user visits cannot drive the cameras;
+// see Config.CheckOrigin.
+package wsbridge
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+)
+
+const DefaultPollInterval = 100 * time.Millisecond
+
+type Config struct {
+	// PollInterval is the interval of position inquiries while clients are
+	// connected. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// CheckOrigin reports whether to accept the handshake of r, from the
+	// page of its Origin header. Defaults to accepting requests without an
+	// Origin, from clients other than browsers, and those whose Origin has
+	// the host of the request.
+	CheckOrigin func(r *http.Request) bool
+}
+
+// Handler upgrades requests to WebSocket connections controlling a camera.
+type Handler struct {
+	manager *voip.Manager
+	cfg     Config
+
+	mu      sync.Mutex
+	pollers map[*voip.Camera]*sharedPoller
+}
+
+// sharedPoller is the Poller shared by the clients of a camera, dropped
+// with the last one.
+type sharedPoller struct {
+	poller  *voip.Poller
+	clients int
+}
+
+func NewHandler(m *voip.Manager, cfg Config) *Handler {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.CheckOrigin == nil {
+		cfg.CheckOrigin = sameOrigin
+	}
+	return &Handler{
+		manager: m,
+		cfg:     cfg,
+		pollers: make(map[*voip.Camera]*sharedPoller),
+	}
+}
+
+type clientMessage struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Pan     int    `json:"pan"`
+	Tilt    int    `json:"tilt"`
+	Zoom    int    `json:"zoom"`
+	Preset  int    `json:"preset"`
+	Inquiry string `json:"inquiry"`
+}
+
+type positionMessage struct {
+	Type string `json:"type"`
+	Pan  int    `json:"pan"`
+	Tilt int    `json:"tilt"`
+	Zoom int    `json:"zoom"`
+}
+
+type stateMessage struct {
+	Type  string `json:"type"`
+	State string `json:"state"`
+}
+
+type replyMessage struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Data  string `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// subscribe subscribes a client to the positions of c. Unsubscribing the
+// last client of c stops polling it and forgets its Poller.
+func (h *Handler) subscribe(c *voip.Camera) (<-chan voip.Position, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	p, ok := h.pollers[c]
+	if !ok {
+		p = &sharedPoller{poller: voip.NewPoller(c, h.cfg.PollInterval)}
+		h.pollers[c] = p
+	}
+	p.clients++
+	positions, unsubscribe := p.poller.Subscribe()
+	return positions, func() {
+		unsubscribe()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if p.clients--; p.clients == 0 {
+			delete(h.pollers, c)
+		}
+	}
+}
+
+// sameOrigin reports whether r has no Origin or one with the host of r.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.cfg.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	name := r.URL.Query().Get("camera")
+	camera, ok := h.manager.Get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown camera: %s", name), http.StatusNotFound)
+		return
+	}
+	ws, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.close()
+
+	// A single writer goroutine keeps slow clients from stalling the camera
+	out := make(chan any, 32)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case msg := <-out:
+				b, err := json.Marshal(msg)
+				if err == nil {
+					err = ws.writeText(b)
+				}
+				if err != nil {
+					ws.conn.Close()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	// push drops the message if the client is too slow to keep up
+	push := func(msg any) {
+		select {
+		case out <- msg:
+		default:
+		}
+	}
+	send := func(msg any) {
+		select {
+		case out <- msg:
+		case <-done:
+		}
+	}
+
+	unsubscribeState := camera.OnStateChange(func(from, to voip.State) {
+		push(stateMessage{Type: "state", State: to.String()})
+	})
+	defer unsubscribeState()
+	send(stateMessage{Type: "state", State: camera.State().String()})
+
+	positions, unsubscribePosition := h.subscribe(camera)
+	defer unsubscribePosition()
+	go func() {
+		for {
+			select {
+			case pos := <-positions:
+				push(positionMessage{Type: "position", Pan: pos.Pan, Tilt: pos.Tilt, Zoom: pos.Zoom})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		b, err := ws.readMessage()
+		if err != nil {
+			return
+		}
+		var msg clientMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			send(replyMessage{Type: "error", Error: fmt.Sprintf("invalid message: %v", err)})
+			continue
+		}
+		if reply, ok := handle(camera, msg); ok {
+			send(reply)
+		}
+	}
+}
+
+// handle executes a client message and returns the reply to send, if any.
+func handle(c *voip.Camera, msg clientMessage) (replyMessage, bool) {
+	var err error
+	switch msg.Type {
+	case "velocity":
+		err = errors.Join(c.PanTilt(msg.Pan, msg.Tilt), c.Zoom(msg.Zoom))
+	case "preset":
+		err = c.RecallPreset(msg.Preset)
+	case "home":
+		err = c.Home()
+	case "stop":
+		err = errors.Join(c.PanTiltStop(), c.ZoomStop())
+	case "inquiry":
+		var data []byte
+		data, err = c.SendInquiry(msg.Inquiry)
+		if err == nil {
+			return replyMessage{Type: "reply", ID: msg.ID, Data: hex.EncodeToString(data)}, true
+		}
+	default:
+		err = fmt.Errorf("unknown message type: %q", msg.Type)
+	}
+
+	if err != nil {
+		return replyMessage{Type: "error", ID: msg.ID, Error: err.Error()}, true
+	}
+	if msg.ID != "" {
+		return replyMessage{Type: "ok", ID: msg.ID}, true
+	}
+	return replyMessage{}, false
+}
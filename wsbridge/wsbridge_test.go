@@ -0,0 +1,262 @@
+package wsbridge_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+	"github.com/quangd42/visca-over-ip/wsbridge"
+)
+
+// wsClient is a bare WebSocket client for tests.
+type wsClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialWS(t *testing.T, server *httptest.Server, path string) *wsClient {
+	t.Helper()
+	conn, r, res := handshake(t, server, path, "")
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", res.StatusCode)
+	}
+	// Example from RFC 6455
+	if got := res.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return &wsClient{conn: conn, r: r}
+}
+
+// handshake sends a WebSocket handshake to host test, with an Origin
+// header unless origin is empty, and returns the response.
+func handshake(t *testing.T, server *httptest.Server, path, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if origin != "" {
+		origin = "Origin: " + origin + "\r\n"
+	}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n%s\r\n", path, origin)
+
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, r, res
+}
+
+func (c *wsClient) send(t *testing.T, msg string) {
+	t.Helper()
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(msg))}
+	frame = append(frame, mask...)
+	for i := range len(msg) {
+		frame = append(frame, msg[i]^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// next returns the next message of the given type, skipping the others.
+func (c *wsClient) next(t *testing.T, typ string) map[string]any {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		var h [2]byte
+		if _, err := io.ReadFull(c.r, h[:]); err != nil {
+			t.Fatal(err)
+		}
+		length := int(h[1] & 0x7F)
+		if length == 126 {
+			var ext [2]byte
+			io.ReadFull(c.r, ext[:])
+			length = int(binary.BigEndian.Uint16(ext[:]))
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg["type"] == typ {
+			return msg
+		}
+	}
+}
+
+func TestBridge(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: 42})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	m.Add("cam1", camera)
+
+	handler := wsbridge.NewHandler(m, wsbridge.Config{PollInterval: 10 * time.Millisecond})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/ws?camera=cam9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown camera status = %d, want 404", res.StatusCode)
+	}
+
+	c := dialWS(t, server, "/ws?camera=cam1")
+
+	if msg := c.next(t, "state"); msg["state"] != "connected" {
+		t.Errorf("state message = %v", msg)
+	}
+	if msg := c.next(t, "position"); msg["pan"] != float64(42) {
+		t.Errorf("position message = %v, want pan 42", msg)
+	}
+
+	c.send(t, `{"type": "preset", "id": "a", "preset": 3}`)
+	if msg := c.next(t, "ok"); msg["id"] != "a" {
+		t.Errorf("ok message = %v, want id a", msg)
+	}
+
+	c.send(t, `{"type": "inquiry", "id": "b", "inquiry": "04 00"}`)
+	if msg := c.next(t, "reply"); msg["id"] != "b" || msg["data"] != "02" {
+		t.Errorf("reply message = %v, want id b, data 02", msg)
+	}
+
+	c.send(t, `{"type": "velocity", "pan": 99}`)
+	if msg := c.next(t, "error"); !strings.Contains(msg["error"].(string), "invalid argument") {
+		t.Errorf("error message = %v", msg)
+	}
+
+	emulator.SetState(viscatest.State{Power: true, Pan: -7})
+	if msg := c.next(t, "position"); msg["pan"] != float64(-7) {
+		t.Errorf("position message = %v, want pan -7", msg)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	m := voip.NewManager()
+	defer m.Close()
+	camera, err := voip.Dial(context.Background(), "127.0.0.1:1", voip.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add("cam1", camera)
+
+	server := httptest.NewServer(wsbridge.NewHandler(m, wsbridge.Config{}))
+	defer server.Close()
+	for _, tc := range []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{"http://test", http.StatusSwitchingProtocols},
+		{"https://evil.example", http.StatusForbidden},
+		{"http://test.evil.example", http.StatusForbidden},
+	} {
+		if _, _, res := handshake(t, server, "/ws?camera=cam1", tc.origin); res.StatusCode != tc.status {
+			t.Errorf("handshake from %q = %d, want %d", tc.origin, res.StatusCode, tc.status)
+		}
+	}
+
+	// Overridden by Config.CheckOrigin
+	allowed := httptest.NewServer(wsbridge.NewHandler(m, wsbridge.Config{
+		CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == "https://ui.example" },
+	}))
+	defer allowed.Close()
+	if _, _, res := handshake(t, allowed, "/ws?camera=cam1", "https://ui.example"); res.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("handshake from allowed origin = %d, want 101", res.StatusCode)
+	}
+}
+
+func TestBridgeStopsPolling(t *testing.T) {
+	emulator, err := viscatest.NewEmulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emulator.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: 42})
+
+	cfg := voip.Config{MaxRetries: 3, Timeout: 100 * time.Millisecond}
+	camera, err := voip.Dial(context.Background(), emulator.Addr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := voip.NewManager()
+	defer m.Close()
+	m.Add("cam1", camera)
+
+	server := httptest.NewServer(wsbridge.NewHandler(m, wsbridge.Config{PollInterval: 10 * time.Millisecond}))
+	defer server.Close()
+
+	polls := func() int {
+		n := 0
+		for _, req := range emulator.Requests() {
+			if bytes.Equal(req, []byte{0x81, 0x09, 0x06, 0x12, 0xFF}) {
+				n++
+			}
+		}
+		return n
+	}
+
+	c1 := dialWS(t, server, "/ws?camera=cam1")
+	c2 := dialWS(t, server, "/ws?camera=cam1")
+	c1.next(t, "position")
+	c2.next(t, "position")
+
+	// The other client keeps the camera polled
+	c1.conn.Close()
+	emulator.SetState(viscatest.State{Power: true, Pan: -7})
+	if msg := c2.next(t, "position"); msg["pan"] != float64(-7) {
+		t.Errorf("position message = %v, want pan -7", msg)
+	}
+
+	c2.conn.Close()
+	deadline := time.Now().Add(time.Second)
+	for n := polls(); ; {
+		time.Sleep(50 * time.Millisecond)
+		if next := polls(); next == n {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("camera still polled without clients: %d polls", next)
+		} else {
+			n = next
+		}
+	}
+
+	// A new client polls again
+	c3 := dialWS(t, server, "/ws?camera=cam1")
+	if msg := c3.next(t, "position"); msg["pan"] != float64(-7) {
+		t.Errorf("position message = %v, want pan -7", msg)
+	}
+}
@@ -0,0 +1,51 @@
+package viscaoverip
+
+import (
+	"math"
+	"time"
+)
+
+// ZoomPositionMaxAge is how long the zoom position is reused by the zoom
+// compensation of PanTilt before it is inquired again.
+const ZoomPositionMaxAge = 500 * time.Millisecond
+
+// compensateZoom scales drive speeds down with the zoom position, keeping
+// moving axes at speed 1 or more.
+func (c *Camera) compensateZoom(pan, tilt int) (int, int, error) {
+	if pan == 0 && tilt == 0 {
+		return 0, 0, nil
+	}
+	zoom, err := c.recentZoomPosition()
+	if err != nil {
+		return 0, 0, err
+	}
+	ratio := min(1, max(0, float64(zoom)/OpticalZoomMax))
+	scale := 1 - min(1, c.Config.ZoomCompensation)*ratio
+	return scaleSpeed(pan, scale), scaleSpeed(tilt, scale), nil
+}
+
+func scaleSpeed(speed int, scale float64) int {
+	if speed == 0 {
+		return 0
+	}
+	v := int(math.Round(float64(speed) * scale))
+	if v == 0 {
+		if speed > 0 {
+			return 1
+		}
+		return -1
+	}
+	return v
+}
+
+// recentZoomPosition returns the last zoom position inquired, or inquires
+// it if older than ZoomPositionMaxAge.
+func (c *Camera) recentZoomPosition() (int, error) {
+	c.zoomMu.Lock()
+	zoom, at := c.zoomPos, c.zoomAt
+	c.zoomMu.Unlock()
+	if !at.IsZero() && time.Since(at) < ZoomPositionMaxAge {
+		return zoom, nil
+	}
+	return c.ZoomPosition()
+}
@@ -0,0 +1,48 @@
+package viscaoverip_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	voip "github.com/quangd42/visca-over-ip"
+	"github.com/quangd42/visca-over-ip/viscatest"
+)
+
+func TestZoomCompensation(t *testing.T) {
+	camera, emulator := newEmulatedCamera(t)
+	camera.Config.ZoomCompensation = 0.75
+
+	tests := []struct {
+		zoom      uint16
+		pan, tilt int
+		want      string // Drive command sent
+	}{
+		{0, 0x18, 0x14, "8101060118140201ff"},
+		{voip.OpticalZoomMax / 2, 0x18, -0x14, "810106010f0d0202ff"},
+		{voip.OpticalZoomMax, 0x18, 1, "8101060106010201ff"},
+	}
+	for _, tc := range tests {
+		emulator.SetState(viscatest.State{Power: true, Zoom: tc.zoom})
+		// Refresh the zoom position remembered from the previous case
+		if _, err := camera.ZoomPosition(); err != nil {
+			t.Fatal(err)
+		}
+		if err := camera.PanTilt(tc.pan, tc.tilt); err != nil {
+			t.Fatal(err)
+		}
+		requests := emulator.Requests()
+		if got := hex.EncodeToString(requests[len(requests)-1]); got != tc.want {
+			t.Errorf("zoom %#x: PanTilt(%d, %d) sent %s, want %s", tc.zoom, tc.pan, tc.tilt, got, tc.want)
+		}
+	}
+
+	// Stopping never waits for an inquiry
+	before := len(emulator.Requests())
+	camera.Config.ZoomCompensation = 1
+	if err := camera.PanTiltStop(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(emulator.Requests()) - before; n != 1 {
+		t.Errorf("PanTiltStop() sent %d messages, want 1", n)
+	}
+}